@@ -0,0 +1,283 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"mock-server/pkg/client"
+	"os"
+)
+
+// messages are taken from arguments or, with -file, one message per line ("-" for stdin)
+func readMessages(file string, args []string) ([]string, error) {
+	if file == "" {
+		return args, nil
+	}
+
+	in := os.Stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	messages := append([]string{}, args...)
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			messages = append(messages, line)
+		}
+	}
+	return messages, scanner.Err()
+}
+
+func poolCommands() map[string]command {
+	return map[string]command{
+		"list": {
+			usage: "",
+			run: func(a *app, args []string) error {
+				if _, err := parseArgs(noFlags("list"), args, 0); err != nil {
+					return err
+				}
+				pools, err := a.client.ListPools(a.ctx)
+				if err != nil {
+					return err
+				}
+
+				rows := make([][]string, len(pools))
+				for i, pool := range pools {
+					rows[i] = []string{pool.PoolName, pool.Broker, pool.QueueName + pool.TopicName}
+				}
+				return a.printer.print(pools, []string{"POOL", "BROKER", "QUEUE/TOPIC"}, rows)
+			},
+		},
+		"get": {
+			usage: "<pool>",
+			run: func(a *app, args []string) error {
+				rest, err := parseArgs(noFlags("get"), args, 1)
+				if err != nil {
+					return err
+				}
+				config, err := a.client.GetPoolConfig(a.ctx, rest[0])
+				if err != nil {
+					return err
+				}
+				// broker specific config has no fixed columns
+				return a.printer.printJSON(config)
+			},
+		},
+		"create": {
+			usage: "-broker rabbitmq|kafka (-queue <queue> | -topic <topic>) <pool>",
+			run: func(a *app, args []string) error {
+				fs := noFlags("create")
+				broker := fs.String("broker", "", "broker of pool: rabbitmq or kafka")
+				queue := fs.String("queue", "", "rabbitmq queue name")
+				topic := fs.String("topic", "", "kafka topic name")
+				rest, err := parseArgs(fs, args, 1)
+				if err != nil {
+					return err
+				}
+				if *broker == "" {
+					return errUsage
+				}
+
+				pool := client.MessagePool{
+					PoolName:  rest[0],
+					QueueName: *queue,
+					TopicName: *topic,
+					Broker:    *broker,
+				}
+				if err := a.client.CreatePool(a.ctx, pool); err != nil {
+					return err
+				}
+				a.printer.done("pool " + rest[0] + " created")
+				return nil
+			},
+		},
+		"delete": {
+			usage: "<pool>",
+			run: func(a *app, args []string) error {
+				rest, err := parseArgs(noFlags("delete"), args, 1)
+				if err != nil {
+					return err
+				}
+				if err := a.client.DeletePool(a.ctx, rest[0]); err != nil {
+					return err
+				}
+				a.printer.done("pool " + rest[0] + " deleted")
+				return nil
+			},
+		},
+		"publish": {
+			usage: "[-file <messages.txt>|-] <pool> [message...]",
+			run: func(a *app, args []string) error {
+				fs := noFlags("publish")
+				file := fs.String("file", "", "read messages from file, one per line (- for stdin)")
+				if err := fs.Parse(args); err != nil || fs.NArg() < 1 {
+					return errUsage
+				}
+
+				messages, err := readMessages(*file, fs.Args()[1:])
+				if err != nil {
+					return err
+				}
+				if len(messages) == 0 {
+					return errors.New("no messages to publish")
+				}
+
+				if err := a.client.PublishMessages(a.ctx, fs.Arg(0), messages); err != nil {
+					return err
+				}
+				a.printer.done("write task scheduled")
+				return nil
+			},
+		},
+		"read": {
+			usage: "<pool>",
+			run: func(a *app, args []string) error {
+				rest, err := parseArgs(noFlags("read"), args, 1)
+				if err != nil {
+					return err
+				}
+				if err := a.client.ScheduleRead(a.ctx, rest[0]); err != nil {
+					return err
+				}
+				a.printer.done("read task scheduled")
+				return nil
+			},
+		},
+		"messages": {
+			usage: "[-written] <pool>",
+			run: func(a *app, args []string) error {
+				fs := noFlags("messages")
+				written := fs.Bool("written", false, "show written messages instead of read ones")
+				rest, err := parseArgs(fs, args, 1)
+				if err != nil {
+					return err
+				}
+
+				messagesOf := a.client.ReadMessages
+				if *written {
+					messagesOf = a.client.WrittenMessages
+				}
+				messages, err := messagesOf(a.ctx, rest[0])
+				if err != nil {
+					return err
+				}
+
+				rows := make([][]string, len(messages))
+				for i, message := range messages {
+					rows[i] = []string{singleLine(message)}
+				}
+				return a.printer.print(messages, []string{"MESSAGE"}, rows)
+			},
+		},
+	}
+}
+
+func esbCommands() map[string]command {
+	return map[string]command{
+		"list": {
+			usage: "",
+			run: func(a *app, args []string) error {
+				if _, err := parseArgs(noFlags("list"), args, 0); err != nil {
+					return err
+				}
+				records, err := a.client.ListEsbRecords(a.ctx)
+				if err != nil {
+					return err
+				}
+
+				rows := make([][]string, len(records))
+				for i, record := range records {
+					rows[i] = []string{record.PoolNameIn, record.PoolNameOut}
+				}
+				return a.printer.print(records, []string{"POOL IN", "POOL OUT"}, rows)
+			},
+		},
+		"code": {
+			usage: "<pool in>",
+			run: func(a *app, args []string) error {
+				rest, err := parseArgs(noFlags("code"), args, 1)
+				if err != nil {
+					return err
+				}
+				code, err := a.client.GetEsbRecordCode(a.ctx, rest[0])
+				if err != nil {
+					return err
+				}
+				return a.printer.printValue(map[string]string{"pool_name_in": rest[0], "code": code}, code)
+			},
+		},
+		"create": {
+			usage: "[-file <mapper.py> | -code <code>] <pool in> <pool out>",
+			run: func(a *app, args []string) error {
+				fs := noFlags("create")
+				file := fs.String("file", "", "read mapper code from file")
+				code := fs.String("code", "", "mapper code")
+				rest, err := parseArgs(fs, args, 2)
+				if err != nil {
+					return err
+				}
+				if *file != "" && *code != "" {
+					return errors.New("-file and -code are mutually exclusive")
+				}
+				if *file != "" {
+					data, err := os.ReadFile(*file)
+					if err != nil {
+						return err
+					}
+					*code = string(data)
+				}
+
+				record := client.EsbRecord{PoolNameIn: rest[0], PoolNameOut: rest[1], Code: *code}
+				if err := a.client.CreateEsbRecord(a.ctx, record); err != nil {
+					return err
+				}
+				a.printer.done("esb record " + rest[0] + " -> " + rest[1] + " created")
+				return nil
+			},
+		},
+		"delete": {
+			usage: "<pool in>",
+			run: func(a *app, args []string) error {
+				rest, err := parseArgs(noFlags("delete"), args, 1)
+				if err != nil {
+					return err
+				}
+				if err := a.client.DeleteEsbRecord(a.ctx, rest[0]); err != nil {
+					return err
+				}
+				a.printer.done("esb record " + rest[0] + " deleted")
+				return nil
+			},
+		},
+		"task": {
+			usage: "[-file <messages.txt>|-] <pool in> [message...]",
+			run: func(a *app, args []string) error {
+				fs := noFlags("task")
+				file := fs.String("file", "", "read messages from file, one per line (- for stdin)")
+				if err := fs.Parse(args); err != nil || fs.NArg() < 1 {
+					return errUsage
+				}
+
+				messages, err := readMessages(*file, fs.Args()[1:])
+				if err != nil {
+					return err
+				}
+				if len(messages) == 0 {
+					return errors.New("no messages to submit")
+				}
+
+				if err := a.client.SubmitEsbTask(a.ctx, fs.Arg(0), messages); err != nil {
+					return err
+				}
+				a.printer.done("task submitted to " + fs.Arg(0))
+				return nil
+			},
+		},
+	}
+}
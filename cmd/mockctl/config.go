@@ -0,0 +1,251 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mock-server/pkg/client"
+	"os"
+)
+
+// snapshot of everything managed through admin api, pools go before esb records
+// on import because records reference them
+type exportedConfig struct {
+	Static  []client.StaticEndpoint  `json:"static"`
+	Proxy   []client.ProxyEndpoint   `json:"proxy"`
+	Dynamic []client.DynamicEndpoint `json:"dynamic"`
+	Pools   []client.MessagePool     `json:"pools"`
+	Esb     []client.EsbRecord       `json:"esb"`
+}
+
+// export and import move whole routes with static representations and
+// activity windows, not only the value managed by route commands
+type routeConfigs[T any] struct {
+	kind   routeKind
+	path   func(route *T) string
+	get    func(cl *client.Client, ctx context.Context, path string) (*T, error)
+	create func(cl *client.Client, ctx context.Context, route T) error
+	update func(cl *client.Client, ctx context.Context, route T) error
+}
+
+var (
+	staticConfigs = routeConfigs[client.StaticEndpoint]{
+		kind:   staticRoutes,
+		path:   func(route *client.StaticEndpoint) string { return route.Path },
+		get:    (*client.Client).GetStaticRoute,
+		create: (*client.Client).CreateStaticRoute,
+		update: (*client.Client).UpdateStaticRoute,
+	}
+	proxyConfigs = routeConfigs[client.ProxyEndpoint]{
+		kind:   proxyRoutes,
+		path:   func(route *client.ProxyEndpoint) string { return route.Path },
+		get:    (*client.Client).GetProxyRoute,
+		create: (*client.Client).CreateProxyRoute,
+		update: (*client.Client).UpdateProxyRoute,
+	}
+	dynamicConfigs = routeConfigs[client.DynamicEndpoint]{
+		kind:   dynamicRoutes,
+		path:   func(route *client.DynamicEndpoint) string { return route.Path },
+		get:    (*client.Client).GetDynamicRoute,
+		create: (*client.Client).CreateDynamicRoute,
+		update: (*client.Client).UpdateDynamicRoute,
+	}
+)
+
+func (r routeConfigs[T]) exportAll(ctx context.Context, cl *client.Client) ([]T, error) {
+	paths, err := r.kind.list(cl, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	routes := make([]T, 0, len(paths))
+	for _, path := range paths {
+		route, err := r.get(cl, ctx, path)
+		if err != nil {
+			return nil, fmt.Errorf("export %s route %s: %w", r.kind.name, path, err)
+		}
+		routes = append(routes, *route)
+	}
+	return routes, nil
+}
+
+// existing routes are updated with overwrite, otherwise they are skipped
+func (r routeConfigs[T]) importAll(ctx context.Context, cl *client.Client, routes []T, overwrite bool, stats *importStats) error {
+	for _, route := range routes {
+		path := r.path(&route)
+		err := r.create(cl, ctx, route)
+		switch {
+		case err == nil:
+			stats.created++
+		case errors.Is(err, client.ErrConflict) && overwrite:
+			if err := r.update(cl, ctx, route); err != nil {
+				return fmt.Errorf("update %s route %s: %w", r.kind.name, path, err)
+			}
+			stats.updated++
+		case errors.Is(err, client.ErrConflict):
+			warn("%s route %s already exists, skipped", r.kind.name, path)
+			stats.skipped++
+		default:
+			return fmt.Errorf("create %s route %s: %w", r.kind.name, path, err)
+		}
+	}
+	return nil
+}
+
+func exportConfig(ctx context.Context, cl *client.Client) (*exportedConfig, error) {
+	var (
+		config exportedConfig
+		err    error
+	)
+
+	if config.Static, err = staticConfigs.exportAll(ctx, cl); err != nil {
+		return nil, err
+	}
+	if config.Proxy, err = proxyConfigs.exportAll(ctx, cl); err != nil {
+		return nil, err
+	}
+	if config.Dynamic, err = dynamicConfigs.exportAll(ctx, cl); err != nil {
+		return nil, err
+	}
+	if config.Pools, err = cl.ListPools(ctx); err != nil {
+		return nil, err
+	}
+	if config.Esb, err = cl.ListEsbRecords(ctx); err != nil {
+		return nil, err
+	}
+	for i := range config.Esb {
+		code, err := cl.GetEsbRecordCode(ctx, config.Esb[i].PoolNameIn)
+		if err != nil {
+			return nil, fmt.Errorf("export esb record %s: %w", config.Esb[i].PoolNameIn, err)
+		}
+		config.Esb[i].Code = code
+	}
+
+	return &config, nil
+}
+
+type importStats struct {
+	created int
+	updated int
+	skipped int
+}
+
+// existing routes are updated with overwrite, otherwise they are skipped;
+// pools and esb records can not be updated and are always skipped if exist
+func importConfig(ctx context.Context, cl *client.Client, config *exportedConfig, overwrite bool) (importStats, error) {
+	var stats importStats
+
+	if err := staticConfigs.importAll(ctx, cl, config.Static, overwrite, &stats); err != nil {
+		return stats, err
+	}
+	if err := proxyConfigs.importAll(ctx, cl, config.Proxy, overwrite, &stats); err != nil {
+		return stats, err
+	}
+	if err := dynamicConfigs.importAll(ctx, cl, config.Dynamic, overwrite, &stats); err != nil {
+		return stats, err
+	}
+
+	for _, pool := range config.Pools {
+		err := cl.CreatePool(ctx, pool)
+		switch {
+		case err == nil:
+			stats.created++
+		case errors.Is(err, client.ErrConflict):
+			warn("pool %s already exists, skipped", pool.PoolName)
+			stats.skipped++
+		default:
+			return stats, fmt.Errorf("create pool %s: %w", pool.PoolName, err)
+		}
+	}
+
+	for _, record := range config.Esb {
+		err := cl.CreateEsbRecord(ctx, record)
+		switch {
+		case err == nil:
+			stats.created++
+		case errors.Is(err, client.ErrConflict):
+			warn("esb record %s already exists, skipped", record.PoolNameIn)
+			stats.skipped++
+		default:
+			return stats, fmt.Errorf("create esb record %s: %w", record.PoolNameIn, err)
+		}
+	}
+
+	return stats, nil
+}
+
+func configCommands() map[string]command {
+	return map[string]command{
+		"export": {
+			usage: "[-file <config.json>]",
+			run: func(a *app, args []string) error {
+				fs := noFlags("export")
+				file := fs.String("file", "", "write config to file instead of stdout")
+				if _, err := parseArgs(fs, args, 0); err != nil {
+					return err
+				}
+
+				config, err := exportConfig(a.ctx, a.client)
+				if err != nil {
+					return err
+				}
+
+				if *file == "" {
+					// export is always json, output format does not matter
+					return a.printer.printJSON(config)
+				}
+
+				data, err := json.MarshalIndent(config, "", "  ")
+				if err != nil {
+					return err
+				}
+				if err := os.WriteFile(*file, append(data, '\n'), 0644); err != nil {
+					return err
+				}
+				a.printer.done("config exported to " + *file)
+				return nil
+			},
+		},
+		"import": {
+			usage: "[-overwrite] <config.json>|-",
+			run: func(a *app, args []string) error {
+				fs := noFlags("import")
+				overwrite := fs.Bool("overwrite", false, "update routes that already exist")
+				rest, err := parseArgs(fs, args, 1)
+				if err != nil {
+					return err
+				}
+
+				in := os.Stdin
+				if rest[0] != "-" {
+					f, err := os.Open(rest[0])
+					if err != nil {
+						return err
+					}
+					defer f.Close()
+					in = f
+				}
+
+				var config exportedConfig
+				if err := json.NewDecoder(in).Decode(&config); err != nil {
+					return fmt.Errorf("parse config: %w", err)
+				}
+
+				stats, err := importConfig(a.ctx, a.client, &config, *overwrite)
+				if a.printer.format == OUTPUT_JSON {
+					if printErr := a.printer.printJSON(map[string]int{
+						"created": stats.created,
+						"updated": stats.updated,
+						"skipped": stats.skipped,
+					}); printErr != nil && err == nil {
+						err = printErr
+					}
+				} else {
+					a.printer.done(fmt.Sprintf("created %d, updated %d, skipped %d", stats.created, stats.updated, stats.skipped))
+				}
+				return err
+			},
+		},
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"mock-server/pkg/client"
+	"os"
+	"sort"
+	"strings"
+)
+
+// returned when command line is malformed, usage is printed and exit code is 2
+var errUsage = errors.New("usage")
+
+type command struct {
+	usage string
+	run   func(app *app, args []string) error
+}
+
+type app struct {
+	ctx     context.Context
+	client  *client.Client
+	printer *printer
+}
+
+// resource -> action -> command
+var commands = map[string]map[string]command{
+	"static":  routeCommands(staticRoutes),
+	"proxy":   routeCommands(proxyRoutes),
+	"dynamic": routeCommands(dynamicRoutes),
+	"pool":    poolCommands(),
+	"esb":     esbCommands(),
+	"config":  configCommands(),
+}
+
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage: mockctl [flags] <resource> <action> [args]\n\nFlags:\n")
+	flag.PrintDefaults()
+	fmt.Fprintf(out, "\nCommands:\n")
+
+	resources := make([]string, 0, len(commands))
+	for resource := range commands {
+		resources = append(resources, resource)
+	}
+	sort.Strings(resources)
+
+	for _, resource := range resources {
+		actions := make([]string, 0, len(commands[resource]))
+		for action := range commands[resource] {
+			actions = append(actions, action)
+		}
+		sort.Strings(actions)
+		for _, action := range actions {
+			fmt.Fprintln(out, strings.TrimRight("  "+resource+" "+action+" "+commands[resource][action].usage, " "))
+		}
+	}
+}
+
+func envOr(name string, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func main() {
+	addr := flag.String("addr", envOr("MOCKCTL_ADDR", client.DEFAULT_ADDR), "mock server address (env MOCKCTL_ADDR)")
+	token := flag.String("token", os.Getenv("MOCKCTL_TOKEN"), "bearer token for admin api (env MOCKCTL_TOKEN)")
+	basicAuth := flag.String("basic", os.Getenv("MOCKCTL_BASIC"), "basic auth credentials user:password (env MOCKCTL_BASIC)")
+	output := flag.String("o", OUTPUT_TABLE, "output format: table or json")
+	flag.Usage = usage
+	flag.Parse()
+
+	if *output != OUTPUT_TABLE && *output != OUTPUT_JSON {
+		fmt.Fprintf(os.Stderr, "mockctl: unknown output format %q\n", *output)
+		os.Exit(2)
+	}
+
+	args := flag.Args()
+	if len(args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	cmd, ok := commands[args[0]][args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "mockctl: unknown command %q\n", strings.Join(args[:2], " "))
+		usage()
+		os.Exit(2)
+	}
+
+	opts := []client.Option{client.WithToken(*token)}
+	if *basicAuth != "" {
+		username, password, _ := strings.Cut(*basicAuth, ":")
+		opts = append(opts, client.WithBasicAuth(username, password))
+	}
+
+	a := &app{
+		ctx:     context.Background(),
+		client:  client.New(*addr, opts...),
+		printer: &printer{format: *output, out: os.Stdout},
+	}
+
+	err := cmd.run(a, args[2:])
+	switch {
+	case err == nil:
+	case errors.Is(err, errUsage):
+		fmt.Fprintf(os.Stderr, "Usage: mockctl %s %s %s\n", args[0], args[1], cmd.usage)
+		os.Exit(2)
+	default:
+		fmt.Fprintf(os.Stderr, "mockctl: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+// parses subcommand flags and checks number of positional arguments
+func parseArgs(fs *flag.FlagSet, args []string, positional int) ([]string, error) {
+	fs.SetOutput(flag.CommandLine.Output())
+	if err := fs.Parse(args); err != nil {
+		return nil, errUsage
+	}
+	if fs.NArg() != positional {
+		return nil, errUsage
+	}
+	return fs.Args(), nil
+}
+
+func noFlags(name string) *flag.FlagSet {
+	return flag.NewFlagSet(name, flag.ContinueOnError)
+}
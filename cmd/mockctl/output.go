@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	OUTPUT_TABLE = "table"
+	OUTPUT_JSON  = "json"
+)
+
+type printer struct {
+	format string
+	out    io.Writer
+}
+
+// data is printed as is in json format, header and rows are used for table
+func (p *printer) print(data interface{}, header []string, rows [][]string) error {
+	if p.format == OUTPUT_JSON {
+		return p.printJSON(data)
+	}
+
+	w := tabwriter.NewWriter(p.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
+func (p *printer) printJSON(data interface{}) error {
+	enc := json.NewEncoder(p.out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(data)
+}
+
+// prints single value (code, response) without table decoration
+func (p *printer) printValue(data interface{}, value string) error {
+	if p.format == OUTPUT_JSON {
+		return p.printJSON(data)
+	}
+	_, err := fmt.Fprintln(p.out, value)
+	return err
+}
+
+func (p *printer) done(message string) {
+	if p.format == OUTPUT_JSON {
+		return
+	}
+	fmt.Fprintln(p.out, message)
+}
+
+func warn(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "warning: "+format+"\n", args...)
+}
+
+func singleLine(s string) string {
+	s = strings.ReplaceAll(s, "\n", "\\n")
+	if len(s) > 60 {
+		s = s[:57] + "..."
+	}
+	return s
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"mock-server/pkg/client"
+	"os"
+)
+
+// route types managed by mockctl, all of them have the same shape:
+// list of paths, value by path, create/update with path and value and delete by path
+type routeKind struct {
+	name       string
+	valueField string
+	fromFile   bool // value is a script, can be read from file
+
+	list   func(cl *client.Client, ctx context.Context) ([]string, error)
+	get    func(cl *client.Client, ctx context.Context, path string) (string, error)
+	create func(cl *client.Client, ctx context.Context, path string, value string) error
+	update func(cl *client.Client, ctx context.Context, path string, value string) error
+	remove func(cl *client.Client, ctx context.Context, path string) error
+}
+
+var (
+	staticRoutes = routeKind{
+		name:       "static",
+		valueField: "expected_response",
+		list:       (*client.Client).ListStaticRoutes,
+		get:        (*client.Client).GetStaticRouteResponse,
+		create: func(cl *client.Client, ctx context.Context, path string, value string) error {
+			return cl.CreateStaticRoute(ctx, client.StaticEndpoint{Path: path, ExpectedResponse: value})
+		},
+		update: func(cl *client.Client, ctx context.Context, path string, value string) error {
+			return cl.UpdateStaticRoute(ctx, client.StaticEndpoint{Path: path, ExpectedResponse: value})
+		},
+		remove: (*client.Client).DeleteStaticRoute,
+	}
+	proxyRoutes = routeKind{
+		name:       "proxy",
+		valueField: "proxy_url",
+		list:       (*client.Client).ListProxyRoutes,
+		get:        (*client.Client).GetProxyRouteUrl,
+		create: func(cl *client.Client, ctx context.Context, path string, value string) error {
+			return cl.CreateProxyRoute(ctx, client.ProxyEndpoint{Path: path, ProxyUrl: value})
+		},
+		update: func(cl *client.Client, ctx context.Context, path string, value string) error {
+			return cl.UpdateProxyRoute(ctx, client.ProxyEndpoint{Path: path, ProxyUrl: value})
+		},
+		remove: (*client.Client).DeleteProxyRoute,
+	}
+	dynamicRoutes = routeKind{
+		name:       "dynamic",
+		valueField: "code",
+		fromFile:   true,
+		list:       (*client.Client).ListDynamicRoutes,
+		get:        (*client.Client).GetDynamicRouteCode,
+		create: func(cl *client.Client, ctx context.Context, path string, value string) error {
+			return cl.CreateDynamicRoute(ctx, client.DynamicEndpoint{Path: path, Code: value})
+		},
+		update: func(cl *client.Client, ctx context.Context, path string, value string) error {
+			return cl.UpdateDynamicRoute(ctx, client.DynamicEndpoint{Path: path, Code: value})
+		},
+		remove: (*client.Client).DeleteDynamicRoute,
+	}
+)
+
+type routeConfig struct {
+	Path  string `json:"path"`
+	Value string `json:"value"`
+}
+
+func (k routeKind) valueUsage() string {
+	if k.fromFile {
+		return "(-file <script.py> | -code <code>) <path>"
+	}
+	return "<path> <" + k.valueField + ">"
+}
+
+// value is given as second positional argument or, for scripts, with -file/-code flags
+func (k routeKind) parseValue(name string, args []string) (string, string, error) {
+	fs := noFlags(name)
+	if !k.fromFile {
+		rest, err := parseArgs(fs, args, 2)
+		if err != nil {
+			return "", "", err
+		}
+		return rest[0], rest[1], nil
+	}
+
+	file := fs.String("file", "", "read code from file")
+	code := fs.String("code", "", "code of handler")
+	rest, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return "", "", err
+	}
+
+	switch {
+	case *file != "" && *code != "":
+		return "", "", errors.New("-file and -code are mutually exclusive")
+	case *file != "":
+		data, err := os.ReadFile(*file)
+		if err != nil {
+			return "", "", err
+		}
+		return rest[0], string(data), nil
+	case *code != "":
+		return rest[0], *code, nil
+	default:
+		return "", "", errUsage
+	}
+}
+
+func routeCommands(k routeKind) map[string]command {
+	return map[string]command{
+		"list": {
+			usage: "",
+			run: func(a *app, args []string) error {
+				if _, err := parseArgs(noFlags("list"), args, 0); err != nil {
+					return err
+				}
+				paths, err := k.list(a.client, a.ctx)
+				if err != nil {
+					return err
+				}
+
+				rows := make([][]string, len(paths))
+				for i, path := range paths {
+					rows[i] = []string{path}
+				}
+				return a.printer.print(paths, []string{"PATH"}, rows)
+			},
+		},
+		"get": {
+			usage: "<path>",
+			run: func(a *app, args []string) error {
+				rest, err := parseArgs(noFlags("get"), args, 1)
+				if err != nil {
+					return err
+				}
+				value, err := k.get(a.client, a.ctx, rest[0])
+				if err != nil {
+					return err
+				}
+				return a.printer.printValue(routeConfig{Path: rest[0], Value: value}, value)
+			},
+		},
+		"create": {
+			usage: k.valueUsage(),
+			run: func(a *app, args []string) error {
+				path, value, err := k.parseValue("create", args)
+				if err != nil {
+					return err
+				}
+				if err := k.create(a.client, a.ctx, path, value); err != nil {
+					return err
+				}
+				a.printer.done(k.name + " route " + path + " created")
+				return nil
+			},
+		},
+		"update": {
+			usage: k.valueUsage(),
+			run: func(a *app, args []string) error {
+				path, value, err := k.parseValue("update", args)
+				if err != nil {
+					return err
+				}
+				if err := k.update(a.client, a.ctx, path, value); err != nil {
+					return err
+				}
+				a.printer.done(k.name + " route " + path + " updated")
+				return nil
+			},
+		},
+		"delete": {
+			usage: "<path>",
+			run: func(a *app, args []string) error {
+				rest, err := parseArgs(noFlags("delete"), args, 1)
+				if err != nil {
+					return err
+				}
+				if err := k.remove(a.client, a.ctx, rest[0]); err != nil {
+					return err
+				}
+				a.printer.done(k.name + " route " + rest[0] + " deleted")
+				return nil
+			},
+		},
+	}
+}
@@ -20,7 +20,7 @@ import (
 func play_brokers() {
 	// broker example
 
-	handler, err := brokers.AddMessagePool(brokers.NewRabbitMQMessagePool("test-pool", "test-mock-queue"))
+	handler, err := brokers.AddMessagePool(context.TODO(), brokers.NewRabbitMQMessagePool("test-pool", "test-mock-queue"))
 	if err != nil {
 		zlog.Error().Err(err).Msg("add new pool failed")
 	}
@@ -30,7 +30,7 @@ func play_brokers() {
 
 	time.Sleep(1 * time.Second)
 
-	handler, err = brokers.GetMessagePool("test-pool")
+	handler, err = brokers.GetMessagePool(context.TODO(), "test-pool")
 	if err != nil {
 		zlog.Error().Err(err).Msg("get pool failed")
 	}
@@ -69,13 +69,13 @@ func play_coderun() {
 `))
 
 	for i := 0; i < 10; i += 1 {
-		worker, err := coderun.WorkerWatcher.BorrowWorker()
+		worker, err := coderun.WorkerWatcher.BorrowWorker(context.Background())
 		if err != nil {
 			zlog.Error().Err(err).Msg("borrow worker")
 			return
 		}
 
-		out, err := worker.RunScript("mapper", "test.py", ARGS)
+		out, err := worker.RunScript(context.Background(), "mapper", "test.py", ARGS)
 		if err != nil {
 			zlog.Error().Err(err).Msg("run script")
 			return
@@ -106,9 +106,9 @@ def func(msgs):
 `)
 	var ARGS_HARD = []string{"msg1", "msg2", "msg3"}
 
-	pool1, _ := brokers.AddMessagePool(brokers.NewRabbitMQMessagePool("test-pool-1", "test-mock-queue-1"))
-	pool2, _ := brokers.AddMessagePool(brokers.NewRabbitMQMessagePool("test-pool-2", "test-mock-queue-2"))
-	pool3, _ := brokers.AddMessagePool(brokers.NewRabbitMQMessagePool("test-pool-3", "test-mock-queue-3"))
+	pool1, _ := brokers.AddMessagePool(context.TODO(), brokers.NewRabbitMQMessagePool("test-pool-1", "test-mock-queue-1"))
+	pool2, _ := brokers.AddMessagePool(context.TODO(), brokers.NewRabbitMQMessagePool("test-pool-2", "test-mock-queue-2"))
+	pool3, _ := brokers.AddMessagePool(context.TODO(), brokers.NewRabbitMQMessagePool("test-pool-3", "test-mock-queue-3"))
 
 	fs, _ := util.NewFileStorageDriver("coderun")
 
@@ -259,7 +259,7 @@ func play_kafka() {
 		}
 	}()
 
-	handler, err := brokers.AddMessagePool(brokers.NewKafkaMessagePool("test-pool-kafka", "test-topic"))
+	handler, err := brokers.AddMessagePool(context.TODO(), brokers.NewKafkaMessagePool("test-pool-kafka", "test-topic"))
 	if err != nil {
 		zlog.Error().Err(err).Msg("add new pool failed")
 	}
@@ -269,7 +269,7 @@ func play_kafka() {
 
 	time.Sleep(1 * time.Second)
 
-	handler, err = brokers.GetMessagePool("test-pool-kafka")
+	handler, err = brokers.GetMessagePool(context.TODO(), "test-pool-kafka")
 	if err != nil {
 		zlog.Error().Err(err).Msg("get pool failed")
 	}
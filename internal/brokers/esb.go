@@ -11,15 +11,15 @@ import (
 
 const EMPTY_MAPPER = ""
 
-func runMapper(mapper_name string, msgs []string) ([]string, error) {
-	worker, err := coderun.WorkerWatcher.BorrowWorker()
+func runMapper(ctx context.Context, mapper_name string, msgs []string) ([]string, error) {
+	worker, err := coderun.WorkerWatcher.BorrowWorker(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	defer worker.Return()
 
-	out, err := worker.RunScript("mapper", mapper_name, coderun.NewMapperArgs(msgs))
+	out, err := worker.RunScript(ctx, "mapper", mapper_name, coderun.NewMapperArgs(msgs))
 	if err != nil {
 		return nil, err
 	}
@@ -34,16 +34,17 @@ func runMapper(mapper_name string, msgs []string) ([]string, error) {
 	return mappedMsgs, nil
 }
 
-func submitToESB(record database.ESBRecord, msgs []string) error {
+// ctx is the one of read task, so mapping stops with the task
+func submitToESB(ctx context.Context, record database.ESBRecord, msgs []string) error {
 	zlog.Info().Str("pool_in", record.PoolNameIn).Str("pool_out", record.PoolNameOut).Msg("using esb record")
 
-	handler, err := GetMessagePool(record.PoolNameOut)
+	handler, err := GetMessagePool(ctx, record.PoolNameOut)
 	if err != nil {
 		return err
 	}
 
 	if record.MapperScriptName != EMPTY_MAPPER {
-		msgs, err = runMapper(record.MapperScriptName, msgs)
+		msgs, err = runMapper(ctx, record.MapperScriptName, msgs)
 		if err != nil {
 			return err
 		}
@@ -74,6 +75,15 @@ func AddEsbRecordWithMapper(ctx context.Context, pool_name_in string, pool_name_
 	})
 }
 
+// empty mapper script name removes mapper
+func UpdateEsbRecord(ctx context.Context, pool_name_in string, pool_name_out string, mapperScriptName string) error {
+	return database.UpdateESBRecord(ctx, database.ESBRecord{
+		PoolNameIn:       pool_name_in,
+		PoolNameOut:      pool_name_out,
+		MapperScriptName: mapperScriptName,
+	})
+}
+
 func RemoveEsbRecord(ctx context.Context, pool_name_in string) error {
 	return database.RemoveESBRecord(ctx, pool_name_in)
 }
@@ -0,0 +1,53 @@
+package brokers
+
+import (
+	"context"
+	"mock-server/internal/configs"
+	"time"
+
+	kafka "github.com/confluentinc/confluent-kafka-go/kafka"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const defaultPingTimeout = 5 * time.Second
+
+// checks that rabbitmq from config accepts connections
+func PingRabbitMQ(ctx context.Context) error {
+	s, err := configs.GetRabbitMQConnectionConfig()
+	if err != nil {
+		return err
+	}
+
+	timeout := defaultPingTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+
+	conn, err := amqp.DialConfig(getRabbitMqConnectionString(s), amqp.Config{
+		Heartbeat: 10 * time.Second,
+		Locale:    "en_US",
+		Dial:      amqp.DefaultDial(timeout),
+	})
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// checks that kafka from config responds with cluster metadata,
+// ctx must have deadline
+func PingKafka(ctx context.Context) error {
+	s, err := configs.GetKafkaConnectionConfig()
+	if err != nil {
+		return err
+	}
+
+	admin, err := kafka.NewAdminClient(&kafka.ConfigMap{"bootstrap.servers": getKafkaConnectionString(s)})
+	if err != nil {
+		return err
+	}
+	defer admin.Close()
+
+	_, err = admin.ClusterID(ctx)
+	return err
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"mock-server/internal/configs"
 	"mock-server/internal/database"
+	"mock-server/internal/metrics"
 	"sync/atomic"
 	"time"
 
@@ -141,17 +142,19 @@ func (t *kafkaReadTask) read(ctx context.Context) error {
 				if databaseErr := database.AddTaskMessage(context.TODO(), database.TaskMessage{
 					TaskId:  string(t.getTaskId()),
 					Message: string(e.Value),
-				}); err != nil {
-					zlog.Err(err).Msg(fmt.Sprintf("Failed to upload message for task: %s", t.getTaskId()))
+				}); databaseErr != nil {
+					zlog.Err(databaseErr).Msg(fmt.Sprintf("Failed to upload message for task: %s", t.getTaskId()))
 					err = databaseErr
 					run.Store(false)
+				} else {
+					metrics.IncBrokerMessages(t.pool.name, t.pool.GetBroker(), metrics.DIRECTION_READ)
 				}
 			case kafka.Error:
 				err = e
 				run.Store(false)
 			default:
 				if has_esb_record && len(t.msgs) > 0 {
-					if esb_err := submitToESB(esb_record, t.msgs); esb_err != nil {
+					if esb_err := submitToESB(ctx, esb_record, t.msgs); esb_err != nil {
 						err = esb_err
 						run.Store(false)
 					}
@@ -237,6 +240,7 @@ func (t *kafkaWriteTask) write(ctx context.Context) error {
 					zlog.Err(err).Msg(fmt.Sprintf("Failed to upload message for task: %s", t.getTaskId()))
 					return err
 				}
+				metrics.IncBrokerMessages(t.pool.name, t.pool.GetBroker(), metrics.DIRECTION_WRITTEN)
 			}
 		}
 	}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"sync"
 
+	"mock-server/internal/chaos"
 	"mock-server/internal/configs"
+	"mock-server/internal/metrics"
 	"mock-server/internal/util"
 
 	zlog "github.com/rs/zerolog/log"
@@ -89,12 +91,20 @@ func (mps *mpTaskScheduler) Errors() <-chan TaskError {
 func (mps *mpTaskScheduler) submitReadTask(task qReadTask) TaskId {
 	if mps.running_read_tasks.Insert(task.getTaskId()) {
 		mps.read_tasks.Put(task)
+		metrics.SetSchedulerQueueDepth(metrics.QUEUE_READ, mps.read_tasks.Len())
 	}
 	return task.getTaskId()
 }
 
 func (mps *mpTaskScheduler) submitWriteTask(task qWriteTask) TaskId {
+	if chaos.DropWriteTask() {
+		zlog.Warn().Str("task", string(task.getTaskId())).Msg("write task dropped by chaos profile")
+		metrics.IncSchedulerTask(metrics.QUEUE_WRITE, metrics.OUTCOME_DROPPED)
+		return task.getTaskId()
+	}
+
 	mps.write_tasks.Put(task)
+	metrics.SetSchedulerQueueDepth(metrics.QUEUE_WRITE, mps.write_tasks.Len())
 	return task.getTaskId()
 }
 
@@ -112,6 +122,17 @@ func (mps *mpTaskScheduler) submitError(id TaskId, err error) {
 	}
 }
 
+func taskOutcome(err error) string {
+	switch err {
+	case nil:
+		return metrics.OUTCOME_SUCCESS
+	case context.Canceled, context.DeadlineExceeded:
+		return metrics.OUTCOME_TIMEOUT
+	default:
+		return metrics.OUTCOME_ERROR
+	}
+}
+
 func qread(ctx context.Context, task qReadTask) error {
 	zlog.Info().Str("task", string(task.getTaskId())).Msg("started")
 	if err := task.connectAndPrepare(ctx); err != nil {
@@ -137,8 +158,11 @@ func (mps *mpTaskScheduler) rWorkerRoutine() {
 			return
 		}
 		task := elem.Unwrap()
+		metrics.SetSchedulerQueueDepth(metrics.QUEUE_READ, mps.read_tasks.Len())
 		task_ctx, cancel := context.WithTimeout(mps.ctx, mps.cfg.Read_timeout)
-		if err := qread(task_ctx, task); err != nil && err != context.Canceled && err != context.DeadlineExceeded {
+		err := qread(task_ctx, task)
+		metrics.IncSchedulerTask(metrics.QUEUE_READ, taskOutcome(err))
+		if err != nil && err != context.Canceled && err != context.DeadlineExceeded {
 			mps.submitError(task.getTaskId(), err)
 		}
 		mps.running_read_tasks.Remove(task.getTaskId())
@@ -171,8 +195,11 @@ func (mps *mpTaskScheduler) wWorkerRoutine() {
 			return
 		}
 		task := elem.Unwrap()
+		metrics.SetSchedulerQueueDepth(metrics.QUEUE_WRITE, mps.write_tasks.Len())
 		task_ctx, cancel := context.WithTimeout(mps.ctx, mps.cfg.Write_timeout)
-		if err := qwrite(task_ctx, task); err != nil && err != context.Canceled && err != context.DeadlineExceeded {
+		err := qwrite(task_ctx, task)
+		metrics.IncSchedulerTask(metrics.QUEUE_WRITE, taskOutcome(err))
+		if err != nil && err != context.Canceled && err != context.DeadlineExceeded {
 			mps.submitError(task.getTaskId(), err)
 		}
 		cancel()
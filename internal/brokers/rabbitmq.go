@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"mock-server/internal/configs"
 	"mock-server/internal/database"
+	"mock-server/internal/metrics"
 	"time"
 
 	amqp "github.com/rabbitmq/amqp091-go"
@@ -188,9 +189,10 @@ func (t *rabbitMQReadTask) read(ctx context.Context) error {
 				zlog.Err(err).Msg(fmt.Sprintf("Failed to upload message for task: %s", t.getTaskId()))
 				return err
 			}
+			metrics.IncBrokerMessages(t.pool.name, t.pool.GetBroker(), metrics.DIRECTION_READ)
 		case <-ticker.C:
 			if has_esb_record && len(t.msgs) > 0 {
-				if err := submitToESB(esb_record, t.msgs); err != nil {
+				if err := submitToESB(ctx, esb_record, t.msgs); err != nil {
 					return err
 				}
 				t.msgs = make([]string, 0)
@@ -236,6 +238,7 @@ func (t *rabbitMQWriteTask) write(ctx context.Context) error {
 			zlog.Err(err).Msg(fmt.Sprintf("Failed to upload message for task: %s", t.getTaskId()))
 			return err
 		}
+		metrics.IncBrokerMessages(t.pool.name, t.pool.GetBroker(), metrics.DIRECTION_WRITTEN)
 	}
 
 	return nil
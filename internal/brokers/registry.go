@@ -33,7 +33,7 @@ func createFromDatabase(pool database.MessagePool) (MessagePool, error) {
 	}
 }
 
-func AddMessagePool(pool MessagePool) (MessagePool, error) {
+func AddMessagePool(ctx context.Context, pool MessagePool) (MessagePool, error) {
 	if err := pool.CreateBrokerEndpoint(); err != nil {
 		return nil, err
 	}
@@ -42,7 +42,7 @@ func AddMessagePool(pool MessagePool) (MessagePool, error) {
 	if err != nil {
 		return nil, err
 	}
-	err = database.AddMessagePool(context.TODO(), database.MessagePool{
+	err = database.AddMessagePool(ctx, database.MessagePool{
 		Name:   pool.GetName(),
 		Queue:  pool.GetQueue(),
 		Broker: pool.GetBroker(),
@@ -52,8 +52,8 @@ func AddMessagePool(pool MessagePool) (MessagePool, error) {
 	return pool, err
 }
 
-func RemoveMessagePool(poolName string) error {
-	pool, err := GetMessagePool(poolName)
+func RemoveMessagePool(ctx context.Context, poolName string) error {
+	pool, err := GetMessagePool(ctx, poolName)
 	if err != nil {
 		return err
 	}
@@ -62,14 +62,45 @@ func RemoveMessagePool(poolName string) error {
 		return err
 	}
 
-	err = database.RemoveMessagePool(context.TODO(), poolName)
+	err = database.RemoveMessagePool(ctx, poolName)
 	return err
 }
 
-func GetMessagePool(poolName string) (MessagePool, error) {
-	pool, err := database.GetMessagePool(context.TODO(), poolName)
+func GetMessagePool(ctx context.Context, poolName string) (MessagePool, error) {
+	pool, err := database.GetMessagePool(ctx, poolName)
 	if err != nil {
 		return nil, err
 	}
 	return createFromDatabase(pool)
 }
+
+// puts pool back to snapshot state, broker endpoint follows the stored pool
+func RestoreMessagePool(ctx context.Context, poolName string, snapshot database.Snapshot) error {
+	current, err := GetMessagePool(ctx, poolName)
+	switch err {
+	case nil:
+		if err := current.RemoveBrokerEndpoint(); err != nil {
+			return err
+		}
+	case database.ErrNoSuchPool:
+	default:
+		return err
+	}
+
+	if err := database.RestoreMessagePool(ctx, poolName, snapshot); err != nil {
+		return err
+	}
+	if !snapshot.Exists() {
+		return nil
+	}
+
+	var stored database.MessagePool
+	if err := snapshot.Decode(&stored); err != nil {
+		return err
+	}
+	pool, err := createFromDatabase(stored)
+	if err != nil {
+		return err
+	}
+	return pool.CreateBrokerEndpoint()
+}
@@ -0,0 +1,156 @@
+package chaos
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// prefix of profile applied to the whole server
+const GLOBAL_PREFIX = ""
+
+// status of failed requests if profile lists none
+const DEFAULT_ERROR_STATUS = 500
+
+// percentages are of requests or write tasks affected, 0..100
+type Profile struct {
+	// namespace prefix, GLOBAL_PREFIX for the whole server
+	Prefix        string
+	ErrorPercent  float64
+	ErrorStatuses []int
+	DelayPercent  float64
+	DelayMin      time.Duration
+	DelayMax      time.Duration
+	ResetPercent  float64
+	// broker write tasks are not bound to namespaces, so only global profile drops them
+	DropWritePercent float64
+	// same seed gives the same sequence of decisions
+	Seed int64
+}
+
+// faults injected into one request, delay comes before reset or failure
+type Decision struct {
+	Delay time.Duration
+	Reset bool
+	// 0 if request is not failed
+	Status int
+}
+
+type profileState struct {
+	profile Profile
+	rnd     *rand.Rand
+}
+
+func (ps *profileState) hit(percent float64) bool {
+	return percent > 0 && ps.rnd.Float64()*100 < percent
+}
+
+func (ps *profileState) decide() Decision {
+	var decision Decision
+	p := &ps.profile
+
+	if ps.hit(p.DelayPercent) {
+		decision.Delay = p.DelayMin
+		if p.DelayMax > p.DelayMin {
+			decision.Delay += time.Duration(ps.rnd.Int63n(int64(p.DelayMax - p.DelayMin + 1)))
+		}
+	}
+	if ps.hit(p.ResetPercent) {
+		decision.Reset = true
+	} else if ps.hit(p.ErrorPercent) {
+		decision.Status = DEFAULT_ERROR_STATUS
+		if len(p.ErrorStatuses) != 0 {
+			decision.Status = p.ErrorStatuses[ps.rnd.Intn(len(p.ErrorStatuses))]
+		}
+	}
+	return decision
+}
+
+// profiles are kept in memory of the instance and lost on restart
+var (
+	profiles = make(map[string]*profileState)
+	mtx      sync.Mutex
+)
+
+// enables or replaces profile of its prefix, zero seed is replaced
+// by random one; returns profile with the seed in use
+func SetProfile(profile Profile) Profile {
+	if profile.Seed == 0 {
+		profile.Seed = time.Now().UnixNano()
+	}
+
+	mtx.Lock()
+	defer mtx.Unlock()
+
+	profiles[profile.Prefix] = &profileState{
+		profile: profile,
+		rnd:     rand.New(rand.NewSource(profile.Seed)),
+	}
+	return profile
+}
+
+// false if profile was not enabled
+func RemoveProfile(prefix string) bool {
+	mtx.Lock()
+	defer mtx.Unlock()
+
+	_, ok := profiles[prefix]
+	delete(profiles, prefix)
+	return ok
+}
+
+// disables profiles of all prefixes
+func RemoveAllProfiles() {
+	mtx.Lock()
+	defer mtx.Unlock()
+
+	profiles = make(map[string]*profileState)
+}
+
+// sorted by prefix, global profile first
+func ListProfiles() []Profile {
+	mtx.Lock()
+	defer mtx.Unlock()
+
+	list := make([]Profile, 0, len(profiles))
+	for _, state := range profiles {
+		list = append(list, state.profile)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Prefix < list[j].Prefix
+	})
+	return list
+}
+
+func Enabled() bool {
+	mtx.Lock()
+	defer mtx.Unlock()
+
+	return len(profiles) != 0
+}
+
+// faults for request to route in namespace with prefix, profile of namespace
+// takes precedence over global one; false if no profile applies
+func Decide(namespacePrefix string) (Decision, bool) {
+	mtx.Lock()
+	defer mtx.Unlock()
+
+	state, ok := profiles[namespacePrefix]
+	if !ok {
+		state, ok = profiles[GLOBAL_PREFIX]
+	}
+	if !ok {
+		return Decision{}, false
+	}
+	return state.decide(), true
+}
+
+// whether broker write task is dropped instead of being scheduled
+func DropWriteTask() bool {
+	mtx.Lock()
+	defer mtx.Unlock()
+
+	state, ok := profiles[GLOBAL_PREFIX]
+	return ok && state.hit(state.profile.DropWritePercent)
+}
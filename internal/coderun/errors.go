@@ -4,3 +4,4 @@ import "errors"
 
 var ErrCodeRunFailed = errors.New("coderun failed")
 var ErrWorkerFailed = errors.New("worker failed")
+var ErrNoRunningWorker = errors.New("no running worker now")
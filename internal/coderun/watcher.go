@@ -7,6 +7,8 @@ import (
 	"io"
 	"mock-server/internal/coderun/docker-provider"
 	"mock-server/internal/configs"
+	"mock-server/internal/metrics"
+	"mock-server/internal/tracing"
 	"mock-server/internal/util"
 	"net"
 	"net/http"
@@ -15,6 +17,8 @@ import (
 
 	"github.com/docker/docker/api/types"
 	zlog "github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var WorkerWatcher = &watcher{}
@@ -49,7 +53,33 @@ func NewMapperArgs(msgs []string) *Args {
 	return args
 }
 
-func (w *worker) RunScript(run_type string, script string, args *Args) ([]byte, error) {
+// ctx carries trace of the caller, run is bounded by handle timeout of watcher
+func (w *worker) RunScript(ctx context.Context, run_type string, script string, args *Args) ([]byte, error) {
+	ctx, span := tracing.Start(ctx, "coderun.RunScript", trace.WithAttributes(
+		attribute.String("coderun.run_type", run_type),
+		attribute.String("coderun.script", script),
+		attribute.String("coderun.worker_port", w.port),
+	))
+	defer span.End()
+
+	start := time.Now()
+	out, err := w.runScript(ctx, run_type, script, args)
+	tracing.RecordError(span, err)
+
+	outcome := metrics.OUTCOME_SUCCESS
+	switch err {
+	case nil:
+	case ErrCodeRunFailed:
+		outcome = metrics.OUTCOME_SCRIPT_ERROR
+	default:
+		outcome = metrics.OUTCOME_ERROR
+	}
+	metrics.ObserveCoderunScript(run_type, outcome, time.Since(start))
+
+	return out, err
+}
+
+func (w *worker) runScript(traceCtx context.Context, run_type string, script string, args *Args) ([]byte, error) {
 	zlog.Info().Str("run_type", run_type).Str("script", script).Msg("preparing worker request")
 
 	var byteArgs []byte
@@ -75,6 +105,7 @@ func (w *worker) RunScript(run_type string, script string, args *Args) ([]byte,
 
 	req.Header.Add("RunType", run_type)
 	req.Header.Add("Script", script)
+	tracing.Inject(traceCtx, req.Header)
 
 	res, err := http.DefaultClient.Do(req)
 	if err != nil {
@@ -110,6 +141,7 @@ type watcher struct {
 	dp           *docker.DockerProvider
 	workers      chan *worker
 	repair       chan *worker
+	mtx          sync.Mutex // guards workerByPort
 	workerByPort map[string]*worker
 }
 
@@ -150,7 +182,9 @@ func (w *watcher) startNewWorker() error {
 	}
 
 	w.workers <- &worker
+	w.mtx.Lock()
 	w.workerByPort[port] = &worker
+	w.mtx.Unlock()
 
 	return nil
 }
@@ -177,7 +211,9 @@ func (w *watcher) repairLoop() {
 			info, err := w.dp.InspectWorkerContainer(worker.cId)
 			if err != nil {
 				w.dp.RemoveWorkerContainer(worker.cId, true) // nolint:errcheck
+				w.mtx.Lock()
 				delete(w.workerByPort, worker.port)
+				w.mtx.Unlock()
 				w.startNewWorker() // nolint:errcheck
 			} else {
 				w.processWorkerInfo(worker, &info)
@@ -226,15 +262,40 @@ func (w *watcher) Init(ctx context.Context, cfg *configs.CoderunConfig) error {
 	return nil
 }
 
-func (w *watcher) BorrowWorker() (*worker, error) {
+// ctx carries trace of the caller, wait is bounded by handle timeout of watcher
+func (w *watcher) BorrowWorker(ctx context.Context) (*worker, error) {
+	_, span := tracing.Start(ctx, "coderun.BorrowWorker")
+	defer span.End()
+
+	start := time.Now()
+	worker, err := w.borrowWorker(ctx)
+	tracing.RecordError(span, err)
+
+	outcome := metrics.OUTCOME_SUCCESS
+	switch err {
+	case nil:
+	case ErrNoRunningWorker, context.DeadlineExceeded:
+		outcome = metrics.OUTCOME_TIMEOUT
+	default:
+		outcome = metrics.OUTCOME_ERROR
+	}
+	metrics.ObserveCoderunBorrow(outcome, time.Since(start))
+
+	return worker, err
+}
+
+// waits for free worker until handle timeout or until caller gives up
+func (w *watcher) borrowWorker(ctx context.Context) (*worker, error) {
 	zlog.Info().Msg("trying to borrow worker")
-	ctx, cancel := context.WithTimeout(w.ctx, w.cfg.WorkerConfig.HandleTimeout)
+	timeoutCtx, cancel := context.WithTimeout(w.ctx, w.cfg.WorkerConfig.HandleTimeout)
 	defer cancel()
 
 	for {
 		select {
 		case <-ctx.Done():
-			return nil, fmt.Errorf("no running worker now")
+			return nil, ctx.Err()
+		case <-timeoutCtx.Done():
+			return nil, ErrNoRunningWorker
 		case worker := <-w.workers:
 			info, err := w.dp.InspectWorkerContainer(worker.cId)
 			if err != nil {
@@ -251,6 +312,25 @@ func (w *watcher) BorrowWorker() (*worker, error) {
 	}
 }
 
+// number of workers with running container and configured number of workers
+func (w *watcher) RunningWorkers() (running int, total int) {
+	w.mtx.Lock()
+	workers := make([]*worker, 0, len(w.workerByPort))
+	for _, worker := range w.workerByPort {
+		workers = append(workers, worker)
+	}
+	w.mtx.Unlock()
+
+	for _, worker := range workers {
+		info, err := w.dp.InspectWorkerContainer(worker.cId)
+		if err == nil && info.State.Running {
+			running += 1
+		}
+	}
+
+	return running, w.cfg.WorkerCnt
+}
+
 func (w *watcher) Stop() {
 	zlog.Info().Msg("stopping watcher")
 	w.wg.Wait()
@@ -1,10 +1,66 @@
 package configs
 
+import (
+	"strings"
+	"time"
+)
+
+const (
+	DATABASE_STORAGE_MONGO = "mongo"
+	// state in process memory, no mongod is started or connected
+	DATABASE_STORAGE_MEMORY = "memory"
+)
+
+type DatabaseTLSConfig struct {
+	// pem file with certificates of trusted authorities, system pool if empty
+	CAFile string `yaml:"ca_file"`
+	// pem files of client certificate and its key, for x.509 authentication
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+	// skips verification of server certificate and host name
+	Insecure bool `yaml:"insecure"`
+}
+
 type DatabaseConfig struct {
+	// "mongo" if empty, other options apply to mongo storage
+	Storage string `yaml:"storage"`
+	// embedded mongod, state is lost on restart
 	InMemory  bool `yaml:"inmemory"`
 	CacheSize int  `yaml:"cache_size"`
+	// connection string of external deployment, used if inmemory is false,
+	// printed with loaded config without credentials
+	URI string `yaml:"uri"`
+	// credentials override the ones in the uri
+	Username string `yaml:"username"`
+	// not printed with loaded config
+	Password   string `yaml:"password" json:"-"`
+	AuthSource string `yaml:"auth_source"`
+	// "mongo_storage" if empty
+	Name string `yaml:"name"`
+	// tls is enabled if specified, tls options of the uri apply otherwise
+	TLS *DatabaseTLSConfig `yaml:"tls,omitempty"`
+	// of connecting and of the startup ping, 10s if not set
+	ConnectTimeout time.Duration `yaml:"connect_timeout"`
 }
 
 func GetDatabaseConfig() *DatabaseConfig {
 	return &config.Database
 }
+
+// replaces user info of connection string, hosts of mongodb uri
+// may be a list, so it is not parsed as url
+func redactURI(uri string) string {
+	scheme := strings.Index(uri, "://")
+	if scheme < 0 {
+		return uri
+	}
+	rest := uri[scheme+len("://"):]
+	if slash := strings.IndexAny(rest, "/?"); slash >= 0 {
+		rest = rest[:slash]
+	}
+	at := strings.LastIndex(rest, "@")
+	if at < 0 {
+		return uri
+	}
+	return uri[:scheme+len("://")] + "xxxxx" + uri[scheme+len("://")+at:]
+}
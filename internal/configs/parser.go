@@ -20,6 +20,7 @@ type ServiceConfig struct {
 	Server     *ServerConfig    `yaml:"server,omitempty"`
 	Logs       LogConfig        `yaml:"logs"`
 	Database   DatabaseConfig   `yaml:"database"`
+	Tracing    *TracingConfig   `yaml:"tracing,omitempty"`
 }
 
 var config ServiceConfig
@@ -32,6 +33,15 @@ func SetConfigureForTestingFunc(configureForTestingFunc ConfigureForTestingFunc)
 	configureForTesting = configureForTestingFunc
 }
 
+// sets config directly instead of reading config file, used by embedded server
+func SetConfig(cfg ServiceConfig) {
+	config = cfg
+
+	if configureForTesting != nil {
+		configureForTesting(&config)
+	}
+}
+
 func LoadConfig() {
 	cfg_path := os.Getenv("CONFIG_PATH")
 	if cfg_path == "" {
@@ -56,6 +66,8 @@ func LoadConfig() {
 		panic(err)
 	}
 
+	// sections missing in the file must not be kept from previously loaded config
+	config = ServiceConfig{}
 	if err = yaml.Unmarshal(cfg, &config); err != nil {
 		zlog.Err(err).Msg("Unmarshal config failed")
 		panic(err)
@@ -65,7 +77,9 @@ func LoadConfig() {
 		configureForTesting(&config)
 	}
 
-	s, err := json.MarshalIndent(config, "", "\t")
+	printed := config
+	printed.Database.URI = redactURI(config.Database.URI)
+	s, err := json.MarshalIndent(printed, "", "\t")
 	if err == nil {
 		fmt.Println("Config", string(s))
 	}
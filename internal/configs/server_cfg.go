@@ -2,11 +2,47 @@ package configs
 
 import "time"
 
+type CorsConfig struct {
+	AllowOrigins     []string      `yaml:"allow_origins"`
+	AllowMethods     []string      `yaml:"allow_methods"`
+	AllowHeaders     []string      `yaml:"allow_headers"`
+	ExposeHeaders    []string      `yaml:"expose_headers"`
+	AllowCredentials bool          `yaml:"allow_credentials"`
+	MaxAge           time.Duration `yaml:"max_age"`
+}
+
+const (
+	AUTH_ROLE_READ_ONLY = "read_only"
+	AUTH_ROLE_ADMIN     = "admin"
+)
+
+// passed as "Authorization: Bearer <token>", secrets are not printed with loaded config
+type AuthToken struct {
+	Name  string `yaml:"name"`
+	Token string `yaml:"token" json:"-"`
+	Role  string `yaml:"role"`
+}
+
+type AuthUser struct {
+	Username string `yaml:"username"`
+	Password string `yaml:"password" json:"-"`
+	Role     string `yaml:"role"`
+}
+
+type AuthConfig struct {
+	Tokens []AuthToken `yaml:"tokens"`
+	Users  []AuthUser  `yaml:"users"`
+}
+
 type ServerConfig struct {
 	Addr             string        `yaml:"addr"`
 	AcceptTimeout    time.Duration `yaml:"accept_timeout"`
 	ResponseTimeout  time.Duration `yaml:"response_timeout"`
 	DeployProduction bool          `yaml:"deploy_production"`
+	// cors policy of admin api, all origins are allowed if not specified
+	AdminCors *CorsConfig `yaml:"admin_cors,omitempty"`
+	// admin api is open if not specified
+	Auth *AuthConfig `yaml:"auth,omitempty"`
 }
 
 func GetServerConfig() *ServerConfig {
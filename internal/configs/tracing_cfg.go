@@ -0,0 +1,16 @@
+package configs
+
+type TracingConfig struct {
+	// otlp grpc collector address, e.g. "localhost:4317"
+	Endpoint string `yaml:"endpoint"`
+	// connect without tls
+	Insecure    bool   `yaml:"insecure"`
+	ServiceName string `yaml:"service_name"`
+	// fraction of new traces to record, traces started by callers follow their decision
+	SampleRatio float64 `yaml:"sample_ratio"`
+}
+
+// nil if tracing is not configured
+func GetTracingConfig() *TracingConfig {
+	return config.Tracing
+}
@@ -4,13 +4,16 @@ import (
 	"context"
 	"fmt"
 	"mock-server/internal/brokers"
+	"mock-server/internal/chaos"
 	"mock-server/internal/coderun"
 	"mock-server/internal/configs"
 	"mock-server/internal/database"
 	"mock-server/internal/logger"
 	"mock-server/internal/server"
+	"mock-server/internal/tracing"
 	"os/signal"
 	"syscall"
+	"time"
 
 	zlog "github.com/rs/zerolog/log"
 )
@@ -35,6 +38,11 @@ func (c *componentsManager) Start() {
 	logger.Init(configs.GetLogConfig())
 	zlog.Info().Msg("starting...")
 
+	// init tracing before components producing spans
+	if err := tracing.Init(c.ctx, configs.GetTracingConfig()); err != nil {
+		panic(fmt.Errorf("tracing expected to init but failed: %s", err.Error()))
+	}
+
 	err := database.InitDB(c.ctx, configs.GetDatabaseConfig())
 	if err != nil {
 		panic(err)
@@ -85,8 +93,18 @@ func (c *componentsManager) Stop() {
 		coderun.WorkerWatcher.Stop()
 	}
 
+	// profiles must not outlive the instance they were enabled on
+	chaos.RemoveAllProfiles()
+
 	err := database.Disconnect(c.ctx)
 	if err != nil {
 		panic(err)
 	}
+
+	// flush spans of stopped components, root context is already cancelled
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := tracing.Shutdown(ctx); err != nil {
+		zlog.Error().Err(err).Msg("failed to flush spans")
+	}
 }
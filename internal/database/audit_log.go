@@ -0,0 +1,121 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"mock-server/internal/configs"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// audit log is append only, records are never updated
+type auditLog struct {
+	coll *mongo.Collection
+}
+
+func createAuditLog(ctx context.Context, client *mongo.Client, cfg *configs.DatabaseConfig) (*auditLog, error) {
+	a := &auditLog{}
+	err := a.init(ctx, client, cfg)
+	return a, err
+}
+
+func (a *auditLog) init(ctx context.Context, client *mongo.Client, cfg *configs.DatabaseConfig) error {
+	a.coll = client.Database(databaseName(cfg)).Collection(AUDIT_COLLECTION)
+
+	indexModels := []mongo.IndexModel{
+		{Keys: bson.D{{Key: AUDIT_RESOURCE_FIELD, Value: 1}, {Key: AUDIT_KEY_FIELD, Value: 1}, {Key: ID_FIELD, Value: 1}}},
+		{Keys: bson.D{{Key: AUDIT_TIMESTAMP_FIELD, Value: 1}}},
+	}
+	_, err := a.coll.Indexes().CreateMany(ctx, indexModels)
+	return err
+}
+
+func (a *auditLog) addRecord(ctx context.Context, record AuditRecord) error {
+	_, err := a.coll.InsertOne(ctx, record)
+	return err
+}
+
+// filters of audit log query, zero values do not filter
+type AuditQuery struct {
+	ListQuery
+	Resource string
+	Key      string
+	Actor    string
+	Since    time.Time
+	Until    time.Time
+}
+
+func (a *auditLog) findRecords(ctx context.Context, query AuditQuery) (Page[AuditRecord], error) {
+	filter := bson.D{}
+	if query.Resource != "" {
+		filter = append(filter, bson.E{Key: AUDIT_RESOURCE_FIELD, Value: query.Resource})
+	}
+	if query.Key != "" {
+		filter = append(filter, bson.E{Key: AUDIT_KEY_FIELD, Value: query.Key})
+	}
+	if query.Prefix != "" {
+		filter = append(filter, prefixFilter(AUDIT_KEY_FIELD, query.Prefix))
+	}
+	if query.Actor != "" {
+		filter = append(filter, bson.E{Key: AUDIT_ACTOR_FIELD, Value: query.Actor})
+	}
+
+	timeRange := bson.D{}
+	if !query.Since.IsZero() {
+		timeRange = append(timeRange, bson.E{Key: "$gte", Value: query.Since})
+	}
+	if !query.Until.IsZero() {
+		timeRange = append(timeRange, bson.E{Key: "$lt", Value: query.Until})
+	}
+	if len(timeRange) != 0 {
+		filter = append(filter, bson.E{Key: AUDIT_TIMESTAMP_FIELD, Value: timeRange})
+	}
+
+	sortFields := []string{AUDIT_TIMESTAMP_FIELD}
+	return findPage[AuditRecord](ctx, a.coll, filter, query.ListQuery, sortFields, options.Find())
+}
+
+// resource state stored in audit record
+type AuditState = bson.Raw
+
+// stored document of snapshot without _id, with extra fields appended
+// in name order; nil if document did not exist
+func NewAuditState(snapshot Snapshot, extra map[string]interface{}) (AuditState, error) {
+	if !snapshot.Exists() {
+		return nil, nil
+	}
+
+	var doc bson.D
+	if err := snapshot.Decode(&doc); err != nil {
+		return nil, err
+	}
+	state := make(bson.D, 0, len(doc)+len(extra))
+	for _, elem := range doc {
+		if elem.Key != ID_FIELD {
+			state = append(state, elem)
+		}
+	}
+
+	names := make([]string, 0, len(extra))
+	for name := range extra {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		state = append(state, bson.E{Key: name, Value: extra[name]})
+	}
+
+	return bson.Marshal(state)
+}
+
+// relaxed extended json of audit state, null if state is missing
+func AuditStateJSON(state AuditState) (json.RawMessage, error) {
+	if state == nil {
+		return nil, nil
+	}
+	return bson.MarshalExtJSON(state, false, false)
+}
@@ -2,42 +2,169 @@ package database
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
 	"mock-server/internal/configs"
+	"os"
+	"time"
 
 	mim "github.com/ONSdigital/dp-mongodb-in-memory"
+	zlog "github.com/rs/zerolog/log"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
 )
 
-var inMemoryServer mim.Server
-
-func initInMemoryDB(ctx context.Context, cfg *configs.DatabaseConfig) error {
-	inMemoryServer, err := mim.Start(ctx, "5.0.2")
+func initInMemoryDB(ctx context.Context, cfg *configs.DatabaseConfig) (Storage, error) {
+	server, err := mim.Start(ctx, "5.0.2")
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	client, err := mongo.Connect(ctx, options.Client().ApplyURI(inMemoryServer.URI()))
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(server.URI()))
 	if err != nil {
-		return err
+		server.Stop(ctx)
+		return nil, err
 	}
 
-	return db.init(ctx, client, cfg)
+	storage := &MongoStorage{embedded: server}
+	if err := storage.init(ctx, client, cfg); err != nil {
+		storage.Disconnect(ctx)
+		return nil, err
+	}
+	return storage, nil
 }
 
-func InitDB(ctx context.Context, cfg *configs.DatabaseConfig) error {
-	if cfg.InMemory {
-		return initInMemoryDB(ctx, cfg)
-	} else {
-		panic("Not implemented")
+// of connecting to external deployment and of the startup ping
+const DEFAULT_CONNECT_TIMEOUT = 10 * time.Second
+
+func databaseName(cfg *configs.DatabaseConfig) string {
+	if cfg.Name == "" {
+		return DEFAULT_DATABASE_NAME
 	}
+	return cfg.Name
 }
 
-func Disconnect(ctx context.Context) error {
-	err := db.client.Disconnect(ctx)
+func databaseTLSConfig(cfg *configs.DatabaseTLSConfig) (*tls.Config, error) {
+	tlsCfg := &tls.Config{InsecureSkipVerify: cfg.Insecure}
+
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in %s", cfg.CAFile)
+		}
+		tlsCfg.RootCAs = pool
+	}
+
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsCfg, nil
+}
+
+func externalClientOptions(cfg *configs.DatabaseConfig) (*options.ClientOptions, error) {
+	timeout := cfg.ConnectTimeout
+	if timeout == 0 {
+		timeout = DEFAULT_CONNECT_TIMEOUT
+	}
+
+	opts := options.Client().
+		ApplyURI(cfg.URI).
+		SetConnectTimeout(timeout).
+		SetServerSelectionTimeout(timeout)
+
+	// keeps auth mechanism and other credential options of the uri
+	if cfg.Username != "" || cfg.Password != "" || cfg.AuthSource != "" {
+		var credential options.Credential
+		if opts.Auth != nil {
+			credential = *opts.Auth
+		}
+		if cfg.Username != "" {
+			credential.Username = cfg.Username
+		}
+		if cfg.Password != "" {
+			credential.Password = cfg.Password
+			credential.PasswordSet = true
+		}
+		if cfg.AuthSource != "" {
+			credential.AuthSource = cfg.AuthSource
+		}
+		opts.SetAuth(credential)
+	}
+
+	if cfg.TLS != nil {
+		tlsCfg, err := databaseTLSConfig(cfg.TLS)
+		if err != nil {
+			return nil, fmt.Errorf("database tls: %w", err)
+		}
+		opts.SetTLSConfig(tlsCfg)
+	}
+
+	return opts, opts.Validate()
+}
+
+func initExternalDB(ctx context.Context, cfg *configs.DatabaseConfig) (Storage, error) {
+	if cfg.URI == "" {
+		return nil, errors.New("database uri is required if inmemory is false")
+	}
+
+	opts, err := externalClientOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := mongo.Connect(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// connect does not wait for deployment, so unreachable one fails startup here
+	pingCtx, cancel := context.WithTimeout(ctx, *opts.ServerSelectionTimeout)
+	defer cancel()
+	if err := client.Ping(pingCtx, readpref.Primary()); err != nil {
+		client.Disconnect(ctx)
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	zlog.Info().Str("database", databaseName(cfg)).Strs("hosts", opts.Hosts).Msg("Connected to database")
+	storage := &MongoStorage{}
+	if err := storage.init(ctx, client, cfg); err != nil {
+		client.Disconnect(ctx)
+		return nil, err
+	}
+	return storage, nil
+}
+
+func InitDB(ctx context.Context, cfg *configs.DatabaseConfig) error {
+	storage, err := NewStorage(ctx, cfg)
 	if err != nil {
 		return err
 	}
-	inMemoryServer.Stop(ctx)
+	UseStorage(storage)
 	return nil
 }
+
+// sets storage of process, used by operations without storage in context
+func UseStorage(storage Storage) {
+	db = bindStorage(storage)
+}
+
+// pings storage of request
+func Ping(ctx context.Context) error {
+	return dbOf(ctx).Ping(ctx)
+}
+
+func Disconnect(ctx context.Context) error {
+	return db.Disconnect(ctx)
+}
@@ -7,3 +7,7 @@ var ErrDuplicateKey = errors.New("duplicate key")
 var ErrNoSuchRecord = errors.New("no such record")
 var ErrNoSuchPool = errors.New("no such pool")
 var ErrBadRouteType = errors.New("bad route type")
+var ErrNoSuchNamespace = errors.New("no such namespace")
+var ErrInvalidCursor = errors.New("invalid page cursor")
+var ErrInvalidSort = errors.New("unsupported sort field")
+var ErrNoSuchRevision = errors.New("no such revision")
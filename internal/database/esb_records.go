@@ -26,7 +26,7 @@ func createESBRecords(ctx context.Context, client *mongo.Client, cfg *configs.Da
 }
 
 func (esb *esbRecords) init(ctx context.Context, client *mongo.Client, cfg *configs.DatabaseConfig) error {
-	esb.coll = client.Database(DATABASE_NAME).Collection(ESB_RECORDS_COLLECTION)
+	esb.coll = client.Database(databaseName(cfg)).Collection(ESB_RECORDS_COLLECTION)
 	esb.cache = gcache.New(cfg.CacheSize).Simple().LoaderFunc(func(poolNameIn interface{}) (interface{}, error) {
 		var res ESBRecord
 		err := esb.coll.FindOne(
@@ -77,6 +77,31 @@ func (esb *esbRecords) removeESBRecord(ctx context.Context, poolNameIn string) e
 	})
 }
 
+// fields replaced by update of esb record, both storages update records with these fields
+func esbRecordUpdateFields(esbRecord *ESBRecord) bson.D {
+	return bson.D{
+		{Key: POOL_NAME_OUT_FIELD, Value: esbRecord.PoolNameOut},
+		{Key: MAPPER_SCRIPT_NAME_FIELD, Value: esbRecord.MapperScriptName},
+	}
+}
+
+func (esb *esbRecords) updateESBRecord(ctx context.Context, esbRecord ESBRecord) error {
+	return util.RunWithWriteLock(&esb.mutex, func() error {
+		res, err := esb.coll.UpdateOne(
+			ctx,
+			bson.D{primitive.E{Key: POOL_NAME_IN_FIELD, Value: esbRecord.PoolNameIn}},
+			bson.D{{Key: "$set", Value: esbRecordUpdateFields(&esbRecord)}},
+		)
+		if err != nil {
+			return err
+		}
+		if res.MatchedCount == 0 {
+			return ErrNoSuchRecord
+		}
+		return esb.cache.Set(esbRecord.PoolNameIn, esbRecord)
+	})
+}
+
 func (esb *esbRecords) getESBRecord(ctx context.Context, poolNameIn string) (ESBRecord, error) {
 	return util.RunWithReadLock(&esb.mutex, func() (ESBRecord, error) {
 		res, err := esb.cache.Get(poolNameIn)
@@ -104,3 +129,18 @@ func (esb *esbRecords) listESBRecords(ctx context.Context) ([]ESBRecord, error)
 		return results, nil
 	})
 }
+
+func (esb *esbRecords) findESBRecords(ctx context.Context, query ListQuery) (Page[ESBRecord], error) {
+	return util.RunWithReadLock(&esb.mutex, func() (Page[ESBRecord], error) {
+		filter := bson.D{}
+		if query.Prefix != "" {
+			filter = append(filter, prefixFilter(POOL_NAME_IN_FIELD, query.Prefix))
+		}
+		if query.Search != "" {
+			filter = append(filter, searchFilter(query.Search, POOL_NAME_IN_FIELD, POOL_NAME_OUT_FIELD))
+		}
+
+		sortFields := []string{POOL_NAME_IN_FIELD, POOL_NAME_OUT_FIELD}
+		return findPage[ESBRecord](ctx, esb.coll, filter, query, sortFields, options.Find())
+	})
+}
@@ -0,0 +1,440 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// state in process memory, lost when storage is dropped;
+// collections behave like mongo ones, but transactions are not supported
+type MemoryStorage struct {
+	routes       *memoryRoutes
+	taskMessages *memoryTaskMessages
+	esbRecords   *memoryESBRecords
+	messagePools *memoryMessagePools
+	namespaces   *memoryNamespaces
+	auditLog     *memoryAuditLog
+	revisions    *memoryRevisions
+}
+
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{
+		routes: &memoryRoutes{
+			docs: newMemoryCollection(func(r *Route) string { return r.Path }),
+		},
+		taskMessages: &memoryTaskMessages{
+			docs: newMemoryCollection[TaskMessage](nil),
+		},
+		esbRecords: &memoryESBRecords{
+			docs: newMemoryCollection(func(r *ESBRecord) string { return r.PoolNameIn }),
+		},
+		messagePools: &memoryMessagePools{
+			docs: newMemoryCollection(func(p *MessagePool) string { return p.Name }),
+		},
+		namespaces: &memoryNamespaces{
+			docs: newMemoryCollection(func(ns *Namespace) string { return ns.Prefix }),
+		},
+		auditLog: &memoryAuditLog{
+			docs: newMemoryCollection[AuditRecord](nil),
+		},
+		revisions: &memoryRevisions{
+			docs: newMemoryCollection[Revision](nil),
+		},
+	}
+}
+
+func (m *MemoryStorage) collections() collections {
+	return collections{
+		routes:       m.routes,
+		taskMessages: m.taskMessages,
+		esbRecords:   m.esbRecords,
+		messagePools: m.messagePools,
+		namespaces:   m.namespaces,
+		auditLog:     m.auditLog,
+		revisions:    m.revisions,
+	}
+}
+
+func (m *MemoryStorage) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (m *MemoryStorage) Disconnect(ctx context.Context) error {
+	return nil
+}
+
+func (m *MemoryStorage) TransactionsSupported() bool {
+	return false
+}
+
+// just runs fn, changes of failed batch are undone by restoring snapshots
+func (m *MemoryStorage) RunInTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// ids of memory documents grow in creation order, unlike counter
+// of generated ObjectID which starts at random value and may wrap
+var memoryIds struct {
+	sync.Mutex
+	timestamp uint32
+	counter   uint64
+}
+
+func newMemoryId() primitive.ObjectID {
+	memoryIds.Lock()
+	defer memoryIds.Unlock()
+
+	timestamp := uint32(time.Now().Unix())
+	if timestamp < memoryIds.timestamp {
+		timestamp = memoryIds.timestamp
+	}
+	memoryIds.timestamp = timestamp
+	memoryIds.counter++
+
+	var id primitive.ObjectID
+	binary.BigEndian.PutUint32(id[0:4], timestamp)
+	binary.BigEndian.PutUint64(id[4:12], memoryIds.counter)
+	return id
+}
+
+// stored document, marshalled the way mongo stores it
+type memoryDoc[T any] struct {
+	Id    primitive.ObjectID `bson:"_id"`
+	Value T                  `bson:",inline"`
+}
+
+// documents in insertion order, which is the order of ids;
+// collections are not safe for concurrent use, stores lock them
+type memoryCollection[T any] struct {
+	// unique key of document, nil if collection has no unique key
+	key  func(*T) string
+	docs []memoryDoc[T]
+}
+
+func newMemoryCollection[T any](key func(*T) string) *memoryCollection[T] {
+	return &memoryCollection[T]{key: key}
+}
+
+func (c *memoryCollection[T]) index(key string) int {
+	for i := range c.docs {
+		if c.key(&c.docs[i].Value) == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// stored value, changes of it are stored as well
+func (c *memoryCollection[T]) get(key string) (*T, bool) {
+	i := c.index(key)
+	if i == -1 {
+		return nil, false
+	}
+	return &c.docs[i].Value, true
+}
+
+func (c *memoryCollection[T]) insert(id primitive.ObjectID, value T) {
+	c.put(memoryDoc[T]{Id: id, Value: value})
+}
+
+// document is placed by id, which is the last one unless document is restored
+func (c *memoryCollection[T]) put(doc memoryDoc[T]) {
+	i := sort.Search(len(c.docs), func(i int) bool {
+		return bytes.Compare(c.docs[i].Id[:], doc.Id[:]) > 0
+	})
+	c.docs = append(c.docs, memoryDoc[T]{})
+	copy(c.docs[i+1:], c.docs[i:])
+	c.docs[i] = doc
+}
+
+func (c *memoryCollection[T]) remove(key string) bool {
+	i := c.index(key)
+	if i == -1 {
+		return false
+	}
+	c.docs = append(c.docs[:i], c.docs[i+1:]...)
+	return true
+}
+
+// replaces value of document keeping its id, inserts document if there is none
+func (c *memoryCollection[T]) replace(key string, value T) {
+	if i := c.index(key); i != -1 {
+		c.docs[i].Value = value
+		return
+	}
+	c.insert(newMemoryId(), value)
+}
+
+func (c *memoryCollection[T]) filter(match func(*T) bool) []memoryDoc[T] {
+	var docs []memoryDoc[T]
+	for i := range c.docs {
+		if match(&c.docs[i].Value) {
+			docs = append(docs, c.docs[i])
+		}
+	}
+	return docs
+}
+
+func (c *memoryCollection[T]) values() []T {
+	values := make([]T, len(c.docs))
+	for i := range c.docs {
+		values[i] = c.docs[i].Value
+	}
+	return values
+}
+
+func (c *memoryCollection[T]) snapshot(key string) (Snapshot, error) {
+	i := c.index(key)
+	if i == -1 {
+		return Snapshot{}, nil
+	}
+	raw, err := bson.Marshal(c.docs[i])
+	if err != nil {
+		return Snapshot{}, err
+	}
+	return Snapshot{raw: raw}, nil
+}
+
+func (c *memoryCollection[T]) restore(key string, snapshot Snapshot) error {
+	if !snapshot.Exists() {
+		c.remove(key)
+		return nil
+	}
+
+	var doc memoryDoc[T]
+	if err := snapshot.Decode(&doc); err != nil {
+		return err
+	}
+	c.remove(key)
+	c.put(doc)
+	return nil
+}
+
+// fields are set the way mongo sets them, by bson names;
+// fields with nil value are removed
+func setFields[T any](value T, fields bson.D) (T, error) {
+	var result T
+	raw, err := bson.Marshal(value)
+	if err != nil {
+		return result, err
+	}
+	var doc bson.D
+	if err := bson.Unmarshal(raw, &doc); err != nil {
+		return result, err
+	}
+
+	set := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		set[field.Key] = true
+	}
+	updated := make(bson.D, 0, len(doc)+len(fields))
+	for _, elem := range doc {
+		if !set[elem.Key] {
+			updated = append(updated, elem)
+		}
+	}
+	for _, field := range fields {
+		if !isNilValue(field.Value) {
+			updated = append(updated, field)
+		}
+	}
+
+	if raw, err = bson.Marshal(updated); err != nil {
+		return result, err
+	}
+	err = bson.Unmarshal(raw, &result)
+	return result, err
+}
+
+func hasPrefix(value string, prefix string) bool {
+	return prefix == "" || strings.HasPrefix(value, prefix)
+}
+
+// case insensitive substring in any of values, like searchFilter
+func matchesSearch(search string, values ...string) bool {
+	if search == "" {
+		return true
+	}
+	search = strings.ToLower(search)
+	for _, value := range values {
+		if strings.Contains(strings.ToLower(value), search) {
+			return true
+		}
+	}
+	return false
+}
+
+// keyset pagination of documents like findPage, documents
+// are filtered already and are in insertion order
+func findMemoryPage[T any](docs []memoryDoc[T], query ListQuery, sortFields []string) (Page[T], error) {
+	page := Page[T]{Items: make([]T, 0)}
+
+	sortBy := query.SortBy
+	if sortBy == "" {
+		sortBy = ID_FIELD
+	}
+	if sortBy != ID_FIELD && !contains(sortFields, sortBy) {
+		return page, ErrInvalidSort
+	}
+
+	limit := query.Limit
+	if limit > MAX_PAGE_LIMIT {
+		limit = MAX_PAGE_LIMIT
+	}
+
+	type positioned struct {
+		value    T
+		position pageCursor
+	}
+	items := make([]positioned, len(docs))
+	for i, doc := range docs {
+		items[i].value = doc.Value
+		items[i].position.Id = doc.Id
+		if sortBy == ID_FIELD {
+			items[i].position.Value = bson.RawValue{Type: bsontype.ObjectID, Value: items[i].position.Id[:]}
+			continue
+		}
+
+		raw, err := bson.Marshal(doc.Value)
+		if err != nil {
+			return page, err
+		}
+		items[i].position.Value = bson.Raw(raw).Lookup(sortBy)
+		if items[i].position.Value.Type == 0 {
+			items[i].position.Value = bson.RawValue{Type: bsontype.Null}
+		}
+	}
+
+	before := func(a pageCursor, b pageCursor) bool {
+		if query.Desc {
+			return comparePositions(a, b) > 0
+		}
+		return comparePositions(a, b) < 0
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return before(items[i].position, items[j].position)
+	})
+
+	if query.Cursor != "" {
+		cursor, err := decodeCursor(query.Cursor)
+		if err != nil {
+			return page, err
+		}
+		start := sort.Search(len(items), func(i int) bool {
+			return before(cursor, items[i].position)
+		})
+		items = items[start:]
+	}
+
+	for i, item := range items {
+		if limit > 0 && int64(i) == limit {
+			next, err := encodeCursor(items[i-1].position)
+			if err != nil {
+				return page, err
+			}
+			page.NextCursor = next
+			break
+		}
+		page.Items = append(page.Items, item.value)
+	}
+	return page, nil
+}
+
+// order of sort values, _id breaks ties
+func comparePositions(a pageCursor, b pageCursor) int {
+	if c := compareValues(a.Value, b.Value); c != 0 {
+		return c
+	}
+	return bytes.Compare(a.Id[:], b.Id[:])
+}
+
+// mongo orders values of different types by type: null first,
+// then numbers, strings, documents, arrays, binary data, ids,
+// booleans and dates
+func typeOrder(t bsontype.Type) int {
+	switch t {
+	case bsontype.Null, bsontype.Undefined:
+		return 1
+	case bsontype.Double, bsontype.Int32, bsontype.Int64, bsontype.Decimal128:
+		return 2
+	case bsontype.String, bsontype.Symbol:
+		return 3
+	case bsontype.EmbeddedDocument:
+		return 4
+	case bsontype.Array:
+		return 5
+	case bsontype.Binary:
+		return 6
+	case bsontype.ObjectID:
+		return 7
+	case bsontype.Boolean:
+		return 8
+	case bsontype.DateTime:
+		return 9
+	case bsontype.Timestamp:
+		return 10
+	}
+	return 11
+}
+
+func compareValues(a bson.RawValue, b bson.RawValue) int {
+	if ta, tb := typeOrder(a.Type), typeOrder(b.Type); ta != tb {
+		if ta < tb {
+			return -1
+		}
+		return 1
+	}
+
+	switch a.Type {
+	case bsontype.Null, bsontype.Undefined:
+		return 0
+	case bsontype.Double, bsontype.Int32, bsontype.Int64:
+		if a.Type != bsontype.Double && b.Type != bsontype.Double {
+			return compareOrdered(a.AsInt64(), b.AsInt64())
+		}
+		return compareOrdered(numberValue(a), numberValue(b))
+	case bsontype.String:
+		return strings.Compare(a.StringValue(), b.StringValue())
+	case bsontype.Boolean:
+		return compareOrdered(boolValue(a.Boolean()), boolValue(b.Boolean()))
+	case bsontype.DateTime:
+		return compareOrdered(a.DateTime(), b.DateTime())
+	}
+	return bytes.Compare(a.Value, b.Value)
+}
+
+func numberValue(v bson.RawValue) float64 {
+	switch v.Type {
+	case bsontype.Int32:
+		return float64(v.Int32())
+	case bsontype.Int64:
+		return float64(v.Int64())
+	}
+	return v.Double()
+}
+
+func boolValue(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func compareOrdered[T int | int64 | float64](a T, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
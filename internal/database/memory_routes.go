@@ -0,0 +1,205 @@
+package database
+
+import (
+	"context"
+	"mock-server/internal/util"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// expired routes are invisible like in mongo, they are dropped
+// when path is taken by new route or scripts are listed
+type memoryRoutes struct {
+	docs  *memoryCollection[Route]
+	mutex sync.RWMutex
+}
+
+func (r *memoryRoutes) addRoute(ctx context.Context, route Route) error {
+	now := time.Now()
+	route.CreatedAt = &now
+	route.UpdatedAt = &now
+
+	return util.RunWithWriteLock(&r.mutex, func() error {
+		if current, ok := r.docs.get(route.Path); ok {
+			// path may be occupied by expired route
+			if !current.IsExpired(time.Now()) {
+				return ErrDuplicateKey
+			}
+			r.docs.remove(route.Path)
+		}
+		r.docs.insert(newMemoryId(), route)
+		return nil
+	})
+}
+
+func (r *memoryRoutes) removeRoute(ctx context.Context, path string) error {
+	return util.RunWithWriteLock(&r.mutex, func() error {
+		r.docs.remove(path)
+		return nil
+	})
+}
+
+func (r *memoryRoutes) updateRoute(ctx context.Context, route Route) error {
+	return util.RunWithWriteLock(&r.mutex, func() error {
+		current, ok := r.docs.get(route.Path)
+		if !ok || current.Type != route.Type || current.IsExpired(time.Now()) {
+			return ErrNoSuchPath
+		}
+
+		updated, err := setFields(*current, routeUpdateFields(&route))
+		if err != nil {
+			return err
+		}
+		*current = updated
+		return nil
+	})
+}
+
+// nil value unsets the field
+func (r *memoryRoutes) setRouteField(ctx context.Context, path string, field string, value interface{}) error {
+	return util.RunWithWriteLock(&r.mutex, func() error {
+		current, ok := r.docs.get(path)
+		if !ok {
+			return ErrNoSuchPath
+		}
+
+		fields := bson.D{{Key: field, Value: value}, {Key: ROUTE_UPDATED_AT_FIELD, Value: time.Now()}}
+		updated, err := setFields(*current, fields)
+		if err != nil {
+			return err
+		}
+		*current = updated
+		return nil
+	})
+}
+
+func (r *memoryRoutes) getRoute(ctx context.Context, path string) (Route, error) {
+	return util.RunWithReadLock(&r.mutex, func() (Route, error) {
+		route, ok := r.docs.get(path)
+		if !ok || route.IsExpired(time.Now()) {
+			return Route{}, ErrNoSuchPath
+		}
+		return *route, nil
+	})
+}
+
+func (r *memoryRoutes) listAllRoutesPathsWithType(ctx context.Context, t string) ([]string, error) {
+	return util.RunWithReadLock(&r.mutex, func() ([]string, error) {
+		now := time.Now()
+		routes := r.docs.filter(func(route *Route) bool {
+			return route.Type == t && !route.IsExpired(now)
+		})
+		paths := make([]string, len(routes))
+		for i, route := range routes {
+			paths[i] = route.Value.Path
+		}
+		return paths, nil
+	})
+}
+
+// scripts of all stored routes, expired routes are removed first
+func (r *memoryRoutes) listScripts(ctx context.Context) ([]string, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := time.Now()
+	r.docs.docs = r.docs.filter(func(route *Route) bool {
+		return !route.IsExpired(now)
+	})
+
+	var scripts []string
+	for _, doc := range r.docs.docs {
+		if doc.Value.ScriptName != "" {
+			scripts = append(scripts, doc.Value.ScriptName)
+		}
+		for _, op := range doc.Value.Operations {
+			if op.ScriptName != "" {
+				scripts = append(scripts, op.ScriptName)
+			}
+		}
+	}
+	return scripts, nil
+}
+
+func (r *memoryRoutes) findRoutePaths(ctx context.Context, t string, query ListQuery) (Page[string], error) {
+	return util.RunWithReadLock(&r.mutex, func() (Page[string], error) {
+		now := time.Now()
+		docs := r.docs.filter(func(route *Route) bool {
+			return route.Type == t && !route.IsExpired(now) &&
+				hasPrefix(route.Path, query.Prefix) &&
+				matchesSearch(query.Search, route.Path)
+		})
+
+		routes, err := findMemoryPage(docs, query, []string{ROUTE_PATH_FIELD})
+		if err != nil {
+			return Page[string]{}, err
+		}
+
+		paths := Page[string]{Items: make([]string, len(routes.Items)), NextCursor: routes.NextCursor}
+		for i, route := range routes.Items {
+			paths.Items[i] = route.Path
+		}
+		return paths, nil
+	})
+}
+
+// not expired routes of all types, hits are counted as requests are served
+func (r *memoryRoutes) listRoutes(ctx context.Context, query ListQuery) (Page[Route], error) {
+	return util.RunWithReadLock(&r.mutex, func() (Page[Route], error) {
+		now := time.Now()
+		docs := r.docs.filter(func(route *Route) bool {
+			return !route.IsExpired(now) &&
+				(query.Type == "" || route.Type == query.Type) &&
+				hasPrefix(route.Path, query.Prefix) &&
+				matchesSearch(query.Search, route.Path, route.Response, route.ProxyURL)
+		})
+
+		sortFields := []string{ROUTE_PATH_FIELD, ROUTE_TYPE_FIELD, ROUTE_UPDATED_AT_FIELD, ROUTE_HITS_FIELD}
+		return findMemoryPage(docs, query, sortFields)
+	})
+}
+
+func (r *memoryRoutes) recordHit(path string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if route, ok := r.docs.get(path); ok {
+		route.Hits++
+	}
+}
+
+func (r *memoryRoutes) snapshot(ctx context.Context, path string) (Snapshot, error) {
+	return util.RunWithReadLock(&r.mutex, func() (Snapshot, error) {
+		return r.docs.snapshot(path)
+	})
+}
+
+func (r *memoryRoutes) restore(ctx context.Context, path string, snapshot Snapshot) error {
+	return util.RunWithWriteLock(&r.mutex, func() error {
+		return r.docs.restore(path, snapshot)
+	})
+}
+
+// creation time and hits of current route are kept
+func (r *memoryRoutes) rollback(ctx context.Context, path string, state AuditState) error {
+	var route Route
+	if err := bson.Unmarshal(state, &route); err != nil {
+		return err
+	}
+
+	return util.RunWithWriteLock(&r.mutex, func() error {
+		now := time.Now()
+		route.CreatedAt = &now
+		route.Hits = 0
+		if current, ok := r.docs.get(path); ok {
+			if current.CreatedAt != nil {
+				route.CreatedAt = current.CreatedAt
+			}
+			route.Hits = current.Hits
+		}
+		route.UpdatedAt = &now
+		r.docs.replace(path, route)
+		return nil
+	})
+}
@@ -0,0 +1,318 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"mock-server/internal/util"
+	"sort"
+	"sync"
+)
+
+type memoryTaskMessages struct {
+	docs  *memoryCollection[TaskMessage]
+	mutex sync.RWMutex
+}
+
+func (s *memoryTaskMessages) addTaskMessage(ctx context.Context, taskMessage TaskMessage) error {
+	return util.RunWithWriteLock(&s.mutex, func() error {
+		s.docs.insert(newMemoryId(), taskMessage)
+		return nil
+	})
+}
+
+func (s *memoryTaskMessages) getTaskMessages(ctx context.Context, taskId string) ([]string, error) {
+	return util.RunWithReadLock(&s.mutex, func() ([]string, error) {
+		taskMessages := s.docs.filter(func(m *TaskMessage) bool {
+			return m.TaskId == taskId
+		})
+		messages := make([]string, len(taskMessages))
+		for i, taskMessage := range taskMessages {
+			messages[i] = taskMessage.Value.Message
+		}
+		return messages, nil
+	})
+}
+
+// messages of task in the order they were stored, unless other sort is requested
+func (s *memoryTaskMessages) findTaskMessages(ctx context.Context, taskId string, query ListQuery) (Page[string], error) {
+	return util.RunWithReadLock(&s.mutex, func() (Page[string], error) {
+		docs := s.docs.filter(func(m *TaskMessage) bool {
+			return m.TaskId == taskId && matchesSearch(query.Search, m.Message)
+		})
+		taskMessages, err := findMemoryPage(docs, query, []string{MESSAGE_FIELD})
+		if err != nil {
+			return Page[string]{}, err
+		}
+
+		messages := Page[string]{Items: make([]string, len(taskMessages.Items)), NextCursor: taskMessages.NextCursor}
+		for i, taskMessage := range taskMessages.Items {
+			messages.Items[i] = taskMessage.Message
+		}
+		return messages, nil
+	})
+}
+
+type memoryESBRecords struct {
+	docs  *memoryCollection[ESBRecord]
+	mutex sync.RWMutex
+}
+
+func (esb *memoryESBRecords) addESBRecord(ctx context.Context, esbRecord ESBRecord) error {
+	return util.RunWithWriteLock(&esb.mutex, func() error {
+		if _, ok := esb.docs.get(esbRecord.PoolNameIn); ok {
+			return ErrDuplicateKey
+		}
+		esb.docs.insert(newMemoryId(), esbRecord)
+		return nil
+	})
+}
+
+func (esb *memoryESBRecords) removeESBRecord(ctx context.Context, poolNameIn string) error {
+	return util.RunWithWriteLock(&esb.mutex, func() error {
+		esb.docs.remove(poolNameIn)
+		return nil
+	})
+}
+
+func (esb *memoryESBRecords) updateESBRecord(ctx context.Context, esbRecord ESBRecord) error {
+	return util.RunWithWriteLock(&esb.mutex, func() error {
+		current, ok := esb.docs.get(esbRecord.PoolNameIn)
+		if !ok {
+			return ErrNoSuchRecord
+		}
+
+		updated, err := setFields(*current, esbRecordUpdateFields(&esbRecord))
+		if err != nil {
+			return err
+		}
+		*current = updated
+		return nil
+	})
+}
+
+func (esb *memoryESBRecords) getESBRecord(ctx context.Context, poolNameIn string) (ESBRecord, error) {
+	return util.RunWithReadLock(&esb.mutex, func() (ESBRecord, error) {
+		record, ok := esb.docs.get(poolNameIn)
+		if !ok {
+			return ESBRecord{}, ErrNoSuchRecord
+		}
+		return *record, nil
+	})
+}
+
+func (esb *memoryESBRecords) listESBRecords(ctx context.Context) ([]ESBRecord, error) {
+	return util.RunWithReadLock(&esb.mutex, func() ([]ESBRecord, error) {
+		return esb.docs.values(), nil
+	})
+}
+
+func (esb *memoryESBRecords) findESBRecords(ctx context.Context, query ListQuery) (Page[ESBRecord], error) {
+	return util.RunWithReadLock(&esb.mutex, func() (Page[ESBRecord], error) {
+		docs := esb.docs.filter(func(record *ESBRecord) bool {
+			return hasPrefix(record.PoolNameIn, query.Prefix) &&
+				matchesSearch(query.Search, record.PoolNameIn, record.PoolNameOut)
+		})
+		sortFields := []string{POOL_NAME_IN_FIELD, POOL_NAME_OUT_FIELD}
+		return findMemoryPage(docs, query, sortFields)
+	})
+}
+
+func (esb *memoryESBRecords) snapshot(ctx context.Context, poolNameIn string) (Snapshot, error) {
+	return util.RunWithReadLock(&esb.mutex, func() (Snapshot, error) {
+		return esb.docs.snapshot(poolNameIn)
+	})
+}
+
+func (esb *memoryESBRecords) restore(ctx context.Context, poolNameIn string, snapshot Snapshot) error {
+	return util.RunWithWriteLock(&esb.mutex, func() error {
+		return esb.docs.restore(poolNameIn, snapshot)
+	})
+}
+
+type memoryMessagePools struct {
+	docs  *memoryCollection[MessagePool]
+	mutex sync.RWMutex
+}
+
+func (mp *memoryMessagePools) addMessagePool(ctx context.Context, messagePool MessagePool) error {
+	return util.RunWithWriteLock(&mp.mutex, func() error {
+		if _, ok := mp.docs.get(messagePool.Name); ok {
+			return ErrDuplicateKey
+		}
+		mp.docs.insert(newMemoryId(), messagePool)
+		return nil
+	})
+}
+
+func (mp *memoryMessagePools) removeMessagePool(ctx context.Context, name string) error {
+	return util.RunWithWriteLock(&mp.mutex, func() error {
+		mp.docs.remove(name)
+		return nil
+	})
+}
+
+func (mp *memoryMessagePools) getMessagePool(ctx context.Context, name string) (MessagePool, error) {
+	return util.RunWithReadLock(&mp.mutex, func() (MessagePool, error) {
+		pool, ok := mp.docs.get(name)
+		if !ok {
+			return MessagePool{}, ErrNoSuchPool
+		}
+		return *pool, nil
+	})
+}
+
+func (mp *memoryMessagePools) listMessagePools(ctx context.Context) ([]MessagePool, error) {
+	return util.RunWithReadLock(&mp.mutex, func() ([]MessagePool, error) {
+		return mp.docs.values(), nil
+	})
+}
+
+func (mp *memoryMessagePools) findMessagePools(ctx context.Context, query ListQuery) (Page[MessagePool], error) {
+	return util.RunWithReadLock(&mp.mutex, func() (Page[MessagePool], error) {
+		docs := mp.docs.filter(func(pool *MessagePool) bool {
+			return hasPrefix(pool.Name, query.Prefix) &&
+				(query.Broker == "" || pool.Broker == query.Broker) &&
+				matchesSearch(query.Search, pool.Name, pool.Queue)
+		})
+		sortFields := []string{MESSAGE_POOL_NAME, MESSAGE_POOL_BROKER}
+		return findMemoryPage(docs, query, sortFields)
+	})
+}
+
+func (mp *memoryMessagePools) snapshot(ctx context.Context, name string) (Snapshot, error) {
+	return util.RunWithReadLock(&mp.mutex, func() (Snapshot, error) {
+		return mp.docs.snapshot(name)
+	})
+}
+
+func (mp *memoryMessagePools) restore(ctx context.Context, name string, snapshot Snapshot) error {
+	return util.RunWithWriteLock(&mp.mutex, func() error {
+		return mp.docs.restore(name, snapshot)
+	})
+}
+
+type memoryNamespaces struct {
+	docs  *memoryCollection[Namespace]
+	mutex sync.RWMutex
+}
+
+func (ns *memoryNamespaces) addNamespace(ctx context.Context, namespace Namespace) error {
+	return util.RunWithWriteLock(&ns.mutex, func() error {
+		if _, ok := ns.docs.get(namespace.Prefix); ok {
+			return ErrDuplicateKey
+		}
+		ns.docs.insert(newMemoryId(), namespace)
+		return nil
+	})
+}
+
+func (ns *memoryNamespaces) removeNamespace(ctx context.Context, prefix string) error {
+	return util.RunWithWriteLock(&ns.mutex, func() error {
+		ns.docs.remove(prefix)
+		return nil
+	})
+}
+
+func (ns *memoryNamespaces) updateNamespace(ctx context.Context, namespace Namespace) error {
+	return util.RunWithWriteLock(&ns.mutex, func() error {
+		current, ok := ns.docs.get(namespace.Prefix)
+		if !ok {
+			return ErrNoSuchNamespace
+		}
+		*current = namespace
+		return nil
+	})
+}
+
+func (ns *memoryNamespaces) listNamespaces(ctx context.Context) ([]Namespace, error) {
+	return util.RunWithReadLock(&ns.mutex, func() ([]Namespace, error) {
+		all := ns.docs.values()
+		sort.Slice(all, func(i, j int) bool {
+			return all[i].Prefix < all[j].Prefix
+		})
+		return all, nil
+	})
+}
+
+func (ns *memoryNamespaces) snapshot(ctx context.Context, prefix string) (Snapshot, error) {
+	return util.RunWithReadLock(&ns.mutex, func() (Snapshot, error) {
+		return ns.docs.snapshot(prefix)
+	})
+}
+
+// audit log is append only, records are never updated
+type memoryAuditLog struct {
+	docs  *memoryCollection[AuditRecord]
+	mutex sync.RWMutex
+}
+
+func (a *memoryAuditLog) addRecord(ctx context.Context, record AuditRecord) error {
+	return util.RunWithWriteLock(&a.mutex, func() error {
+		record.Id = newMemoryId()
+		a.docs.insert(record.Id, record)
+		return nil
+	})
+}
+
+func (a *memoryAuditLog) findRecords(ctx context.Context, query AuditQuery) (Page[AuditRecord], error) {
+	return util.RunWithReadLock(&a.mutex, func() (Page[AuditRecord], error) {
+		docs := a.docs.filter(func(record *AuditRecord) bool {
+			return (query.Resource == "" || record.Resource == query.Resource) &&
+				(query.Key == "" || record.Key == query.Key) &&
+				hasPrefix(record.Key, query.Prefix) &&
+				(query.Actor == "" || record.Actor == query.Actor) &&
+				(query.Since.IsZero() || !record.Timestamp.Before(query.Since)) &&
+				(query.Until.IsZero() || record.Timestamp.Before(query.Until))
+		})
+		sortFields := []string{AUDIT_TIMESTAMP_FIELD}
+		return findMemoryPage(docs, query.ListQuery, sortFields)
+	})
+}
+
+// numbered definitions of routes and esb records, kept after resource is removed
+type memoryRevisions struct {
+	docs  *memoryCollection[Revision]
+	mutex sync.RWMutex
+}
+
+func (r *memoryRevisions) ofResource(resource string, key string) []memoryDoc[Revision] {
+	return r.docs.filter(func(revision *Revision) bool {
+		return revision.Resource == resource && revision.Key == key
+	})
+}
+
+// stores revision with next number, unless definition equals the latest revision
+func (r *memoryRevisions) addRevision(ctx context.Context, revision Revision) (Revision, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	revision.Number = 1
+	// revisions of resource are stored in number order
+	if docs := r.ofResource(revision.Resource, revision.Key); len(docs) != 0 {
+		latest := docs[len(docs)-1].Value
+		if latest.Code == revision.Code && bytes.Equal(latest.State, revision.State) {
+			return latest, nil
+		}
+		revision.Number = latest.Number + 1
+	}
+	r.docs.insert(newMemoryId(), revision)
+	return revision, nil
+}
+
+func (r *memoryRevisions) getRevision(ctx context.Context, resource string, key string, number int64) (Revision, error) {
+	return util.RunWithReadLock(&r.mutex, func() (Revision, error) {
+		for _, doc := range r.ofResource(resource, key) {
+			if doc.Value.Number == number {
+				return doc.Value, nil
+			}
+		}
+		return Revision{}, ErrNoSuchRevision
+	})
+}
+
+func (r *memoryRevisions) findRevisions(ctx context.Context, resource string, key string, query ListQuery) (Page[Revision], error) {
+	return util.RunWithReadLock(&r.mutex, func() (Page[Revision], error) {
+		sortFields := []string{REVISION_NUMBER_FIELD}
+		return findMemoryPage(r.ofResource(resource, key), query, sortFields)
+	})
+}
@@ -26,7 +26,7 @@ func createMessagePools(ctx context.Context, client *mongo.Client, cfg *configs.
 }
 
 func (mp *messagePools) init(ctx context.Context, client *mongo.Client, cfg *configs.DatabaseConfig) error {
-	mp.coll = client.Database(DATABASE_NAME).Collection(MESSAGE_POOLS_COLLECTION)
+	mp.coll = client.Database(databaseName(cfg)).Collection(MESSAGE_POOLS_COLLECTION)
 	mp.cache = gcache.New(cfg.CacheSize).Simple().LoaderFunc(func(poolName interface{}) (interface{}, error) {
 		var res MessagePool
 		err := mp.coll.FindOne(
@@ -104,3 +104,21 @@ func (mp *messagePools) listMessagePools(ctx context.Context) ([]MessagePool, er
 		return results, nil
 	})
 }
+
+func (mp *messagePools) findMessagePools(ctx context.Context, query ListQuery) (Page[MessagePool], error) {
+	return util.RunWithReadLock(&mp.mutex, func() (Page[MessagePool], error) {
+		filter := bson.D{}
+		if query.Prefix != "" {
+			filter = append(filter, prefixFilter(MESSAGE_POOL_NAME, query.Prefix))
+		}
+		if query.Broker != "" {
+			filter = append(filter, bson.E{Key: MESSAGE_POOL_BROKER, Value: query.Broker})
+		}
+		if query.Search != "" {
+			filter = append(filter, searchFilter(query.Search, MESSAGE_POOL_NAME, MESSAGE_POOL_QUEUE))
+		}
+
+		sortFields := []string{MESSAGE_POOL_NAME, MESSAGE_POOL_BROKER}
+		return findPage[MessagePool](ctx, mp.coll, filter, query, sortFields, options.Find())
+	})
+}
@@ -1,17 +1,60 @@
 package database
 
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
 // bson names
 const (
 	// routes
-	ROUTE_PATH_FIELD        = "path"
-	ROUTE_TYPE_FIELD        = "type"
-	ROUTE_RESPONSE_FIELD    = "response"
-	ROUTE_SCRIPT_NAME_FIELD = "script_name"
+	ROUTE_PATH_FIELD            = "path"
+	ROUTE_TYPE_FIELD            = "type"
+	ROUTE_RESPONSE_FIELD        = "response"
+	ROUTE_REPRESENTATIONS_FIELD = "representations"
+	ROUTE_PROXY_URL_FIELD       = "proxy_url"
+	ROUTE_SCRIPT_NAME_FIELD     = "script_name"
+	ROUTE_SCHEMA_FIELD          = "schema"
+	ROUTE_OPERATIONS_FIELD      = "operations"
+	ROUTE_ON_CONNECT_FIELD      = "on_connect"
+	ROUTE_REPLIES_FIELD         = "replies"
+	ROUTE_EVENTS_FIELD          = "events"
+	ROUTE_STREAM_MODE_FIELD     = "stream_mode"
+	ROUTE_LOOP_FIELD            = "loop"
+	ROUTE_CONTENT_TYPE_FIELD    = "content_type"
+	ROUTE_FILE_NAME_FIELD       = "file_name"
+	ROUTE_FILE_SIZE_FIELD       = "file_size"
+	ROUTE_CORS_FIELD            = "cors"
+	ROUTE_RATE_LIMIT_FIELD      = "rate_limit"
+	ROUTE_ACTIVE_FROM_FIELD     = "active_from"
+	ROUTE_ACTIVE_UNTIL_FIELD    = "active_until"
+	ROUTE_EXPIRE_AT_FIELD       = "expire_at"
+	ROUTE_CREATED_AT_FIELD      = "created_at"
+	ROUTE_UPDATED_AT_FIELD      = "updated_at"
+	ROUTE_HITS_FIELD            = "hits"
+
+	// namespaces
+	NAMESPACE_PREFIX_FIELD     = "prefix"
+	NAMESPACE_CORS_FIELD       = "cors"
+	NAMESPACE_RATE_LIMIT_FIELD = "rate_limit"
+
+	// rate limit keys
+	RATE_LIMIT_KEY_BY_IP     = "ip"
+	RATE_LIMIT_KEY_BY_HEADER = "header"
 
 	// endpoint types
-	STATIC_ENDPOINT_TYPE  = "static_endpoint"
-	PROXY_ENDPOINT_TYPE   = "proxy_endpoint"
-	DYNAMIC_ENDPOINT_TYPE = "dynamic_endpoint"
+	STATIC_ENDPOINT_TYPE    = "static_endpoint"
+	PROXY_ENDPOINT_TYPE     = "proxy_endpoint"
+	DYNAMIC_ENDPOINT_TYPE   = "dynamic_endpoint"
+	GRAPHQL_ENDPOINT_TYPE   = "graphql_endpoint"
+	WEBSOCKET_ENDPOINT_TYPE = "websocket_endpoint"
+	STREAM_ENDPOINT_TYPE    = "stream_endpoint"
+	FILE_ENDPOINT_TYPE      = "file_endpoint"
+
+	// stream modes
+	STREAM_MODE_SSE     = "sse"
+	STREAM_MODE_CHUNKED = "chunked"
 
 	// task messages
 	TASK_ID_FIELD = "task_id"
@@ -27,6 +70,29 @@ const (
 	MESSAGE_POOL_QUEUE  = "queue"
 	MESSAGE_POOL_BROKER = "broker"
 	MESSAGE_POOL_CONFIG = "config"
+
+	// audit log
+	AUDIT_TIMESTAMP_FIELD = "timestamp"
+	AUDIT_ACTOR_FIELD     = "actor"
+	AUDIT_RESOURCE_FIELD  = "resource"
+	AUDIT_KEY_FIELD       = "key"
+
+	// revisions
+	REVISION_RESOURCE_FIELD = "resource"
+	REVISION_KEY_FIELD      = "key"
+	REVISION_NUMBER_FIELD   = "revision"
+
+	// audited resources
+	AUDIT_RESOURCE_ROUTE     = "route"
+	AUDIT_RESOURCE_POOL      = "pool"
+	AUDIT_RESOURCE_ESB       = "esb"
+	AUDIT_RESOURCE_NAMESPACE = "namespace"
+
+	// audited operations, rollback is undo of failed batch operation
+	AUDIT_OP_CREATE   = "create"
+	AUDIT_OP_UPDATE   = "update"
+	AUDIT_OP_DELETE   = "delete"
+	AUDIT_OP_ROLLBACK = "rollback"
 )
 
 type Route struct {
@@ -34,7 +100,89 @@ type Route struct {
 	Type       string `bson:"type"`
 	ScriptName string `bson:"script_name,omitempty"`
 	Response   string `bson:"response,omitempty"`
-	ProxyURL   string `bson:"proxy_url"`
+	// static routes with representations ignore response
+	Representations []Representation   `bson:"representations,omitempty"`
+	ProxyURL        string             `bson:"proxy_url"`
+	Schema          string             `bson:"schema,omitempty"`
+	Operations      []GraphQLOperation `bson:"operations,omitempty"`
+	OnConnect       []string           `bson:"on_connect,omitempty"`
+	Replies         []WebSocketReply   `bson:"replies,omitempty"`
+	Events          []StreamEvent      `bson:"events,omitempty"`
+	StreamMode      string             `bson:"stream_mode,omitempty"`
+	Loop            bool               `bson:"loop,omitempty"`
+	ContentType     string             `bson:"content_type,omitempty"`
+	FileName        string             `bson:"file_name,omitempty"`
+	FileSize        int64              `bson:"file_size,omitempty"`
+	Cors            *CorsPolicy        `bson:"cors,omitempty"`
+	RateLimit       *RateLimitPolicy   `bson:"rate_limit,omitempty"`
+	CreatedAt       *time.Time         `bson:"created_at,omitempty"`
+	UpdatedAt       *time.Time         `bson:"updated_at,omitempty"`
+	// served requests, flushed periodically so may lag behind
+	Hits int64 `bson:"hits"`
+
+	ActivityWindow `bson:",inline"`
+}
+
+// route is served only inside the window, expired routes
+// are removed by mongo ttl monitor
+type ActivityWindow struct {
+	ActiveFrom  *time.Time `bson:"active_from,omitempty"`
+	ActiveUntil *time.Time `bson:"active_until,omitempty"`
+	ExpireAt    *time.Time `bson:"expire_at,omitempty"`
+}
+
+// variables and response are stored as raw json documents
+type GraphQLOperation struct {
+	OperationName string `bson:"operation_name"`
+	Variables     string `bson:"variables,omitempty"`
+	Response      string `bson:"response,omitempty"`
+	ScriptName    string `bson:"script_name,omitempty"`
+}
+
+type WebSocketReply struct {
+	Match    string `bson:"match"`
+	Regexp   bool   `bson:"regexp,omitempty"`
+	Response string `bson:"response"`
+}
+
+// body of static route served for requests accepting content type
+type Representation struct {
+	ContentType string `bson:"content_type"`
+	Body        string `bson:"body"`
+}
+
+// for chunked streams only data is written
+type StreamEvent struct {
+	Event   string `bson:"event,omitempty"`
+	Data    string `bson:"data"`
+	Id      string `bson:"id,omitempty"`
+	DelayMs int64  `bson:"delay_ms,omitempty"`
+}
+
+type CorsPolicy struct {
+	AllowOrigins     []string `bson:"allow_origins"`
+	AllowMethods     []string `bson:"allow_methods,omitempty"`
+	AllowHeaders     []string `bson:"allow_headers,omitempty"`
+	ExposeHeaders    []string `bson:"expose_headers,omitempty"`
+	AllowCredentials bool     `bson:"allow_credentials,omitempty"`
+	MaxAgeSec        int64    `bson:"max_age_sec,omitempty"`
+}
+
+// token bucket of burst size refilled by limit tokens per period
+type RateLimitPolicy struct {
+	Limit     int64  `bson:"limit"`
+	PeriodSec int64  `bson:"period_sec"`
+	Burst     int64  `bson:"burst,omitempty"`
+	KeyBy     string `bson:"key_by"`
+	Header    string `bson:"header,omitempty"`
+}
+
+// policies shared by all routes under the path prefix,
+// route own policies take precedence
+type Namespace struct {
+	Prefix    string           `bson:"prefix"`
+	Cors      *CorsPolicy      `bson:"cors,omitempty"`
+	RateLimit *RateLimitPolicy `bson:"rate_limit,omitempty"`
 }
 
 type TaskMessage struct {
@@ -54,3 +202,36 @@ type MessagePool struct {
 	Broker string `bson:"broker"`
 	Config []byte `bson:"config"`
 }
+
+// one admin change, states are stored documents of resource
+// (with script code if it has one), missing if resource did not exist
+type AuditRecord struct {
+	Id        primitive.ObjectID `bson:"_id,omitempty"`
+	Timestamp time.Time          `bson:"timestamp"`
+	// authenticated identity, empty if authentication is disabled
+	Actor     string     `bson:"actor,omitempty"`
+	IP        string     `bson:"ip"`
+	Method    string     `bson:"method"`
+	Path      string     `bson:"path"`
+	Operation string     `bson:"operation"`
+	Resource  string     `bson:"resource"`
+	Key       string     `bson:"key"`
+	Status    int        `bson:"status,omitempty"`
+	Before    AuditState `bson:"before,omitempty"`
+	After     AuditState `bson:"after,omitempty"`
+}
+
+// definition of route or esb record after change, code is
+// user code of dynamic handler or esb mapper
+type Revision struct {
+	Resource  string     `bson:"resource"`
+	Key       string     `bson:"key"`
+	Number    int64      `bson:"revision"`
+	Timestamp time.Time  `bson:"timestamp"`
+	Actor     string     `bson:"actor,omitempty"`
+	State     AuditState `bson:"state"`
+	Code      string     `bson:"code,omitempty"`
+	// files of graphql operations and websocket handler by script name,
+	// scripts are removed with definition they belong to
+	Scripts map[string]string `bson:"scripts,omitempty"`
+}
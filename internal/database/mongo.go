@@ -4,31 +4,50 @@ import (
 	"context"
 	"fmt"
 	"mock-server/internal/configs"
+	"mock-server/internal/tracing"
+	"time"
 
+	mim "github.com/ONSdigital/dp-mongodb-in-memory"
+	zlog "github.com/rs/zerolog/log"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
-	DATABASE_NAME            = "mongo_storage"
+	DEFAULT_DATABASE_NAME    = "mongo_storage"
 	ROUTES_COLLECTION        = "routes"
 	TASK_MESSAGES_COLLECTION = "task_messages"
 	ESB_RECORDS_COLLECTION   = "esb_records"
 	MESSAGE_POOLS_COLLECTION = "message_pools"
+	NAMESPACES_COLLECTION    = "namespaces"
+	AUDIT_COLLECTION         = "audit"
+	REVISIONS_COLLECTION     = "revisions"
 )
 
+// state in mongo deployment, external one or embedded mongod
 type MongoStorage struct {
 	client       *mongo.Client
 	routes       *routes
 	taskMessages *taskMessages
 	esbRecords   *esbRecords
 	messagePools *messagePools
+	namespaces   *namespaces
+	auditLog     *auditLog
+	revisions    *revisions
+	transactions bool
+	// nil if deployment is external
+	embedded *mim.Server
 }
 
-var db = &MongoStorage{}
-
 func (db *MongoStorage) init(ctx context.Context, client *mongo.Client, cfg *configs.DatabaseConfig) error {
 	db.client = client
 	var err error
+	db.transactions, err = detectTransactions(ctx, client)
+	if err != nil {
+		return err
+	}
 	db.routes, err = createRoutes(ctx, client, cfg)
 	if err != nil {
 		return err
@@ -45,31 +64,90 @@ func (db *MongoStorage) init(ctx context.Context, client *mongo.Client, cfg *con
 	if err != nil {
 		return err
 	}
+	db.namespaces, err = createNamespaces(ctx, client, cfg)
+	if err != nil {
+		return err
+	}
+	db.auditLog, err = createAuditLog(ctx, client, cfg)
+	if err != nil {
+		return err
+	}
+	db.revisions, err = createRevisions(ctx, client, cfg)
+	if err != nil {
+		return err
+	}
 	return nil
 }
 
-func AddStaticEndpoint(ctx context.Context, path string, response string) error {
-	return db.routes.addRoute(ctx, Route{
+func (db *MongoStorage) collections() collections {
+	return collections{
+		routes:       db.routes,
+		taskMessages: db.taskMessages,
+		esbRecords:   db.esbRecords,
+		messagePools: db.messagePools,
+		namespaces:   db.namespaces,
+		auditLog:     db.auditLog,
+		revisions:    db.revisions,
+	}
+}
+
+func (db *MongoStorage) Ping(ctx context.Context) error {
+	return db.client.Ping(ctx, readpref.Primary())
+}
+
+func (db *MongoStorage) Disconnect(ctx context.Context) error {
+	// context of stopping components may be cancelled already
+	flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := db.routes.hits.close(flushCtx); err != nil {
+		zlog.Error().Err(err).Msg("Failed to flush route hits")
+	}
+
+	// disconnecting again is not an error, embedded server may be stopped twice
+	err := db.client.Disconnect(ctx)
+	if err != nil && err != mongo.ErrClientDisconnected {
+		return err
+	}
+	if db.embedded != nil {
+		db.embedded.Stop(ctx)
+		db.embedded = nil
+	}
+	return nil
+}
+
+func AddStaticEndpoint(ctx context.Context, path string, response string, opts ...RouteOption) error {
+	return dbOf(ctx).routes.addRoute(ctx, applyRouteOptions(Route{
 		Path:     path,
 		Type:     STATIC_ENDPOINT_TYPE,
 		Response: response,
-	})
+	}, opts))
 }
 
 func RemoveStaticEndpoint(ctx context.Context, path string) error {
-	return db.routes.removeRoute(ctx, path)
+	return dbOf(ctx).routes.removeRoute(ctx, path)
 }
 
-func UpdateStaticEndpoint(ctx context.Context, path string, response string) error {
-	return db.routes.updateRoute(ctx, Route{
+func UpdateStaticEndpoint(ctx context.Context, path string, response string, opts ...RouteOption) error {
+	return dbOf(ctx).routes.updateRoute(ctx, applyRouteOptions(Route{
 		Path:     path,
 		Type:     STATIC_ENDPOINT_TYPE,
 		Response: response,
-	})
+	}, opts))
+}
+
+func GetStaticEndpoint(ctx context.Context, path string) (Route, error) {
+	route, err := dbOf(ctx).routes.getRoute(ctx, path)
+	if err != nil {
+		return Route{}, err
+	}
+	if route.Type != STATIC_ENDPOINT_TYPE {
+		return Route{}, ErrBadRouteType
+	}
+	return route, nil
 }
 
 func GetStaticEndpointResponse(ctx context.Context, path string) (string, error) {
-	route, err := db.routes.getRoute(ctx, path)
+	route, err := dbOf(ctx).routes.getRoute(ctx, path)
 	if err != nil {
 		return "", err
 	}
@@ -80,31 +158,31 @@ func GetStaticEndpointResponse(ctx context.Context, path string) (string, error)
 }
 
 func ListAllStaticEndpointPaths(ctx context.Context) ([]string, error) {
-	return db.routes.listAllRoutesPathsWithType(ctx, STATIC_ENDPOINT_TYPE)
+	return dbOf(ctx).routes.listAllRoutesPathsWithType(ctx, STATIC_ENDPOINT_TYPE)
 }
 
-func AddProxyEndpoint(ctx context.Context, path string, proxyUrl string) error {
-	return db.routes.addRoute(ctx, Route{
+func AddProxyEndpoint(ctx context.Context, path string, proxyUrl string, opts ...RouteOption) error {
+	return dbOf(ctx).routes.addRoute(ctx, applyRouteOptions(Route{
 		Path:     path,
 		Type:     PROXY_ENDPOINT_TYPE,
 		ProxyURL: proxyUrl,
-	})
+	}, opts))
 }
 
 func RemoveProxyEndpoint(ctx context.Context, path string) error {
-	return db.routes.removeRoute(ctx, path)
+	return dbOf(ctx).routes.removeRoute(ctx, path)
 }
 
-func UpdateProxyEndpoint(ctx context.Context, path string, proxyUrl string) error {
-	return db.routes.updateRoute(ctx, Route{
+func UpdateProxyEndpoint(ctx context.Context, path string, proxyUrl string, opts ...RouteOption) error {
+	return dbOf(ctx).routes.updateRoute(ctx, applyRouteOptions(Route{
 		Path:     path,
 		Type:     PROXY_ENDPOINT_TYPE,
 		ProxyURL: proxyUrl,
-	})
+	}, opts))
 }
 
 func GetProxyEndpointProxyUrl(ctx context.Context, path string) (string, error) {
-	route, err := db.routes.getRoute(ctx, path)
+	route, err := dbOf(ctx).routes.getRoute(ctx, path)
 	if err != nil {
 		return "", err
 	}
@@ -114,32 +192,54 @@ func GetProxyEndpointProxyUrl(ctx context.Context, path string) (string, error)
 	return route.ProxyURL, nil
 }
 
+func GetProxyEndpoint(ctx context.Context, path string) (Route, error) {
+	route, err := dbOf(ctx).routes.getRoute(ctx, path)
+	if err != nil {
+		return Route{}, err
+	}
+	if route.Type != PROXY_ENDPOINT_TYPE {
+		return Route{}, ErrBadRouteType
+	}
+	return route, nil
+}
+
 func ListAllProxyEndpointPaths(ctx context.Context) ([]string, error) {
-	return db.routes.listAllRoutesPathsWithType(ctx, PROXY_ENDPOINT_TYPE)
+	return dbOf(ctx).routes.listAllRoutesPathsWithType(ctx, PROXY_ENDPOINT_TYPE)
 }
 
-func AddDynamicEndpoint(ctx context.Context, path string, scriptName string) error {
-	return db.routes.addRoute(ctx, Route{
+func AddDynamicEndpoint(ctx context.Context, path string, scriptName string, opts ...RouteOption) error {
+	return dbOf(ctx).routes.addRoute(ctx, applyRouteOptions(Route{
 		Path:       path,
 		Type:       DYNAMIC_ENDPOINT_TYPE,
 		ScriptName: scriptName,
-	})
+	}, opts))
 }
 
 func RemoveDynamicEndpoint(ctx context.Context, path string) error {
-	return db.routes.removeRoute(ctx, path)
+	return dbOf(ctx).routes.removeRoute(ctx, path)
 }
 
-func UpdateDynamicEndpoint(ctx context.Context, path string, scriptName string) error {
-	return db.routes.updateRoute(ctx, Route{
+func UpdateDynamicEndpoint(ctx context.Context, path string, scriptName string, opts ...RouteOption) error {
+	return dbOf(ctx).routes.updateRoute(ctx, applyRouteOptions(Route{
 		Path:       path,
 		Type:       DYNAMIC_ENDPOINT_TYPE,
 		ScriptName: scriptName,
-	})
+	}, opts))
+}
+
+func GetDynamicEndpoint(ctx context.Context, path string) (Route, error) {
+	route, err := dbOf(ctx).routes.getRoute(ctx, path)
+	if err != nil {
+		return Route{}, err
+	}
+	if route.Type != DYNAMIC_ENDPOINT_TYPE {
+		return Route{}, ErrBadRouteType
+	}
+	return route, nil
 }
 
 func GetDynamicEndpointScriptName(ctx context.Context, path string) (string, error) {
-	route, err := db.routes.getRoute(ctx, path)
+	route, err := dbOf(ctx).routes.getRoute(ctx, path)
 	if err != nil {
 		return "", err
 	}
@@ -150,51 +250,342 @@ func GetDynamicEndpointScriptName(ctx context.Context, path string) (string, err
 }
 
 func GetRoute(ctx context.Context, path string) (Route, error) {
-	return db.routes.getRoute(ctx, path)
+	ctx, span := tracing.Start(ctx, "database.GetRoute", trace.WithAttributes(attribute.String("route.path", path)))
+	defer span.End()
+
+	route, err := dbOf(ctx).routes.getRoute(ctx, path)
+	if err != ErrNoSuchPath {
+		tracing.RecordError(span, err)
+	}
+	return route, err
+}
+
+// routes of all types, page by page
+func ListRoutes(ctx context.Context, query ListQuery) (Page[Route], error) {
+	return dbOf(ctx).routes.listRoutes(ctx, query)
+}
+
+// stored state of route, see RestoreRoute
+func SnapshotRoute(ctx context.Context, path string) (Snapshot, error) {
+	return dbOf(ctx).routes.snapshot(ctx, path)
+}
+
+// puts route back to snapshot state, removes it if it did not exist
+func RestoreRoute(ctx context.Context, path string, snapshot Snapshot) error {
+	return dbOf(ctx).routes.restore(ctx, path, snapshot)
+}
+
+// scripts referenced by stored routes, see routes.listScripts
+func ListRouteScripts(ctx context.Context) ([]string, error) {
+	return dbOf(ctx).routes.listScripts(ctx)
+}
+
+// counts request served by route
+func RecordRouteHit(ctx context.Context, path string) {
+	dbOf(ctx).routes.recordHit(path)
 }
 
 func ListAllDynamicEndpointPaths(ctx context.Context) ([]string, error) {
-	return db.routes.listAllRoutesPathsWithType(ctx, DYNAMIC_ENDPOINT_TYPE)
+	return dbOf(ctx).routes.listAllRoutesPathsWithType(ctx, DYNAMIC_ENDPOINT_TYPE)
+}
+
+func AddGraphQLEndpoint(ctx context.Context, path string, schema string, operations []GraphQLOperation, opts ...RouteOption) error {
+	return dbOf(ctx).routes.addRoute(ctx, applyRouteOptions(Route{
+		Path:       path,
+		Type:       GRAPHQL_ENDPOINT_TYPE,
+		Schema:     schema,
+		Operations: operations,
+	}, opts))
+}
+
+func RemoveGraphQLEndpoint(ctx context.Context, path string) error {
+	return dbOf(ctx).routes.removeRoute(ctx, path)
+}
+
+func UpdateGraphQLEndpoint(ctx context.Context, path string, schema string, operations []GraphQLOperation, opts ...RouteOption) error {
+	return dbOf(ctx).routes.updateRoute(ctx, applyRouteOptions(Route{
+		Path:       path,
+		Type:       GRAPHQL_ENDPOINT_TYPE,
+		Schema:     schema,
+		Operations: operations,
+	}, opts))
+}
+
+func GetGraphQLEndpoint(ctx context.Context, path string) (Route, error) {
+	route, err := dbOf(ctx).routes.getRoute(ctx, path)
+	if err != nil {
+		return Route{}, err
+	}
+	if route.Type != GRAPHQL_ENDPOINT_TYPE {
+		return Route{}, ErrBadRouteType
+	}
+	return route, nil
+}
+
+func ListAllGraphQLEndpointPaths(ctx context.Context) ([]string, error) {
+	return dbOf(ctx).routes.listAllRoutesPathsWithType(ctx, GRAPHQL_ENDPOINT_TYPE)
+}
+
+func AddWebSocketEndpoint(ctx context.Context, path string, onConnect []string, replies []WebSocketReply, scriptName string, opts ...RouteOption) error {
+	return dbOf(ctx).routes.addRoute(ctx, applyRouteOptions(Route{
+		Path:       path,
+		Type:       WEBSOCKET_ENDPOINT_TYPE,
+		OnConnect:  onConnect,
+		Replies:    replies,
+		ScriptName: scriptName,
+	}, opts))
+}
+
+func RemoveWebSocketEndpoint(ctx context.Context, path string) error {
+	return dbOf(ctx).routes.removeRoute(ctx, path)
+}
+
+func UpdateWebSocketEndpoint(ctx context.Context, path string, onConnect []string, replies []WebSocketReply, scriptName string, opts ...RouteOption) error {
+	return dbOf(ctx).routes.updateRoute(ctx, applyRouteOptions(Route{
+		Path:       path,
+		Type:       WEBSOCKET_ENDPOINT_TYPE,
+		OnConnect:  onConnect,
+		Replies:    replies,
+		ScriptName: scriptName,
+	}, opts))
+}
+
+func GetWebSocketEndpoint(ctx context.Context, path string) (Route, error) {
+	route, err := dbOf(ctx).routes.getRoute(ctx, path)
+	if err != nil {
+		return Route{}, err
+	}
+	if route.Type != WEBSOCKET_ENDPOINT_TYPE {
+		return Route{}, ErrBadRouteType
+	}
+	return route, nil
+}
+
+func ListAllWebSocketEndpointPaths(ctx context.Context) ([]string, error) {
+	return dbOf(ctx).routes.listAllRoutesPathsWithType(ctx, WEBSOCKET_ENDPOINT_TYPE)
+}
+
+func AddStreamEndpoint(ctx context.Context, path string, mode string, events []StreamEvent, loop bool, contentType string, opts ...RouteOption) error {
+	return dbOf(ctx).routes.addRoute(ctx, applyRouteOptions(Route{
+		Path:        path,
+		Type:        STREAM_ENDPOINT_TYPE,
+		StreamMode:  mode,
+		Events:      events,
+		Loop:        loop,
+		ContentType: contentType,
+	}, opts))
+}
+
+func RemoveStreamEndpoint(ctx context.Context, path string) error {
+	return dbOf(ctx).routes.removeRoute(ctx, path)
+}
+
+func UpdateStreamEndpoint(ctx context.Context, path string, mode string, events []StreamEvent, loop bool, contentType string, opts ...RouteOption) error {
+	return dbOf(ctx).routes.updateRoute(ctx, applyRouteOptions(Route{
+		Path:        path,
+		Type:        STREAM_ENDPOINT_TYPE,
+		StreamMode:  mode,
+		Events:      events,
+		Loop:        loop,
+		ContentType: contentType,
+	}, opts))
+}
+
+func GetStreamEndpoint(ctx context.Context, path string) (Route, error) {
+	route, err := dbOf(ctx).routes.getRoute(ctx, path)
+	if err != nil {
+		return Route{}, err
+	}
+	if route.Type != STREAM_ENDPOINT_TYPE {
+		return Route{}, ErrBadRouteType
+	}
+	return route, nil
+}
+
+func ListAllStreamEndpointPaths(ctx context.Context) ([]string, error) {
+	return dbOf(ctx).routes.listAllRoutesPathsWithType(ctx, STREAM_ENDPOINT_TYPE)
+}
+
+// file is stored in file storage under fileName
+func AddFileEndpoint(ctx context.Context, path string, fileName string, fileSize int64, contentType string, opts ...RouteOption) error {
+	return dbOf(ctx).routes.addRoute(ctx, applyRouteOptions(Route{
+		Path:        path,
+		Type:        FILE_ENDPOINT_TYPE,
+		FileName:    fileName,
+		FileSize:    fileSize,
+		ContentType: contentType,
+	}, opts))
+}
+
+func RemoveFileEndpoint(ctx context.Context, path string) error {
+	return dbOf(ctx).routes.removeRoute(ctx, path)
+}
+
+func UpdateFileEndpoint(ctx context.Context, path string, fileName string, fileSize int64, contentType string, opts ...RouteOption) error {
+	return dbOf(ctx).routes.updateRoute(ctx, applyRouteOptions(Route{
+		Path:        path,
+		Type:        FILE_ENDPOINT_TYPE,
+		FileName:    fileName,
+		FileSize:    fileSize,
+		ContentType: contentType,
+	}, opts))
+}
+
+func GetFileEndpoint(ctx context.Context, path string) (Route, error) {
+	route, err := dbOf(ctx).routes.getRoute(ctx, path)
+	if err != nil {
+		return Route{}, err
+	}
+	if route.Type != FILE_ENDPOINT_TYPE {
+		return Route{}, ErrBadRouteType
+	}
+	return route, nil
+}
+
+// paths of routes of endpoint type, page by page
+func FindRoutePaths(ctx context.Context, routeType string, query ListQuery) (Page[string], error) {
+	return dbOf(ctx).routes.findRoutePaths(ctx, routeType, query)
+}
+
+// nil policy removes route own cors policy
+func SetRouteCorsPolicy(ctx context.Context, path string, policy *CorsPolicy) error {
+	return dbOf(ctx).routes.setRouteField(ctx, path, ROUTE_CORS_FIELD, policy)
+}
+
+// nil policy removes route own rate limit policy
+func SetRouteRateLimitPolicy(ctx context.Context, path string, policy *RateLimitPolicy) error {
+	return dbOf(ctx).routes.setRouteField(ctx, path, ROUTE_RATE_LIMIT_FIELD, policy)
+}
+
+func AddNamespace(ctx context.Context, namespace Namespace) error {
+	return dbOf(ctx).namespaces.addNamespace(ctx, namespace)
+}
+
+func RemoveNamespace(ctx context.Context, prefix string) error {
+	return dbOf(ctx).namespaces.removeNamespace(ctx, prefix)
+}
+
+func UpdateNamespace(ctx context.Context, namespace Namespace) error {
+	return dbOf(ctx).namespaces.updateNamespace(ctx, namespace)
+}
+
+func GetNamespace(ctx context.Context, prefix string) (Namespace, error) {
+	return getNamespace(ctx, dbOf(ctx).namespaces, prefix)
+}
+
+func ListNamespaces(ctx context.Context) ([]Namespace, error) {
+	return dbOf(ctx).namespaces.listNamespaces(ctx)
+}
+
+// returns namespace with the longest prefix covering the path
+func MatchNamespace(ctx context.Context, path string) (Namespace, error) {
+	return matchNamespace(ctx, dbOf(ctx).namespaces, path)
+}
+
+func SnapshotNamespace(ctx context.Context, prefix string) (Snapshot, error) {
+	return dbOf(ctx).namespaces.snapshot(ctx, prefix)
+}
+
+func AddAuditRecord(ctx context.Context, record AuditRecord) error {
+	return dbOf(ctx).auditLog.addRecord(ctx, record)
+}
+
+func FindAuditRecords(ctx context.Context, query AuditQuery) (Page[AuditRecord], error) {
+	return dbOf(ctx).auditLog.findRecords(ctx, query)
+}
+
+// returns stored revision, which is the latest one if definition is not changed
+func AddRevision(ctx context.Context, revision Revision) (Revision, error) {
+	return dbOf(ctx).revisions.addRevision(ctx, revision)
+}
+
+func GetRevision(ctx context.Context, resource string, key string, number int64) (Revision, error) {
+	return dbOf(ctx).revisions.getRevision(ctx, resource, key, number)
+}
+
+func FindRevisions(ctx context.Context, resource string, key string, query ListQuery) (Page[Revision], error) {
+	return dbOf(ctx).revisions.findRevisions(ctx, resource, key, query)
+}
+
+// replaces route definition with revision state, route is created if it was removed
+func RollbackRoute(ctx context.Context, path string, state AuditState) error {
+	return dbOf(ctx).routes.rollback(ctx, path, state)
+}
+
+func RollbackESBRecord(ctx context.Context, poolNameIn string, state AuditState) error {
+	return dbOf(ctx).esbRecords.restore(ctx, poolNameIn, Snapshot{raw: state})
 }
 
 func AddTaskMessage(ctx context.Context, taskMessage TaskMessage) error {
-	return db.taskMessages.addTaskMessage(ctx, taskMessage)
+	return dbOf(ctx).taskMessages.addTaskMessage(ctx, taskMessage)
 }
 
 func GetTaskMessages(ctx context.Context, taskId string) ([]string, error) {
-	return db.taskMessages.getTaskMessages(ctx, taskId)
+	return dbOf(ctx).taskMessages.getTaskMessages(ctx, taskId)
+}
+
+func FindTaskMessages(ctx context.Context, taskId string, query ListQuery) (Page[string], error) {
+	return dbOf(ctx).taskMessages.findTaskMessages(ctx, taskId, query)
 }
 
 func AddESBRecord(ctx context.Context, esbRecord ESBRecord) error {
-	return db.esbRecords.addESBRecord(ctx, esbRecord)
+	return dbOf(ctx).esbRecords.addESBRecord(ctx, esbRecord)
 }
 
 func RemoveESBRecord(ctx context.Context, poolNameIn string) error {
-	return db.esbRecords.removeESBRecord(ctx, poolNameIn)
+	return dbOf(ctx).esbRecords.removeESBRecord(ctx, poolNameIn)
+}
+
+func UpdateESBRecord(ctx context.Context, esbRecord ESBRecord) error {
+	return dbOf(ctx).esbRecords.updateESBRecord(ctx, esbRecord)
 }
 
 func GetESBRecord(ctx context.Context, poolNameIn string) (ESBRecord, error) {
-	return db.esbRecords.getESBRecord(ctx, poolNameIn)
+	return dbOf(ctx).esbRecords.getESBRecord(ctx, poolNameIn)
 }
 
 func ListESBRecords(ctx context.Context) ([]ESBRecord, error) {
-	return db.esbRecords.listESBRecords(ctx)
+	return dbOf(ctx).esbRecords.listESBRecords(ctx)
+}
+
+func SnapshotESBRecord(ctx context.Context, poolNameIn string) (Snapshot, error) {
+	return dbOf(ctx).esbRecords.snapshot(ctx, poolNameIn)
+}
+
+func RestoreESBRecord(ctx context.Context, poolNameIn string, snapshot Snapshot) error {
+	return dbOf(ctx).esbRecords.restore(ctx, poolNameIn, snapshot)
+}
+
+func FindESBRecords(ctx context.Context, query ListQuery) (Page[ESBRecord], error) {
+	return dbOf(ctx).esbRecords.findESBRecords(ctx, query)
 }
 
 func AddMessagePool(ctx context.Context, messagePool MessagePool) error {
-	return db.messagePools.addMessagePool(ctx, messagePool)
+	return dbOf(ctx).messagePools.addMessagePool(ctx, messagePool)
 }
 
 func RemoveMessagePool(ctx context.Context, name string) error {
-	return db.messagePools.removeMessagePool(ctx, name)
+	return dbOf(ctx).messagePools.removeMessagePool(ctx, name)
 }
 
 func GetMessagePool(ctx context.Context, name string) (MessagePool, error) {
-	return db.messagePools.getMessagePool(ctx, name)
+	return dbOf(ctx).messagePools.getMessagePool(ctx, name)
 }
 
 func ListMessagePools(ctx context.Context) ([]MessagePool, error) {
-	return db.messagePools.listMessagePools(ctx)
+	return dbOf(ctx).messagePools.listMessagePools(ctx)
+}
+
+func SnapshotMessagePool(ctx context.Context, name string) (Snapshot, error) {
+	return dbOf(ctx).messagePools.snapshot(ctx, name)
+}
+
+func RestoreMessagePool(ctx context.Context, name string, snapshot Snapshot) error {
+	return dbOf(ctx).messagePools.restore(ctx, name, snapshot)
+}
+
+func FindMessagePools(ctx context.Context, query ListQuery) (Page[MessagePool], error) {
+	return dbOf(ctx).messagePools.findMessagePools(ctx, query)
 }
 
 func GetMessagePoolReadMessages(ctx context.Context, messagePool MessagePool) ([]string, error) {
@@ -208,3 +599,15 @@ func GetMessagePoolWriteMessages(ctx context.Context, messagePool MessagePool) (
 
 	return GetTaskMessages(ctx, poolTasksId)
 }
+
+func FindMessagePoolReadMessages(ctx context.Context, messagePool MessagePool, query ListQuery) (Page[string], error) {
+	poolTasksId := fmt.Sprintf("%s:%s:%s:read", messagePool.Broker, messagePool.Name, messagePool.Queue)
+
+	return FindTaskMessages(ctx, poolTasksId, query)
+}
+
+func FindMessagePoolWriteMessages(ctx context.Context, messagePool MessagePool, query ListQuery) (Page[string], error) {
+	poolTasksId := fmt.Sprintf("%s:%s:%s:write", messagePool.Broker, messagePool.Name, messagePool.Queue)
+
+	return FindTaskMessages(ctx, poolTasksId, query)
+}
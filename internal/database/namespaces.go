@@ -0,0 +1,170 @@
+package database
+
+import (
+	"context"
+	"mock-server/internal/configs"
+	"mock-server/internal/util"
+	"strings"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// namespaces are few and matched by prefix on each mock request,
+// so all of them are kept in memory instead of per key cache
+type namespaces struct {
+	coll   *mongo.Collection
+	cached []Namespace // nil if not loaded yet
+	mutex  sync.RWMutex
+}
+
+func createNamespaces(ctx context.Context, client *mongo.Client, cfg *configs.DatabaseConfig) (*namespaces, error) {
+	ns := &namespaces{}
+	err := ns.init(ctx, client, cfg)
+	return ns, err
+}
+
+func (ns *namespaces) init(ctx context.Context, client *mongo.Client, cfg *configs.DatabaseConfig) error {
+	ns.coll = client.Database(databaseName(cfg)).Collection(NAMESPACES_COLLECTION)
+
+	indexModel := mongo.IndexModel{
+		Keys:    bson.D{{Key: NAMESPACE_PREFIX_FIELD, Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}
+	_, err := ns.coll.Indexes().CreateOne(ctx, indexModel)
+	return err
+}
+
+func (ns *namespaces) addNamespace(ctx context.Context, namespace Namespace) error {
+	return util.RunWithWriteLock(&ns.mutex, func() error {
+		_, err := ns.coll.InsertOne(
+			ctx,
+			namespace,
+		)
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrDuplicateKey
+		} else if err != nil {
+			return err
+		}
+		ns.cached = nil
+		return nil
+	})
+}
+
+func (ns *namespaces) removeNamespace(ctx context.Context, prefix string) error {
+	return util.RunWithWriteLock(&ns.mutex, func() error {
+		_, err := ns.coll.DeleteOne(
+			ctx,
+			bson.D{primitive.E{Key: NAMESPACE_PREFIX_FIELD, Value: prefix}},
+		)
+		if err != nil {
+			return err
+		}
+		ns.cached = nil
+		return nil
+	})
+}
+
+func (ns *namespaces) updateNamespace(ctx context.Context, namespace Namespace) error {
+	return util.RunWithWriteLock(&ns.mutex, func() error {
+		res, err := ns.coll.ReplaceOne(
+			ctx,
+			bson.D{primitive.E{Key: NAMESPACE_PREFIX_FIELD, Value: namespace.Prefix}},
+			namespace,
+		)
+		if err != nil {
+			return err
+		}
+		if res.MatchedCount == 0 {
+			return ErrNoSuchNamespace
+		}
+		ns.cached = nil
+		return nil
+	})
+}
+
+// must be called under lock
+func (ns *namespaces) load(ctx context.Context) ([]Namespace, error) {
+	opts := options.Find().SetSort(bson.D{{Key: NAMESPACE_PREFIX_FIELD, Value: 1}})
+	cursor, err := ns.coll.Find(ctx, bson.D{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	var results = []Namespace{}
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (ns *namespaces) listNamespaces(ctx context.Context) ([]Namespace, error) {
+	cached, _ := util.RunWithReadLock(&ns.mutex, func() ([]Namespace, error) {
+		return ns.cached, nil
+	})
+	if cached != nil {
+		return cached, nil
+	}
+
+	var loaded []Namespace
+	err := util.RunWithWriteLock(&ns.mutex, func() error {
+		if ns.cached == nil {
+			var err error
+			if ns.cached, err = ns.load(ctx); err != nil {
+				return err
+			}
+		}
+		loaded = ns.cached
+		return nil
+	})
+	return loaded, err
+}
+
+func getNamespace(ctx context.Context, ns namespaceStore, prefix string) (Namespace, error) {
+	all, err := ns.listNamespaces(ctx)
+	if err != nil {
+		return Namespace{}, err
+	}
+	for _, namespace := range all {
+		if namespace.Prefix == prefix {
+			return namespace, nil
+		}
+	}
+	return Namespace{}, ErrNoSuchNamespace
+}
+
+func namespaceCovers(prefix string, path string) bool {
+	if path == prefix {
+		return true
+	}
+	// prefix matches whole path segments only: /api covers /api/users, but not /apix
+	return strings.HasPrefix(path, strings.TrimSuffix(prefix, "/")+"/")
+}
+
+func matchNamespace(ctx context.Context, ns namespaceStore, path string) (Namespace, error) {
+	all, err := ns.listNamespaces(ctx)
+	if err != nil {
+		return Namespace{}, err
+	}
+
+	// query string is not a part of namespace
+	if i := strings.IndexByte(path, '?'); i != -1 {
+		path = path[:i]
+	}
+
+	var match *Namespace
+	for i := range all {
+		if !namespaceCovers(all[i].Prefix, path) {
+			continue
+		}
+		if match == nil || len(all[i].Prefix) > len(match.Prefix) {
+			match = &all[i]
+		}
+	}
+	if match == nil {
+		return Namespace{}, ErrNoSuchNamespace
+	}
+	return *match, nil
+}
@@ -0,0 +1,215 @@
+package database
+
+import (
+	"context"
+	"encoding/base64"
+	"regexp"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// page size of clients of paged api, see ListQuery.Limit
+	DEFAULT_PAGE_LIMIT = 100
+	MAX_PAGE_LIMIT     = 1000
+
+	// insertion order
+	ID_FIELD = "_id"
+)
+
+// parameters of paged list query, zero values select all items
+// in insertion order without filtering
+type ListQuery struct {
+	// all items if not positive
+	Limit int64
+	// opaque position returned as next cursor of previous page,
+	// valid only with the same sort
+	Cursor string
+	// bson field, one of sort fields supported by listed collection
+	SortBy string
+	Desc   bool
+
+	// prefix of collection key (route path, pool name, esb in-pool name)
+	Prefix string
+	// case insensitive substring of collection text fields
+	Search string
+	// message pools only
+	Broker string
+	// routes only
+	Type string
+}
+
+type Page[T any] struct {
+	Items []T
+	// empty on the last page
+	NextCursor string
+}
+
+// position of the last item of page, _id breaks ties of sort field;
+// value is null if item has no sort field
+type pageCursor struct {
+	Value bson.RawValue      `bson:"v"`
+	Id    primitive.ObjectID `bson:"id"`
+}
+
+func encodeCursor(cursor pageCursor) (string, error) {
+	raw, err := bson.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func decodeCursor(encoded string) (pageCursor, error) {
+	var cursor pageCursor
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return cursor, ErrInvalidCursor
+	}
+	if err := bson.Unmarshal(raw, &cursor); err != nil || cursor.Value.Type == 0 {
+		return cursor, ErrInvalidCursor
+	}
+	return cursor, nil
+}
+
+func prefixFilter(field string, prefix string) bson.E {
+	return bson.E{Key: field, Value: primitive.Regex{Pattern: "^" + regexp.QuoteMeta(prefix)}}
+}
+
+// matches documents with substring in any of fields
+func searchFilter(search string, fields ...string) bson.E {
+	alternatives := make(bson.A, 0, len(fields))
+	for _, field := range fields {
+		alternatives = append(alternatives, bson.D{{
+			Key:   field,
+			Value: primitive.Regex{Pattern: regexp.QuoteMeta(search), Options: "i"},
+		}})
+	}
+	return bson.E{Key: "$or", Value: alternatives}
+}
+
+// documents placed after cursor in sort order, documents without sort
+// field are sorted as null, before any value (after in descending order)
+func afterCursorFilter(sortBy string, desc bool, cursor pageCursor) bson.D {
+	op := "$gt"
+	if desc {
+		op = "$lt"
+	}
+
+	if sortBy == ID_FIELD {
+		return bson.D{{Key: ID_FIELD, Value: bson.D{{Key: op, Value: cursor.Id}}}}
+	}
+
+	// comparison operators do not match null, so nulls are matched explicitly
+	isNull := bson.D{{Key: sortBy, Value: nil}}
+	if cursor.Value.Type == bsontype.Null {
+		tie := bson.D{{Key: sortBy, Value: nil}, {Key: ID_FIELD, Value: bson.D{{Key: op, Value: cursor.Id}}}}
+		if desc {
+			return tie
+		}
+		return bson.D{{Key: "$or", Value: bson.A{tie, bson.D{{Key: sortBy, Value: bson.D{{Key: "$ne", Value: nil}}}}}}}
+	}
+
+	after := bson.A{
+		bson.D{{Key: sortBy, Value: bson.D{{Key: op, Value: cursor.Value}}}},
+		bson.D{
+			{Key: sortBy, Value: cursor.Value},
+			{Key: ID_FIELD, Value: bson.D{{Key: op, Value: cursor.Id}}},
+		},
+	}
+	if desc {
+		after = append(after, isNull)
+	}
+	return bson.D{{Key: "$or", Value: after}}
+}
+
+// runs filter with keyset pagination of query, sortFields are the fields
+// collection allows to sort by, insertion order is always allowed
+func findPage[T any](
+	ctx context.Context,
+	coll *mongo.Collection,
+	filter bson.D,
+	query ListQuery,
+	sortFields []string,
+	opts *options.FindOptions,
+) (Page[T], error) {
+	page := Page[T]{Items: make([]T, 0)}
+
+	sortBy := query.SortBy
+	if sortBy == "" {
+		sortBy = ID_FIELD
+	}
+	if sortBy != ID_FIELD && !contains(sortFields, sortBy) {
+		return page, ErrInvalidSort
+	}
+
+	limit := query.Limit
+	if limit > MAX_PAGE_LIMIT {
+		limit = MAX_PAGE_LIMIT
+	}
+
+	if query.Cursor != "" {
+		cursor, err := decodeCursor(query.Cursor)
+		if err != nil {
+			return page, err
+		}
+		filter = bson.D{{Key: "$and", Value: bson.A{filter, afterCursorFilter(sortBy, query.Desc, cursor)}}}
+	}
+
+	order := 1
+	if query.Desc {
+		order = -1
+	}
+	sort := bson.D{{Key: sortBy, Value: order}}
+	if sortBy != ID_FIELD {
+		sort = append(sort, bson.E{Key: ID_FIELD, Value: order})
+	}
+
+	// one extra document tells whether next page exists
+	opts = opts.SetSort(sort)
+	if limit > 0 {
+		opts = opts.SetLimit(limit + 1)
+	}
+	cursor, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		return page, err
+	}
+	defer cursor.Close(ctx)
+
+	var last pageCursor
+	for cursor.Next(ctx) {
+		if limit > 0 && int64(len(page.Items)) == limit {
+			page.NextCursor, err = encodeCursor(last)
+			if err != nil {
+				return page, err
+			}
+			break
+		}
+
+		var item T
+		if err := cursor.Decode(&item); err != nil {
+			return page, err
+		}
+		page.Items = append(page.Items, item)
+
+		last.Id, _ = cursor.Current.Lookup(ID_FIELD).ObjectIDOK()
+		last.Value = cursor.Current.Lookup(sortBy)
+		if last.Value.Type == 0 {
+			last.Value = bson.RawValue{Type: bsontype.Null}
+		}
+	}
+	return page, cursor.Err()
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
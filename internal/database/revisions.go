@@ -0,0 +1,124 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"mock-server/internal/configs"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// attempts to take next revision number when several changes of
+// the same resource are recorded at once
+const REVISION_INSERT_ATTEMPTS = 3
+
+// numbered definitions of routes and esb records, kept after resource is removed
+type revisions struct {
+	coll *mongo.Collection
+}
+
+func createRevisions(ctx context.Context, client *mongo.Client, cfg *configs.DatabaseConfig) (*revisions, error) {
+	r := &revisions{}
+	err := r.init(ctx, client, cfg)
+	return r, err
+}
+
+func (r *revisions) init(ctx context.Context, client *mongo.Client, cfg *configs.DatabaseConfig) error {
+	r.coll = client.Database(databaseName(cfg)).Collection(REVISIONS_COLLECTION)
+
+	indexModel := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: REVISION_RESOURCE_FIELD, Value: 1},
+			{Key: REVISION_KEY_FIELD, Value: 1},
+			{Key: REVISION_NUMBER_FIELD, Value: 1},
+		},
+		Options: options.Index().SetUnique(true),
+	}
+	_, err := r.coll.Indexes().CreateOne(ctx, indexModel)
+	return err
+}
+
+func resourceFilter(resource string, key string) bson.D {
+	return bson.D{
+		{Key: REVISION_RESOURCE_FIELD, Value: resource},
+		{Key: REVISION_KEY_FIELD, Value: key},
+	}
+}
+
+func (r *revisions) getLatest(ctx context.Context, resource string, key string) (Revision, error) {
+	var res Revision
+	opts := options.FindOne().SetSort(bson.D{{Key: REVISION_NUMBER_FIELD, Value: -1}})
+	err := r.coll.FindOne(ctx, resourceFilter(resource, key), opts).Decode(&res)
+	if err == mongo.ErrNoDocuments {
+		return res, ErrNoSuchRevision
+	}
+	return res, err
+}
+
+// stores revision with next number, unless definition equals the latest revision
+func (r *revisions) addRevision(ctx context.Context, revision Revision) (Revision, error) {
+	for attempt := 0; ; attempt++ {
+		latest, err := r.getLatest(ctx, revision.Resource, revision.Key)
+		switch err {
+		case nil:
+			if latest.Code == revision.Code && bytes.Equal(latest.State, revision.State) {
+				return latest, nil
+			}
+			revision.Number = latest.Number + 1
+		case ErrNoSuchRevision:
+			revision.Number = 1
+		default:
+			return revision, err
+		}
+
+		_, err = r.coll.InsertOne(ctx, revision)
+		if mongo.IsDuplicateKeyError(err) && attempt+1 < REVISION_INSERT_ATTEMPTS {
+			continue
+		}
+		return revision, err
+	}
+}
+
+func (r *revisions) getRevision(ctx context.Context, resource string, key string, number int64) (Revision, error) {
+	var res Revision
+	filter := append(resourceFilter(resource, key), bson.E{Key: REVISION_NUMBER_FIELD, Value: number})
+	err := r.coll.FindOne(ctx, filter).Decode(&res)
+	if err == mongo.ErrNoDocuments {
+		return res, ErrNoSuchRevision
+	}
+	return res, err
+}
+
+func (r *revisions) findRevisions(ctx context.Context, resource string, key string, query ListQuery) (Page[Revision], error) {
+	sortFields := []string{REVISION_NUMBER_FIELD}
+	return findPage[Revision](ctx, r.coll, resourceFilter(resource, key), query, sortFields, options.Find())
+}
+
+// definition of resource stored in revision: document of snapshot without _id
+// and fields which change without admin requests; nil if document did not exist
+func NewRevisionState(snapshot Snapshot) (AuditState, error) {
+	if !snapshot.Exists() {
+		return nil, nil
+	}
+
+	var doc bson.D
+	if err := snapshot.Decode(&doc); err != nil {
+		return nil, err
+	}
+	state := make(bson.D, 0, len(doc))
+	for _, elem := range doc {
+		switch elem.Key {
+		case ID_FIELD, ROUTE_CREATED_AT_FIELD, ROUTE_UPDATED_AT_FIELD, ROUTE_HITS_FIELD:
+		default:
+			state = append(state, elem)
+		}
+	}
+	return bson.Marshal(state)
+}
+
+// decodes stored definition into Route or ESBRecord
+func (r *Revision) DecodeState(v interface{}) error {
+	return bson.Unmarshal(r.State, v)
+}
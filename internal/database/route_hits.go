@@ -0,0 +1,102 @@
+package database
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	zlog "github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const HITS_FLUSH_INTERVAL = 5 * time.Second
+
+// hits of mock routes are counted in memory and added to stored
+// counters periodically, so serving a request does not wait for a write
+type routeHits struct {
+	coll    *mongo.Collection
+	mutex   sync.Mutex
+	pending map[string]int64 // by route path
+	stop    chan struct{}
+	done    chan struct{}
+	stopped sync.Once
+}
+
+func newRouteHits(coll *mongo.Collection) *routeHits {
+	h := &routeHits{
+		coll:    coll,
+		pending: make(map[string]int64),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go h.run()
+	return h
+}
+
+func (h *routeHits) record(path string) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.pending[path]++
+}
+
+// hits not flushed yet
+func (h *routeHits) get(path string) int64 {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.pending[path]
+}
+
+// drops hits of removed route, so they are not counted for route created at the same path
+func (h *routeHits) forget(path string) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	delete(h.pending, path)
+}
+
+func (h *routeHits) flush(ctx context.Context) error {
+	h.mutex.Lock()
+	pending := h.pending
+	h.pending = make(map[string]int64)
+	h.mutex.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	models := make([]mongo.WriteModel, 0, len(pending))
+	for path, hits := range pending {
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.D{{Key: ROUTE_PATH_FIELD, Value: path}}).
+			SetUpdate(bson.D{{Key: "$inc", Value: bson.D{{Key: ROUTE_HITS_FIELD, Value: hits}}}}),
+		)
+	}
+	_, err := h.coll.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	return err
+}
+
+func (h *routeHits) run() {
+	defer close(h.done)
+
+	ticker := time.NewTicker(HITS_FLUSH_INTERVAL)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := h.flush(context.Background()); err != nil {
+				zlog.Error().Err(err).Msg("Failed to flush route hits")
+			}
+		case <-h.stop:
+			return
+		}
+	}
+}
+
+// stops periodic flushing and flushes what is left, safe to call again
+func (h *routeHits) close(ctx context.Context) error {
+	h.stopped.Do(func() { close(h.stop) })
+	<-h.done
+	return h.flush(ctx)
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"mock-server/internal/configs"
 	"mock-server/internal/util"
+	"reflect"
 	"sync"
+	"time"
 
 	"github.com/bluele/gcache"
 	"go.mongodb.org/mongo-driver/bson"
@@ -17,6 +19,7 @@ type routes struct {
 	coll  *mongo.Collection
 	cache gcache.Cache
 	mutex sync.RWMutex
+	hits  *routeHits
 }
 
 func createRoutes(ctx context.Context, client *mongo.Client, cfg *configs.DatabaseConfig) (*routes, error) {
@@ -26,7 +29,7 @@ func createRoutes(ctx context.Context, client *mongo.Client, cfg *configs.Databa
 }
 
 func (r *routes) init(ctx context.Context, client *mongo.Client, cfg *configs.DatabaseConfig) error {
-	r.coll = client.Database(DATABASE_NAME).Collection(ROUTES_COLLECTION)
+	r.coll = client.Database(databaseName(cfg)).Collection(ROUTES_COLLECTION)
 	r.cache = gcache.New(cfg.CacheSize).Simple().LoaderFunc(func(path interface{}) (interface{}, error) {
 		var res Route
 		err := r.coll.FindOne(
@@ -36,20 +39,93 @@ func (r *routes) init(ctx context.Context, client *mongo.Client, cfg *configs.Da
 		return res, err
 	}).Build()
 
-	indexModel := mongo.IndexModel{
-		Keys:    bson.D{{Key: ROUTE_PATH_FIELD, Value: 1}},
-		Options: options.Index().SetUnique(true),
+	indexModels := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: ROUTE_PATH_FIELD, Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			// expired routes are removed by mongo ttl monitor
+			Keys:    bson.D{{Key: ROUTE_EXPIRE_AT_FIELD, Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
 	}
-	_, err := r.coll.Indexes().CreateOne(ctx, indexModel)
-	return err
+	if _, err := r.coll.Indexes().CreateMany(ctx, indexModels); err != nil {
+		return err
+	}
+
+	r.hits = newRouteHits(r.coll)
+	return nil
+}
+
+type RouteOption func(*Route)
+
+func WithActivityWindow(window ActivityWindow) RouteOption {
+	return func(route *Route) {
+		route.ActivityWindow = window
+	}
+}
+
+// alternative bodies of static route chosen by accept header
+func WithRepresentations(representations []Representation) RouteOption {
+	return func(route *Route) {
+		route.Representations = representations
+	}
+}
+
+func applyRouteOptions(route Route, opts []RouteOption) Route {
+	for _, opt := range opts {
+		opt(&route)
+	}
+	return route
+}
+
+// ttl monitor runs once a minute, so expired routes may still exist
+func (w *ActivityWindow) IsExpired(now time.Time) bool {
+	return w.ExpireAt != nil && !now.Before(*w.ExpireAt)
+}
+
+func (w *ActivityWindow) IsActive(now time.Time) bool {
+	if w.IsExpired(now) {
+		return false
+	}
+	if w.ActiveFrom != nil && now.Before(*w.ActiveFrom) {
+		return false
+	}
+	if w.ActiveUntil != nil && !now.Before(*w.ActiveUntil) {
+		return false
+	}
+	return true
+}
+
+// matches routes that are not expired yet (including routes without expiry)
+func notExpiredFilter(now time.Time) bson.E {
+	return bson.E{Key: ROUTE_EXPIRE_AT_FIELD, Value: bson.D{{Key: "$not", Value: bson.D{{Key: "$lte", Value: now}}}}}
 }
 
 func (r *routes) addRoute(ctx context.Context, route Route) error {
+	now := time.Now()
+	route.CreatedAt = &now
+	route.UpdatedAt = &now
+
 	return util.RunWithWriteLock(&r.mutex, func() error {
 		_, err := r.coll.InsertOne(
 			ctx,
 			route,
 		)
+		if mongo.IsDuplicateKeyError(err) {
+			// path may be occupied by expired route not yet removed by ttl monitor
+			res, delErr := r.coll.DeleteOne(ctx, bson.D{
+				{Key: ROUTE_PATH_FIELD, Value: route.Path},
+				{Key: ROUTE_EXPIRE_AT_FIELD, Value: bson.D{{Key: "$lte", Value: time.Now()}}},
+			})
+			if delErr != nil {
+				return delErr
+			}
+			if res.DeletedCount != 0 {
+				_, err = r.coll.InsertOne(ctx, route)
+			}
+		}
 		if mongo.IsDuplicateKeyError(err) {
 			return ErrDuplicateKey
 		} else if err != nil {
@@ -70,30 +146,95 @@ func (r *routes) removeRoute(ctx context.Context, path string) error {
 			return err
 		}
 		r.cache.Remove(path)
+		r.hits.forget(path)
 		return nil
 	})
 }
 
+// fields replaced by update of route, the rest (policies) are kept;
+// both storages update routes with these fields
+func routeUpdateFields(route *Route) bson.D {
+	return bson.D{
+		{Key: ROUTE_RESPONSE_FIELD, Value: route.Response},
+		{Key: ROUTE_REPRESENTATIONS_FIELD, Value: route.Representations},
+		{Key: ROUTE_PROXY_URL_FIELD, Value: route.ProxyURL},
+		{Key: ROUTE_SCRIPT_NAME_FIELD, Value: route.ScriptName},
+		{Key: ROUTE_SCHEMA_FIELD, Value: route.Schema},
+		{Key: ROUTE_OPERATIONS_FIELD, Value: route.Operations},
+		{Key: ROUTE_ON_CONNECT_FIELD, Value: route.OnConnect},
+		{Key: ROUTE_REPLIES_FIELD, Value: route.Replies},
+		{Key: ROUTE_EVENTS_FIELD, Value: route.Events},
+		{Key: ROUTE_STREAM_MODE_FIELD, Value: route.StreamMode},
+		{Key: ROUTE_LOOP_FIELD, Value: route.Loop},
+		{Key: ROUTE_CONTENT_TYPE_FIELD, Value: route.ContentType},
+		{Key: ROUTE_FILE_NAME_FIELD, Value: route.FileName},
+		{Key: ROUTE_FILE_SIZE_FIELD, Value: route.FileSize},
+		{Key: ROUTE_ACTIVE_FROM_FIELD, Value: route.ActiveFrom},
+		{Key: ROUTE_ACTIVE_UNTIL_FIELD, Value: route.ActiveUntil},
+		{Key: ROUTE_EXPIRE_AT_FIELD, Value: route.ExpireAt},
+		{Key: ROUTE_UPDATED_AT_FIELD, Value: time.Now()},
+	}
+}
+
 func (s *routes) updateRoute(ctx context.Context, route Route) error {
 	return util.RunWithWriteLock(&s.mutex, func() error {
 		res, err := s.coll.UpdateOne(
 			ctx,
 			bson.D{{Key: "$and", Value: bson.A{
 				bson.D{{Key: ROUTE_PATH_FIELD, Value: route.Path}},
-				bson.D{{Key: ROUTE_TYPE_FIELD, Value: route.Type}}},
+				bson.D{{Key: ROUTE_TYPE_FIELD, Value: route.Type}},
+				bson.D{notExpiredFilter(time.Now())}},
 			}},
-			bson.D{{Key: "$set", Value: bson.D{
-				{Key: ROUTE_RESPONSE_FIELD, Value: route.Response},
-				{Key: ROUTE_SCRIPT_NAME_FIELD, Value: route.ScriptName},
-			}}},
+			bson.D{{Key: "$set", Value: routeUpdateFields(&route)}},
 		)
 		if err == mongo.ErrNoDocuments || res.MatchedCount == 0 {
 			return ErrNoSuchPath
 		} else if err != nil {
 			return err
 		}
-		err = s.cache.Set(route.Path, route)
-		return err
+		// route may have fields which are not managed by update (policies)
+		s.cache.Remove(route.Path)
+		return nil
+	})
+}
+
+func isNilValue(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
+
+// nil value unsets the field
+func (s *routes) setRouteField(ctx context.Context, path string, field string, value interface{}) error {
+	return util.RunWithWriteLock(&s.mutex, func() error {
+		updatedAt := bson.E{Key: ROUTE_UPDATED_AT_FIELD, Value: time.Now()}
+		update := bson.D{{Key: "$set", Value: bson.D{{Key: field, Value: value}, updatedAt}}}
+		if isNilValue(value) {
+			update = bson.D{
+				{Key: "$unset", Value: bson.D{{Key: field, Value: ""}}},
+				{Key: "$set", Value: bson.D{updatedAt}},
+			}
+		}
+
+		res, err := s.coll.UpdateOne(
+			ctx,
+			bson.D{{Key: ROUTE_PATH_FIELD, Value: path}},
+			update,
+		)
+		if err != nil {
+			return err
+		}
+		if res.MatchedCount == 0 {
+			return ErrNoSuchPath
+		}
+		s.cache.Remove(path)
+		return nil
 	})
 }
 
@@ -106,7 +247,12 @@ func (s *routes) getRoute(ctx context.Context, path string) (Route, error) {
 		} else if err != nil {
 			return Route{}, err
 		}
-		return res.(Route), nil
+		route := res.(Route)
+		if route.IsExpired(time.Now()) {
+			s.cache.Remove(path)
+			return Route{}, ErrNoSuchPath
+		}
+		return route, nil
 	})
 }
 
@@ -115,7 +261,7 @@ func (r *routes) listAllRoutesPathsWithType(ctx context.Context, t string) ([]st
 		opts := options.Find()
 		opts = opts.SetSort(bson.D{{Key: "timestamp", Value: 1}})
 		opts = opts.SetProjection(bson.D{{Key: ROUTE_PATH_FIELD, Value: 1}})
-		cursor, err := r.coll.Find(ctx, bson.D{{Key: ROUTE_TYPE_FIELD, Value: t}}, opts)
+		cursor, err := r.coll.Find(ctx, bson.D{{Key: ROUTE_TYPE_FIELD, Value: t}, notExpiredFilter(time.Now())}, opts)
 		if err != nil {
 			return nil, err
 		}
@@ -130,3 +276,103 @@ func (r *routes) listAllRoutesPathsWithType(ctx context.Context, t string) ([]st
 		return paths, nil
 	})
 }
+
+// scripts of all stored routes, expired routes which are not removed yet included
+func (r *routes) listScripts(ctx context.Context) ([]string, error) {
+	return util.RunWithReadLock(&r.mutex, func() ([]string, error) {
+		opts := options.Find().SetProjection(bson.D{
+			{Key: ROUTE_SCRIPT_NAME_FIELD, Value: 1},
+			{Key: ROUTE_OPERATIONS_FIELD + "." + ROUTE_SCRIPT_NAME_FIELD, Value: 1},
+		})
+		cursor, err := r.coll.Find(ctx, bson.D{}, opts)
+		if err != nil {
+			return nil, err
+		}
+		var results = []Route{}
+		if err = cursor.All(ctx, &results); err != nil {
+			return nil, err
+		}
+
+		var scripts []string
+		for _, route := range results {
+			if route.ScriptName != "" {
+				scripts = append(scripts, route.ScriptName)
+			}
+			for _, op := range route.Operations {
+				if op.ScriptName != "" {
+					scripts = append(scripts, op.ScriptName)
+				}
+			}
+		}
+		return scripts, nil
+	})
+}
+
+func (r *routes) findRoutePaths(ctx context.Context, t string, query ListQuery) (Page[string], error) {
+	return util.RunWithReadLock(&r.mutex, func() (Page[string], error) {
+		filter := bson.D{{Key: ROUTE_TYPE_FIELD, Value: t}, notExpiredFilter(time.Now())}
+		if query.Prefix != "" {
+			filter = append(filter, prefixFilter(ROUTE_PATH_FIELD, query.Prefix))
+		}
+		if query.Search != "" {
+			filter = append(filter, searchFilter(query.Search, ROUTE_PATH_FIELD))
+		}
+
+		opts := options.Find().SetProjection(bson.D{{Key: ROUTE_PATH_FIELD, Value: 1}})
+		routes, err := findPage[Route](ctx, r.coll, filter, query, []string{ROUTE_PATH_FIELD}, opts)
+		if err != nil {
+			return Page[string]{}, err
+		}
+
+		paths := Page[string]{Items: make([]string, len(routes.Items)), NextCursor: routes.NextCursor}
+		for i, route := range routes.Items {
+			paths.Items[i] = route.Path
+		}
+		return paths, nil
+	})
+}
+
+// not expired routes of all types with details needed to present them,
+// hits include the ones not flushed yet
+func (r *routes) listRoutes(ctx context.Context, query ListQuery) (Page[Route], error) {
+	return util.RunWithReadLock(&r.mutex, func() (Page[Route], error) {
+		filter := bson.D{notExpiredFilter(time.Now())}
+		if query.Type != "" {
+			filter = append(filter, bson.E{Key: ROUTE_TYPE_FIELD, Value: query.Type})
+		}
+		if query.Prefix != "" {
+			filter = append(filter, prefixFilter(ROUTE_PATH_FIELD, query.Prefix))
+		}
+		if query.Search != "" {
+			filter = append(filter, searchFilter(query.Search, ROUTE_PATH_FIELD, ROUTE_RESPONSE_FIELD, ROUTE_PROXY_URL_FIELD))
+		}
+
+		opts := options.Find().SetProjection(bson.D{
+			{Key: ROUTE_PATH_FIELD, Value: 1},
+			{Key: ROUTE_TYPE_FIELD, Value: 1},
+			{Key: ROUTE_RESPONSE_FIELD, Value: 1},
+			{Key: ROUTE_PROXY_URL_FIELD, Value: 1},
+			{Key: ROUTE_SCRIPT_NAME_FIELD, Value: 1},
+			{Key: ROUTE_CREATED_AT_FIELD, Value: 1},
+			{Key: ROUTE_UPDATED_AT_FIELD, Value: 1},
+			{Key: ROUTE_HITS_FIELD, Value: 1},
+			{Key: ROUTE_ACTIVE_FROM_FIELD, Value: 1},
+			{Key: ROUTE_ACTIVE_UNTIL_FIELD, Value: 1},
+			{Key: ROUTE_EXPIRE_AT_FIELD, Value: 1},
+		})
+		sortFields := []string{ROUTE_PATH_FIELD, ROUTE_TYPE_FIELD, ROUTE_UPDATED_AT_FIELD, ROUTE_HITS_FIELD}
+		page, err := findPage[Route](ctx, r.coll, filter, query, sortFields, opts)
+		if err != nil {
+			return page, err
+		}
+
+		for i := range page.Items {
+			page.Items[i].Hits += r.hits.get(page.Items[i].Path)
+		}
+		return page, nil
+	})
+}
+
+func (r *routes) recordHit(path string) {
+	r.hits.record(path)
+}
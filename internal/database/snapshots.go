@@ -0,0 +1,128 @@
+package database
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/bluele/gcache"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// stored state of one document taken before change,
+// restoring it undoes whatever happened to the document since
+type Snapshot struct {
+	raw bson.Raw // nil if document did not exist
+}
+
+func (s Snapshot) Exists() bool {
+	return s.raw != nil
+}
+
+func (s Snapshot) Decode(v interface{}) error {
+	return bson.Unmarshal(s.raw, v)
+}
+
+func takeSnapshot(ctx context.Context, coll *mongo.Collection, field string, key string) (Snapshot, error) {
+	raw, err := coll.FindOne(ctx, bson.D{{Key: field, Value: key}}).DecodeBytes()
+	if err == mongo.ErrNoDocuments {
+		return Snapshot{}, nil
+	} else if err != nil {
+		return Snapshot{}, err
+	}
+	return Snapshot{raw: raw}, nil
+}
+
+// cached document is dropped, so it is loaded again from restored state
+func restoreSnapshot(
+	ctx context.Context,
+	coll *mongo.Collection,
+	mutex *sync.RWMutex,
+	cache gcache.Cache,
+	field string,
+	key string,
+	snapshot Snapshot,
+) error {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	filter := bson.D{{Key: field, Value: key}}
+	var err error
+	if snapshot.Exists() {
+		_, err = coll.ReplaceOne(ctx, filter, snapshot.raw, options.Replace().SetUpsert(true))
+	} else {
+		_, err = coll.DeleteOne(ctx, filter)
+	}
+	if err != nil {
+		return err
+	}
+	cache.Remove(key)
+	return nil
+}
+
+func (r *routes) snapshot(ctx context.Context, path string) (Snapshot, error) {
+	return takeSnapshot(ctx, r.coll, ROUTE_PATH_FIELD, path)
+}
+
+func (r *routes) restore(ctx context.Context, path string, snapshot Snapshot) error {
+	// hits counted since snapshot belong to route which is rolled back
+	r.hits.forget(path)
+	return restoreSnapshot(ctx, r.coll, &r.mutex, r.cache, ROUTE_PATH_FIELD, path, snapshot)
+}
+
+// creation time and hits of current route are kept
+func (r *routes) rollback(ctx context.Context, path string, state AuditState) error {
+	var doc bson.D
+	if err := bson.Unmarshal(state, &doc); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	filter := bson.D{{Key: ROUTE_PATH_FIELD, Value: path}}
+	var current Route
+	err := r.coll.FindOne(ctx, filter).Decode(&current)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return err
+	}
+
+	now := time.Now()
+	createdAt := current.CreatedAt
+	if createdAt == nil {
+		createdAt = &now
+	}
+	doc = append(doc,
+		bson.E{Key: ROUTE_CREATED_AT_FIELD, Value: createdAt},
+		bson.E{Key: ROUTE_UPDATED_AT_FIELD, Value: now},
+		bson.E{Key: ROUTE_HITS_FIELD, Value: current.Hits},
+	)
+
+	if _, err := r.coll.ReplaceOne(ctx, filter, doc, options.Replace().SetUpsert(true)); err != nil {
+		return err
+	}
+	r.cache.Remove(path)
+	return nil
+}
+
+func (mp *messagePools) snapshot(ctx context.Context, name string) (Snapshot, error) {
+	return takeSnapshot(ctx, mp.coll, MESSAGE_POOL_NAME, name)
+}
+
+func (mp *messagePools) restore(ctx context.Context, name string, snapshot Snapshot) error {
+	return restoreSnapshot(ctx, mp.coll, &mp.mutex, mp.cache, MESSAGE_POOL_NAME, name, snapshot)
+}
+
+func (esb *esbRecords) snapshot(ctx context.Context, poolNameIn string) (Snapshot, error) {
+	return takeSnapshot(ctx, esb.coll, POOL_NAME_IN_FIELD, poolNameIn)
+}
+
+func (esb *esbRecords) restore(ctx context.Context, poolNameIn string, snapshot Snapshot) error {
+	return restoreSnapshot(ctx, esb.coll, &esb.mutex, esb.cache, POOL_NAME_IN_FIELD, poolNameIn, snapshot)
+}
+
+func (ns *namespaces) snapshot(ctx context.Context, prefix string) (Snapshot, error) {
+	return takeSnapshot(ctx, ns.coll, NAMESPACE_PREFIX_FIELD, prefix)
+}
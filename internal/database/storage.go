@@ -0,0 +1,144 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"mock-server/internal/configs"
+)
+
+// Storage keeps mock-server state: MongoStorage in mongo deployment,
+// MemoryStorage in process memory
+type Storage interface {
+	Ping(ctx context.Context) error
+	// safe to call again
+	Disconnect(ctx context.Context) error
+	TransactionsSupported() bool
+	RunInTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+
+	collections() collections
+}
+
+// collections of storage, mongo and memory ones behave the same
+type collections struct {
+	routes       routeStore
+	taskMessages taskMessageStore
+	esbRecords   esbRecordStore
+	messagePools messagePoolStore
+	namespaces   namespaceStore
+	auditLog     auditLogStore
+	revisions    revisionStore
+}
+
+type routeStore interface {
+	addRoute(ctx context.Context, route Route) error
+	removeRoute(ctx context.Context, path string) error
+	updateRoute(ctx context.Context, route Route) error
+	// nil value unsets the field
+	setRouteField(ctx context.Context, path string, field string, value interface{}) error
+	getRoute(ctx context.Context, path string) (Route, error)
+	listAllRoutesPathsWithType(ctx context.Context, t string) ([]string, error)
+	// scripts of dynamic, websocket and graphql routes which are stored
+	listScripts(ctx context.Context) ([]string, error)
+	findRoutePaths(ctx context.Context, t string, query ListQuery) (Page[string], error)
+	// not expired routes of all types
+	listRoutes(ctx context.Context, query ListQuery) (Page[Route], error)
+	recordHit(path string)
+	snapshotStore
+	// replaces route with revision state, creation time and hits are kept
+	rollback(ctx context.Context, path string, state AuditState) error
+}
+
+// stored state of documents by their key, used to undo changes
+type snapshotStore interface {
+	snapshot(ctx context.Context, key string) (Snapshot, error)
+	// removes document if snapshot is taken before it existed
+	restore(ctx context.Context, key string, snapshot Snapshot) error
+}
+
+type taskMessageStore interface {
+	addTaskMessage(ctx context.Context, taskMessage TaskMessage) error
+	getTaskMessages(ctx context.Context, taskId string) ([]string, error)
+	findTaskMessages(ctx context.Context, taskId string, query ListQuery) (Page[string], error)
+}
+
+type esbRecordStore interface {
+	addESBRecord(ctx context.Context, esbRecord ESBRecord) error
+	removeESBRecord(ctx context.Context, poolNameIn string) error
+	updateESBRecord(ctx context.Context, esbRecord ESBRecord) error
+	getESBRecord(ctx context.Context, poolNameIn string) (ESBRecord, error)
+	listESBRecords(ctx context.Context) ([]ESBRecord, error)
+	findESBRecords(ctx context.Context, query ListQuery) (Page[ESBRecord], error)
+	snapshotStore
+}
+
+type messagePoolStore interface {
+	addMessagePool(ctx context.Context, messagePool MessagePool) error
+	removeMessagePool(ctx context.Context, name string) error
+	getMessagePool(ctx context.Context, name string) (MessagePool, error)
+	listMessagePools(ctx context.Context) ([]MessagePool, error)
+	findMessagePools(ctx context.Context, query ListQuery) (Page[MessagePool], error)
+	snapshotStore
+}
+
+type namespaceStore interface {
+	addNamespace(ctx context.Context, namespace Namespace) error
+	removeNamespace(ctx context.Context, prefix string) error
+	updateNamespace(ctx context.Context, namespace Namespace) error
+	// ordered by prefix
+	listNamespaces(ctx context.Context) ([]Namespace, error)
+	snapshot(ctx context.Context, prefix string) (Snapshot, error)
+}
+
+type auditLogStore interface {
+	addRecord(ctx context.Context, record AuditRecord) error
+	findRecords(ctx context.Context, query AuditQuery) (Page[AuditRecord], error)
+}
+
+type revisionStore interface {
+	// returns stored revision, which is the latest one if definition is not changed
+	addRevision(ctx context.Context, revision Revision) (Revision, error)
+	getRevision(ctx context.Context, resource string, key string, number int64) (Revision, error)
+	findRevisions(ctx context.Context, resource string, key string, query ListQuery) (Page[Revision], error)
+}
+
+// storage and its collections
+type boundStorage struct {
+	Storage
+	collections
+}
+
+func bindStorage(storage Storage) *boundStorage {
+	return &boundStorage{Storage: storage, collections: storage.collections()}
+}
+
+// storage of process, set by InitDB
+var db *boundStorage
+
+type storageKey struct{}
+
+// operations with returned context use given storage instead of storage of process,
+// so servers embedded in one process keep their own state
+func WithStorage(ctx context.Context, storage Storage) context.Context {
+	return context.WithValue(ctx, storageKey{}, bindStorage(storage))
+}
+
+func dbOf(ctx context.Context) *boundStorage {
+	if storage, ok := ctx.Value(storageKey{}).(*boundStorage); ok {
+		return storage
+	}
+	return db
+}
+
+// creates storage selected by config, storage of process is not changed
+func NewStorage(ctx context.Context, cfg *configs.DatabaseConfig) (Storage, error) {
+	switch cfg.Storage {
+	case configs.DATABASE_STORAGE_MEMORY:
+		return NewMemoryStorage(), nil
+	case "", configs.DATABASE_STORAGE_MONGO:
+		if cfg.InMemory {
+			return initInMemoryDB(ctx, cfg)
+		}
+		return initExternalDB(ctx, cfg)
+	}
+	return nil, fmt.Errorf("unknown database storage %q", cfg.Storage)
+}
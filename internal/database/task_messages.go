@@ -20,8 +20,14 @@ func createTaskMessages(ctx context.Context, client *mongo.Client, cfg *configs.
 }
 
 func (s *taskMessages) init(ctx context.Context, client *mongo.Client, cfg *configs.DatabaseConfig) error {
-	s.coll = client.Database(DATABASE_NAME).Collection(TASK_MESSAGES_COLLECTION)
-	return nil
+	s.coll = client.Database(databaseName(cfg)).Collection(TASK_MESSAGES_COLLECTION)
+
+	// messages of pool task are listed page by page in insertion order
+	indexModel := mongo.IndexModel{
+		Keys: bson.D{{Key: TASK_ID_FIELD, Value: 1}, {Key: ID_FIELD, Value: 1}},
+	}
+	_, err := s.coll.Indexes().CreateOne(ctx, indexModel)
+	return err
 }
 
 func (s *taskMessages) addTaskMessage(ctx context.Context, taskMessage TaskMessage) error {
@@ -59,3 +65,23 @@ func (s *taskMessages) getTaskMessages(ctx context.Context, taskId string) ([]st
 	}
 	return messages, nil
 }
+
+// messages of task in the order they were stored, unless other sort is requested
+func (s *taskMessages) findTaskMessages(ctx context.Context, taskId string, query ListQuery) (Page[string], error) {
+	filter := bson.D{{Key: TASK_ID_FIELD, Value: taskId}}
+	if query.Search != "" {
+		filter = append(filter, searchFilter(query.Search, MESSAGE_FIELD))
+	}
+
+	opts := options.Find().SetProjection(bson.D{{Key: MESSAGE_FIELD, Value: 1}})
+	taskMessages, err := findPage[TaskMessage](ctx, s.coll, filter, query, []string{MESSAGE_FIELD}, opts)
+	if err != nil {
+		return Page[string]{}, err
+	}
+
+	messages := Page[string]{Items: make([]string, len(taskMessages.Items)), NextCursor: taskMessages.NextCursor}
+	for i, taskMessage := range taskMessages.Items {
+		messages.Items[i] = taskMessage.Message
+	}
+	return messages, nil
+}
@@ -0,0 +1,61 @@
+package database
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// transactions need replica set or sharded cluster,
+// standalone server rejects them
+func detectTransactions(ctx context.Context, client *mongo.Client) (bool, error) {
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello)
+	if err != nil {
+		return false, err
+	}
+	return hello.SetName != "" || hello.Msg == "isdbgrid", nil
+}
+
+func TransactionsSupported(ctx context.Context) bool {
+	return dbOf(ctx).TransactionsSupported()
+}
+
+// runs fn in transaction if storage supports them, otherwise just runs fn,
+// operations join transaction only if they are given context passed to fn;
+// transaction is aborted if fn returns error and is not retried
+func RunInTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return dbOf(ctx).RunInTransaction(ctx, fn)
+}
+
+func (db *MongoStorage) TransactionsSupported() bool {
+	return db.transactions
+}
+
+func (db *MongoStorage) RunInTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if !db.transactions {
+		return fn(ctx)
+	}
+
+	session, err := db.client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(context.Background())
+
+	if err := session.StartTransaction(); err != nil {
+		return err
+	}
+
+	sessionCtx := mongo.NewSessionContext(ctx, session)
+	if err := fn(sessionCtx); err != nil {
+		// context of failed request may be cancelled already
+		session.AbortTransaction(context.Background())
+		return err
+	}
+	return session.CommitTransaction(sessionCtx)
+}
@@ -0,0 +1,123 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "mock_server"
+
+// route label of requests that did not match any mock route (or failed to look it up),
+// path is not used to keep cardinality bounded
+const UNMATCHED_ROUTE = "unmatched"
+
+const (
+	OUTCOME_SUCCESS      = "success"
+	OUTCOME_ERROR        = "error"
+	OUTCOME_TIMEOUT      = "timeout"
+	OUTCOME_SCRIPT_ERROR = "script_error"
+	// write task dropped by chaos profile
+	OUTCOME_DROPPED = "dropped"
+)
+
+const (
+	QUEUE_READ  = "read"
+	QUEUE_WRITE = "write"
+)
+
+const (
+	DIRECTION_READ    = "read"
+	DIRECTION_WRITTEN = "written"
+)
+
+var (
+	mockRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "mock_requests_total",
+		Help:      "Requests to mock routes by route, route type and response status.",
+	}, []string{"route", "type", "status"})
+
+	mockRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "mock_request_duration_seconds",
+		Help:      "Latency of mock route requests, for websocket and stream routes it is the connection lifetime.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "type"})
+
+	proxyUpstreamErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "proxy_upstream_errors_total",
+		Help:      "Failed requests of proxy routes to their upstream.",
+	}, []string{"route"})
+
+	coderunBorrowWait = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "coderun_borrow_wait_seconds",
+		Help:      "Time spent waiting for a free coderun worker.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"outcome"})
+
+	coderunScriptDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "coderun_script_duration_seconds",
+		Help:      "Duration of script runs in coderun workers.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"run_type", "outcome"})
+
+	schedulerQueueDepth = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "broker_scheduler_queue_depth",
+		Help:      "Broker tasks waiting in scheduler queues.",
+	}, []string{"queue"})
+
+	schedulerTasks = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "broker_scheduler_tasks_total",
+		Help:      "Finished broker tasks by queue and outcome.",
+	}, []string{"queue", "outcome"})
+
+	brokerMessages = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "broker_messages_total",
+		Help:      "Messages read from and written to message pools.",
+	}, []string{"pool", "broker", "direction"})
+)
+
+func ObserveMockRequest(route string, routeType string, status int, duration time.Duration) {
+	mockRequests.WithLabelValues(route, routeType, strconv.Itoa(status)).Inc()
+	mockRequestDuration.WithLabelValues(route, routeType).Observe(duration.Seconds())
+}
+
+func IncProxyUpstreamError(route string) {
+	proxyUpstreamErrors.WithLabelValues(route).Inc()
+}
+
+func ObserveCoderunBorrow(outcome string, wait time.Duration) {
+	coderunBorrowWait.WithLabelValues(outcome).Observe(wait.Seconds())
+}
+
+func ObserveCoderunScript(runType string, outcome string, duration time.Duration) {
+	coderunScriptDuration.WithLabelValues(runType, outcome).Observe(duration.Seconds())
+}
+
+func SetSchedulerQueueDepth(queue string, depth int) {
+	schedulerQueueDepth.WithLabelValues(queue).Set(float64(depth))
+}
+
+func IncSchedulerTask(queue string, outcome string) {
+	schedulerTasks.WithLabelValues(queue, outcome).Inc()
+}
+
+func IncBrokerMessages(pool string, broker string, direction string) {
+	brokerMessages.WithLabelValues(pool, broker, direction).Inc()
+}
+
+// exposes all registered metrics including go runtime and process ones
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
@@ -0,0 +1,187 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"mock-server/internal/coderun"
+	"mock-server/internal/database"
+	"mock-server/internal/server/protocol"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// admin api with response envelope, see apiV2Middleware
+const API_V2_PREFIX = "/api/v2/"
+
+// error code of response by error of handler,
+// status decides when error is missing or not known
+func apiErrorCode(err error, status int) string {
+	var validationErrs validator.ValidationErrors
+	var batchErr *batchError
+
+	switch {
+	case errors.Is(err, database.ErrNoSuchPath):
+		return protocol.ERR_CODE_ROUTE_NOT_FOUND
+	case errors.Is(err, database.ErrBadRouteType):
+		return protocol.ERR_CODE_ROUTE_TYPE_MISMATCH
+	case errors.Is(err, database.ErrNoSuchNamespace):
+		return protocol.ERR_CODE_NAMESPACE_NOT_FOUND
+	case errors.Is(err, database.ErrNoSuchPool):
+		return protocol.ERR_CODE_POOL_NOT_FOUND
+	case errors.Is(err, database.ErrNoSuchRecord):
+		return protocol.ERR_CODE_RECORD_NOT_FOUND
+	case errors.Is(err, database.ErrNoSuchRevision):
+		return protocol.ERR_CODE_REVISION_NOT_FOUND
+	case errors.Is(err, database.ErrDuplicateKey):
+		return protocol.ERR_CODE_ALREADY_EXISTS
+	case errors.Is(err, database.ErrInvalidCursor):
+		return protocol.ERR_CODE_INVALID_CURSOR
+	case errors.Is(err, database.ErrInvalidSort):
+		return protocol.ERR_CODE_INVALID_SORT
+	case errors.Is(err, coderun.ErrCodeRunFailed):
+		return protocol.ERR_CODE_CODERUN_FAILED
+	case errors.Is(err, coderun.ErrWorkerFailed):
+		return protocol.ERR_CODE_WORKER_FAILED
+	case errors.Is(err, coderun.ErrNoRunningWorker):
+		return protocol.ERR_CODE_NO_RUNNING_WORKER
+	case errors.As(err, &validationErrs):
+		return protocol.ERR_CODE_VALIDATION_FAILED
+	case errors.As(err, &batchErr):
+		return protocol.ERR_CODE_BATCH_FAILED
+	}
+
+	switch status {
+	case http.StatusBadRequest:
+		return protocol.ERR_CODE_BAD_REQUEST
+	case http.StatusUnauthorized:
+		return protocol.ERR_CODE_UNAUTHORIZED
+	case http.StatusForbidden:
+		return protocol.ERR_CODE_FORBIDDEN
+	case http.StatusNotFound:
+		return protocol.ERR_CODE_NOT_FOUND
+	case http.StatusConflict:
+		return protocol.ERR_CODE_ALREADY_EXISTS
+	case http.StatusInternalServerError:
+		return protocol.ERR_CODE_INTERNAL
+	default:
+		return strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_")
+	}
+}
+
+func apiErrorDetails(err error) interface{} {
+	var batchErr *batchError
+	if errors.As(err, &batchErr) {
+		return batchErr.results
+	}
+
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return nil
+	}
+
+	fields := make([]protocol.FieldError, 0, len(validationErrs))
+	for _, fieldErr := range validationErrs {
+		fields = append(fields, protocol.FieldError{
+			Field: fieldErr.Field(),
+			Rule:  fieldErr.Tag(),
+			Param: fieldErr.Param(),
+		})
+	}
+	return fields
+}
+
+// message of v1 error body, which is either {"error": "..."} or bare string
+func apiErrorMessage(body []byte, status int) string {
+	var withError struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &withError); err == nil && withError.Error != "" {
+		return withError.Error
+	}
+
+	var message string
+	if err := json.Unmarshal(body, &message); err == nil && message != "" {
+		return message
+	}
+
+	return http.StatusText(status)
+}
+
+// whether request is served under v2 prefix
+func isApiV2(c *gin.Context) bool {
+	return strings.HasPrefix(c.FullPath(), API_V2_PREFIX)
+}
+
+// responds with error of admin handler: {"error": message} under v1 and
+// envelope with code of err under v2; err may be nil, status decides code then
+func apiError(c *gin.Context, status int, err error, message string) {
+	apiErrorWithBody(c, status, err, message, gin.H{"error": message})
+}
+
+// like apiError, but v1 response has given body
+func apiErrorWithBody(c *gin.Context, status int, err error, message string, v1Body interface{}) {
+	if !isApiV2(c) {
+		c.AbortWithStatusJSON(status, v1Body)
+		return
+	}
+
+	c.AbortWithStatusJSON(status, protocol.ApiResponse{Error: &protocol.ApiError{
+		Code:    apiErrorCode(err, status),
+		Message: message,
+		Details: apiErrorDetails(err),
+	}})
+}
+
+// places json body of successful response into data field as it is written,
+// error responses are written as envelope by apiError already
+type apiV2ResponseWriter struct {
+	gin.ResponseWriter
+	opened bool // data field is written
+}
+
+// header is written with body or at the end of request, so bind errors
+// of gin, which write header early, still get content type of envelope
+func (w *apiV2ResponseWriter) WriteHeaderNow() {}
+
+func (w *apiV2ResponseWriter) Write(data []byte) (int, error) {
+	if !w.opened && w.Status() < http.StatusBadRequest &&
+		strings.HasPrefix(w.Header().Get("Content-Type"), gin.MIMEJSON) {
+		if _, err := w.ResponseWriter.WriteString(`{"data":`); err != nil {
+			return 0, err
+		}
+		w.opened = true
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *apiV2ResponseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// finishes response after handlers
+func (w *apiV2ResponseWriter) close() {
+	if w.opened {
+		w.ResponseWriter.WriteString("}")
+		return
+	}
+	if w.Status() == http.StatusNoContent {
+		w.Header().Del("Content-Type")
+	}
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+// serves v1 handlers under v2 prefix: json bodies of successful responses
+// are placed into data field and 204 responses have no body, errors are
+// written as envelope by apiError
+func apiV2Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		writer := &apiV2ResponseWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+		writer.close()
+	}
+}
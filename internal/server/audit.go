@@ -0,0 +1,275 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mock-server/internal/brokers"
+	"mock-server/internal/database"
+	"mock-server/internal/server/protocol"
+	"mock-server/internal/util"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	zlog "github.com/rs/zerolog/log"
+)
+
+// admin api endpoint changing a resource
+type auditedEndpoint struct {
+	resource  string
+	operation string
+	// resource key is taken either from json body or multipart form field or from query param
+	bodyKey    string
+	queryParam string
+}
+
+// by method and path relative to admin api group
+func auditedEndpoints() map[string]auditedEndpoint {
+	endpoints := map[string]auditedEndpoint{
+		"PUT /routes/policy/cors":          {resource: database.AUDIT_RESOURCE_ROUTE, operation: database.AUDIT_OP_UPDATE, bodyKey: "path"},
+		"DELETE /routes/policy/cors":       {resource: database.AUDIT_RESOURCE_ROUTE, operation: database.AUDIT_OP_UPDATE, queryParam: "path"},
+		"PUT /routes/policy/rate_limit":    {resource: database.AUDIT_RESOURCE_ROUTE, operation: database.AUDIT_OP_UPDATE, bodyKey: "path"},
+		"DELETE /routes/policy/rate_limit": {resource: database.AUDIT_RESOURCE_ROUTE, operation: database.AUDIT_OP_UPDATE, queryParam: "path"},
+
+		"POST /namespaces":   {resource: database.AUDIT_RESOURCE_NAMESPACE, operation: database.AUDIT_OP_CREATE, bodyKey: "prefix"},
+		"PUT /namespaces":    {resource: database.AUDIT_RESOURCE_NAMESPACE, operation: database.AUDIT_OP_UPDATE, bodyKey: "prefix"},
+		"DELETE /namespaces": {resource: database.AUDIT_RESOURCE_NAMESPACE, operation: database.AUDIT_OP_DELETE, queryParam: "prefix"},
+
+		"POST /brokers/pool":   {resource: database.AUDIT_RESOURCE_POOL, operation: database.AUDIT_OP_CREATE, bodyKey: "pool_name"},
+		"DELETE /brokers/pool": {resource: database.AUDIT_RESOURCE_POOL, operation: database.AUDIT_OP_DELETE, queryParam: "pool"},
+
+		"POST /brokers/esb":   {resource: database.AUDIT_RESOURCE_ESB, operation: database.AUDIT_OP_CREATE, bodyKey: "pool_name_in"},
+		"PUT /brokers/esb":    {resource: database.AUDIT_RESOURCE_ESB, operation: database.AUDIT_OP_UPDATE, bodyKey: "pool_name_in"},
+		"DELETE /brokers/esb": {resource: database.AUDIT_RESOURCE_ESB, operation: database.AUDIT_OP_DELETE, queryParam: "pool_in"},
+	}
+
+	for _, kind := range []string{"static", "proxy", "dynamic", "graphql", "websocket", "stream", "file"} {
+		path := "/routes/" + kind
+		endpoints["POST "+path] = auditedEndpoint{resource: database.AUDIT_RESOURCE_ROUTE, operation: database.AUDIT_OP_CREATE, bodyKey: "path"}
+		endpoints["PUT "+path] = auditedEndpoint{resource: database.AUDIT_RESOURCE_ROUTE, operation: database.AUDIT_OP_UPDATE, bodyKey: "path"}
+		endpoints["DELETE "+path] = auditedEndpoint{resource: database.AUDIT_RESOURCE_ROUTE, operation: database.AUDIT_OP_DELETE, queryParam: "path"}
+	}
+	return endpoints
+}
+
+// empty if key is missing, then handler rejects the request itself
+func auditedKey(c *gin.Context, endpoint *auditedEndpoint) string {
+	if endpoint.queryParam != "" {
+		return c.Query(endpoint.queryParam)
+	}
+
+	// multipart form stays parsed for handler
+	if c.ContentType() == gin.MIMEMultipartPOSTForm {
+		return c.PostForm(endpoint.bodyKey)
+	}
+
+	// body is read once more by handler
+	data, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return ""
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(data))
+
+	var fields map[string]json.RawMessage
+	var key string
+	if json.Unmarshal(data, &fields) != nil || json.Unmarshal(fields[endpoint.bodyKey], &key) != nil {
+		return ""
+	}
+	return key
+}
+
+// stored resource and user code of its dynamic handler or esb mapper,
+// code is empty if resource has no script
+func (s *server) resourceState(ctx context.Context, resource string, key string) (database.Snapshot, string, error) {
+	var snapshot database.Snapshot
+	var err error
+
+	switch resource {
+	case database.AUDIT_RESOURCE_ROUTE:
+		snapshot, err = database.SnapshotRoute(ctx, key)
+		if err != nil || !snapshot.Exists() {
+			return snapshot, "", err
+		}
+		var route database.Route
+		if err := snapshot.Decode(&route); err != nil || route.Type != database.DYNAMIC_ENDPOINT_TYPE {
+			return snapshot, "", err
+		}
+		code, err := s.fs.Read(FS_DYN_HANDLE_DIR, route.ScriptName)
+		if err != nil {
+			return snapshot, "", err
+		}
+		return snapshot, util.UnwrapCodeForDynHandle(code), nil
+	case database.AUDIT_RESOURCE_POOL:
+		snapshot, err = database.SnapshotMessagePool(ctx, key)
+	case database.AUDIT_RESOURCE_ESB:
+		snapshot, err = database.SnapshotESBRecord(ctx, key)
+		if err != nil || !snapshot.Exists() {
+			return snapshot, "", err
+		}
+		var record database.ESBRecord
+		if err := snapshot.Decode(&record); err != nil || record.MapperScriptName == brokers.EMPTY_MAPPER {
+			return snapshot, "", err
+		}
+		code, err := s.fs.Read(FS_ESB_DIR, record.MapperScriptName)
+		if err != nil {
+			return snapshot, "", err
+		}
+		return snapshot, util.UnwrapCodeForEsb(code), nil
+	case database.AUDIT_RESOURCE_NAMESPACE:
+		snapshot, err = database.SnapshotNamespace(ctx, key)
+	}
+	return snapshot, "", err
+}
+
+func newAuditState(snapshot database.Snapshot, code string) (database.AuditState, error) {
+	extra := make(map[string]interface{})
+	if code != "" {
+		extra["code"] = code
+	}
+	return database.NewAuditState(snapshot, extra)
+}
+
+// stored resource with code of its script, nil if resource does not exist
+func (s *server) auditState(ctx context.Context, resource string, key string) (database.AuditState, error) {
+	snapshot, code, err := s.resourceState(ctx, resource, key)
+	if err != nil {
+		return nil, err
+	}
+	return newAuditState(snapshot, code)
+}
+
+// records change in audit log, resource state after change is read from storage,
+// routes and esb records which exist after change also get new revision
+func (s *server) recordChange(c *gin.Context, record database.AuditRecord) {
+	snapshot, code, err := s.resourceState(c, record.Resource, record.Key)
+	if err == nil {
+		record.After, err = newAuditState(snapshot, code)
+	}
+	if err != nil {
+		zlog.Error().Err(err).Str("resource", record.Resource).Str("key", record.Key).Msg("Failed to get changed state")
+	}
+
+	record.Timestamp = time.Now()
+	record.Actor = c.GetString(AUTH_IDENTITY_KEY)
+	record.IP = c.ClientIP()
+	record.Method = c.Request.Method
+	record.Path = c.Request.URL.Path
+
+	if err := database.AddAuditRecord(c, record); err != nil {
+		zlog.Error().Err(err).Str("resource", record.Resource).Str("key", record.Key).Msg("Failed to add audit record")
+	}
+
+	if !revisionedResources[record.Resource] || !snapshot.Exists() {
+		return
+	}
+	if err := s.addRevision(c, record.Resource, record.Key, snapshot, code); err != nil {
+		zlog.Error().Err(err).Str("resource", record.Resource).Str("key", record.Key).Msg("Failed to add revision")
+	}
+}
+
+// records successful changes of resources made through admin api under basePath,
+// recording failure does not fail the change
+func (s *server) auditMiddleware(basePath string) gin.HandlerFunc {
+	endpoints := auditedEndpoints()
+
+	return func(c *gin.Context) {
+		endpoint, ok := endpoints[c.Request.Method+" "+strings.TrimPrefix(c.FullPath(), basePath)]
+		if !ok {
+			c.Next()
+			return
+		}
+		key := auditedKey(c, &endpoint)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		before, err := s.auditState(c, endpoint.resource, key)
+		if err != nil {
+			zlog.Error().Err(err).Str("resource", endpoint.resource).Str("key", key).Msg("Failed to get audited state")
+		}
+
+		c.Next()
+
+		status := c.Writer.Status()
+		if status >= http.StatusBadRequest {
+			return
+		}
+
+		s.recordChange(c, database.AuditRecord{
+			Operation: endpoint.operation,
+			Resource:  endpoint.resource,
+			Key:       key,
+			Status:    status,
+			Before:    before,
+		})
+	}
+}
+
+func toProtocolAuditRecord(record *database.AuditRecord) (protocol.AuditRecord, error) {
+	before, err := database.AuditStateJSON(record.Before)
+	if err != nil {
+		return protocol.AuditRecord{}, err
+	}
+	after, err := database.AuditStateJSON(record.After)
+	if err != nil {
+		return protocol.AuditRecord{}, err
+	}
+
+	return protocol.AuditRecord{
+		Id:        record.Id.Hex(),
+		Timestamp: record.Timestamp,
+		Actor:     record.Actor,
+		IP:        record.IP,
+		Method:    record.Method,
+		Path:      record.Path,
+		Operation: record.Operation,
+		Resource:  record.Resource,
+		Key:       record.Key,
+		Status:    record.Status,
+		Before:    before,
+		After:     after,
+	}, nil
+}
+
+func (s *server) initAuditApi(admin *gin.RouterGroup) {
+	admin.GET("/audit", func(c *gin.Context) {
+		var query protocol.AuditQuery
+		if err := c.BindQuery(&query); err != nil {
+			zlog.Error().Err(err).Msg("Invalid audit query")
+			apiError(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
+
+		zlog.Info().Str("resource", query.Resource).Str("key", query.Key).Msg("Audit log request")
+
+		page, err := database.FindAuditRecords(c, database.AuditQuery{
+			ListQuery: toDatabaseListQuery(c, &query.ListQuery),
+			Resource:  query.Resource,
+			Key:       query.Key,
+			Actor:     query.Actor,
+			Since:     query.Since,
+			Until:     query.Until,
+		})
+		if err != nil {
+			listPageError(c, err)
+			return
+		}
+
+		records := make([]protocol.AuditRecord, 0, len(page.Items))
+		for i := range page.Items {
+			record, err := toProtocolAuditRecord(&page.Items[i])
+			if err != nil {
+				zlog.Error().Err(err).Msg("Failed to convert audit record")
+				apiError(c, http.StatusInternalServerError, err, err.Error())
+				return
+			}
+			records = append(records, record)
+		}
+
+		c.JSON(http.StatusOK, pageResponse("records", records, page.NextCursor))
+	})
+}
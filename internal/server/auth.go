@@ -0,0 +1,148 @@
+package server
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"mock-server/internal/configs"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	zlog "github.com/rs/zerolog/log"
+)
+
+// gin context keys of authenticated caller
+const (
+	AUTH_IDENTITY_KEY = "auth_identity"
+	AUTH_ROLE_KEY     = "auth_role"
+)
+
+type authCredential struct {
+	identity string
+	role     string
+}
+
+type authenticator struct {
+	tokens map[string]authCredential // by token
+	users  map[string]string         // password by username
+	roles  map[string]string         // role by username
+}
+
+func validateAuthRole(role string) error {
+	switch role {
+	case configs.AUTH_ROLE_READ_ONLY, configs.AUTH_ROLE_ADMIN:
+		return nil
+	default:
+		return fmt.Errorf("unknown auth role: %s", role)
+	}
+}
+
+func newAuthenticator(cfg *configs.AuthConfig) (*authenticator, error) {
+	a := &authenticator{
+		tokens: make(map[string]authCredential),
+		users:  make(map[string]string),
+		roles:  make(map[string]string),
+	}
+
+	for i, token := range cfg.Tokens {
+		if token.Token == "" {
+			return nil, fmt.Errorf("auth token #%d is empty", i)
+		}
+		if err := validateAuthRole(token.Role); err != nil {
+			return nil, err
+		}
+		identity := token.Name
+		if identity == "" {
+			identity = fmt.Sprintf("token #%d", i)
+		}
+		a.tokens[token.Token] = authCredential{identity: identity, role: token.Role}
+	}
+
+	for _, user := range cfg.Users {
+		if user.Username == "" {
+			return nil, errors.New("auth user without username")
+		}
+		if err := validateAuthRole(user.Role); err != nil {
+			return nil, err
+		}
+		a.users[user.Username] = user.Password
+		a.roles[user.Username] = user.Role
+	}
+
+	return a, nil
+}
+
+func secureCompare(a string, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// returns nil if credentials are missing or invalid
+func (a *authenticator) authenticate(r *http.Request) *authCredential {
+	if header := r.Header.Get("Authorization"); strings.HasPrefix(header, "Bearer ") {
+		token := strings.TrimPrefix(header, "Bearer ")
+		for known, cred := range a.tokens {
+			if secureCompare(known, token) {
+				return &cred
+			}
+		}
+		return nil
+	}
+
+	if username, password, ok := r.BasicAuth(); ok {
+		expected, found := a.users[username]
+		if found && secureCompare(expected, password) {
+			return &authCredential{identity: username, role: a.roles[username]}
+		}
+	}
+	return nil
+}
+
+func authRoleAllows(role string, method string) bool {
+	if role == configs.AUTH_ROLE_ADMIN {
+		return true
+	}
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
+
+// protects admin api, read only role is allowed to use only safe methods
+func newAuthMiddleware(cfg *configs.AuthConfig) gin.HandlerFunc {
+	if cfg == nil {
+		return func(c *gin.Context) {}
+	}
+
+	a, err := newAuthenticator(cfg)
+	if err != nil {
+		panic(err)
+	}
+
+	return func(c *gin.Context) {
+		cred := a.authenticate(c.Request)
+		if cred == nil {
+			zlog.Warn().Str("path", c.Request.URL.Path).Msg("Unauthorized admin api request")
+			c.Header("WWW-Authenticate", `Basic realm="mock-server"`)
+			apiError(c, http.StatusUnauthorized, nil, "authentication required")
+			return
+		}
+
+		if !authRoleAllows(cred.role, c.Request.Method) {
+			zlog.Warn().
+				Str("identity", cred.identity).
+				Str("role", cred.role).
+				Str("method", c.Request.Method).
+				Str("path", c.Request.URL.Path).
+				Msg("Forbidden admin api request")
+			apiError(c, http.StatusForbidden, nil, "insufficient role")
+			return
+		}
+
+		c.Set(AUTH_IDENTITY_KEY, cred.identity)
+		c.Set(AUTH_ROLE_KEY, cred.role)
+		c.Next()
+	}
+}
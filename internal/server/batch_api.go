@@ -0,0 +1,327 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mock-server/internal/brokers"
+	"mock-server/internal/database"
+	"mock-server/internal/server/protocol"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+	zlog "github.com/rs/zerolog/log"
+)
+
+var errBatchOperationFailed = errors.New("batch operation failed")
+
+// error of failed batch, v2 api reports results as error details
+type batchError struct {
+	results []protocol.BatchResult
+}
+
+func (e *batchError) Error() string {
+	return "batch is not applied"
+}
+
+// admin api endpoint serving one batch operation
+type batchTarget struct {
+	kind   string
+	method string
+	path   string
+	query  url.Values
+	key    string
+	body   []byte
+}
+
+// how objects of batch kind are addressed by their admin api
+type batchKindApi struct {
+	path string
+	// field of create and update body holding object key
+	bodyKey string
+	// query param of delete holding object key
+	deleteParam string
+	updatable   bool
+}
+
+var batchKindApis = map[string]batchKindApi{
+	protocol.BATCH_KIND_STATIC:    {path: "/api/routes/static", bodyKey: "path", deleteParam: "path", updatable: true},
+	protocol.BATCH_KIND_PROXY:     {path: "/api/routes/proxy", bodyKey: "path", deleteParam: "path", updatable: true},
+	protocol.BATCH_KIND_DYNAMIC:   {path: "/api/routes/dynamic", bodyKey: "path", deleteParam: "path", updatable: true},
+	protocol.BATCH_KIND_GRAPHQL:   {path: "/api/routes/graphql", bodyKey: "path", deleteParam: "path", updatable: true},
+	protocol.BATCH_KIND_WEBSOCKET: {path: "/api/routes/websocket", bodyKey: "path", deleteParam: "path", updatable: true},
+	protocol.BATCH_KIND_STREAM:    {path: "/api/routes/stream", bodyKey: "path", deleteParam: "path", updatable: true},
+	protocol.BATCH_KIND_POOL:      {path: "/api/brokers/pool", bodyKey: "pool_name", deleteParam: "pool"},
+	protocol.BATCH_KIND_ESB:       {path: "/api/brokers/esb", bodyKey: "pool_name_in", deleteParam: "pool_in", updatable: true},
+}
+
+func newBatchTarget(op *protocol.BatchOperation) (*batchTarget, error) {
+	kindApi := batchKindApis[op.Kind]
+	target := &batchTarget{kind: op.Kind, path: kindApi.path}
+
+	if op.Op == protocol.BATCH_OP_DELETE {
+		if op.Key == "" {
+			return nil, errors.New("key required for delete")
+		}
+		target.method = http.MethodDelete
+		target.key = op.Key
+		target.query = url.Values{kindApi.deleteParam: {op.Key}}
+		return target, nil
+	}
+
+	if op.Op == protocol.BATCH_OP_UPDATE && !kindApi.updatable {
+		return nil, fmt.Errorf("%s does not support update", op.Kind)
+	}
+	target.method = http.MethodPost
+	if op.Op == protocol.BATCH_OP_UPDATE {
+		target.method = http.MethodPut
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(op.Body, &fields); err != nil {
+		return nil, errors.New("body must be json object")
+	}
+	if err := json.Unmarshal(fields[kindApi.bodyKey], &target.key); err != nil || target.key == "" {
+		return nil, fmt.Errorf("body field %s required", kindApi.bodyKey)
+	}
+	target.body = op.Body
+	return target, nil
+}
+
+// state of batch target before operation, restoring it undoes the operation
+type batchUndo struct {
+	target   *batchTarget
+	snapshot database.Snapshot
+	// scripts of route, dynamic handler is updated in place by the route api,
+	// scripts of replaced graphql and websocket definitions are removed
+	scripts map[string]string
+}
+
+func (s *server) snapshotBatchTarget(ctx context.Context, target *batchTarget) (batchUndo, error) {
+	undo := batchUndo{target: target}
+	var err error
+
+	switch target.kind {
+	case protocol.BATCH_KIND_POOL:
+		undo.snapshot, err = database.SnapshotMessagePool(ctx, target.key)
+	case protocol.BATCH_KIND_ESB:
+		undo.snapshot, err = database.SnapshotESBRecord(ctx, target.key)
+	default:
+		undo.snapshot, err = database.SnapshotRoute(ctx, target.key)
+		if err != nil || !undo.snapshot.Exists() {
+			break
+		}
+		var route database.Route
+		if err = undo.snapshot.Decode(&route); err != nil {
+			break
+		}
+		undo.scripts, err = s.readRouteScripts(&route)
+	}
+	return undo, err
+}
+
+func (s *server) restoreBatchTarget(ctx context.Context, undo *batchUndo) error {
+	key := undo.target.key
+
+	switch undo.target.kind {
+	case protocol.BATCH_KIND_POOL:
+		return brokers.RestoreMessagePool(ctx, key, undo.snapshot)
+	case protocol.BATCH_KIND_ESB:
+		return database.RestoreESBRecord(ctx, key, undo.snapshot)
+	default:
+		var restored *database.Route
+		if undo.snapshot.Exists() {
+			restored = &database.Route{}
+			if err := undo.snapshot.Decode(restored); err != nil {
+				return err
+			}
+		}
+		return s.putBackRoute(ctx, key, restored, undo.scripts, func() error {
+			return database.RestoreRoute(ctx, key, undo.snapshot)
+		})
+	}
+}
+
+func batchAuditResource(kind string) string {
+	switch kind {
+	case protocol.BATCH_KIND_POOL:
+		return database.AUDIT_RESOURCE_POOL
+	case protocol.BATCH_KIND_ESB:
+		return database.AUDIT_RESOURCE_ESB
+	default:
+		return database.AUDIT_RESOURCE_ROUTE
+	}
+}
+
+// restores target and records rollback in audit log, unless aborted
+// transaction has undone the operation already
+func (s *server) rollbackBatchTarget(ctx context.Context, c *gin.Context, undo *batchUndo) error {
+	resource := batchAuditResource(undo.target.kind)
+	before, err := s.auditState(ctx, resource, undo.target.key)
+	if err != nil {
+		return err
+	}
+
+	if err := s.restoreBatchTarget(ctx, undo); err != nil {
+		return err
+	}
+
+	after, err := s.auditState(ctx, resource, undo.target.key)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(before, after) {
+		return nil
+	}
+
+	s.recordChange(c, database.AuditRecord{
+		Operation: database.AUDIT_OP_ROLLBACK,
+		Resource:  resource,
+		Key:       undo.target.key,
+		Before:    before,
+	})
+	return nil
+}
+
+// collects response of batch operation
+type batchResponseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *batchResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *batchResponseWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+}
+
+func (w *batchResponseWriter) Write(data []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.body.Write(data)
+}
+
+// runs operation through the router, so it is validated and applied
+// exactly like separate request with the same credentials
+func (s *server) serveBatchTarget(ctx context.Context, parent *http.Request, target *batchTarget) (int, []byte) {
+	uri := url.URL{Path: target.path, RawQuery: target.query.Encode()}
+	req, err := http.NewRequestWithContext(ctx, target.method, uri.String(), bytes.NewReader(target.body))
+	if err != nil {
+		return http.StatusInternalServerError, []byte(err.Error())
+	}
+	req.Header = parent.Header.Clone()
+	req.Header.Set("Content-Type", gin.MIMEJSON)
+	req.RemoteAddr = parent.RemoteAddr
+
+	writer := &batchResponseWriter{header: make(http.Header)}
+	s.router.ServeHTTP(writer, req)
+	return writer.status, writer.body.Bytes()
+}
+
+func (s *server) initBatchApi(admin *gin.RouterGroup) {
+	admin.POST("/batch", func(c *gin.Context) {
+		var request protocol.BatchRequest
+		if err := c.Bind(&request); err != nil {
+			zlog.Error().Err(err).Msg("Failed to bind request")
+			apiError(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
+
+		zlog.Info().Int("operations", len(request.Operations)).Msg("Received batch request")
+
+		response := protocol.BatchResponse{
+			Transaction: database.TransactionsSupported(c),
+			Results:     make([]protocol.BatchResult, len(request.Operations)),
+		}
+		fail := func(status int, message string) {
+			zlog.Error().Str("error", message).Msg("Batch is not applied")
+			response.Error = message
+			apiErrorWithBody(c, status, &batchError{results: response.Results}, message, response)
+		}
+
+		// nothing is applied if any operation is malformed
+		targets := make([]*batchTarget, len(request.Operations))
+		invalid := 0
+		for i := range request.Operations {
+			op := &request.Operations[i]
+			result := &response.Results[i]
+			*result = protocol.BatchResult{Index: i, Op: op.Op, Kind: op.Kind, State: protocol.BATCH_STATE_SKIPPED}
+
+			target, err := newBatchTarget(op)
+			if err != nil {
+				result.Status = http.StatusBadRequest
+				result.State = protocol.BATCH_STATE_FAILED
+				result.Error = err.Error()
+				invalid++
+				continue
+			}
+			result.Key = target.key
+			targets[i] = target
+		}
+		if invalid != 0 {
+			fail(http.StatusBadRequest, fmt.Sprintf("%d of batch operations are invalid", invalid))
+			return
+		}
+
+		failedStatus := http.StatusInternalServerError
+		undos := make([]batchUndo, 0, len(targets))
+		err := database.RunInTransaction(c, func(ctx context.Context) error {
+			for i, target := range targets {
+				result := &response.Results[i]
+
+				undo, err := s.snapshotBatchTarget(ctx, target)
+				if err != nil {
+					result.State = protocol.BATCH_STATE_FAILED
+					result.Error = err.Error()
+					return err
+				}
+
+				status, body := s.serveBatchTarget(ctx, c.Request, target)
+				result.Status = status
+				if status >= http.StatusBadRequest {
+					failedStatus = status
+					result.State = protocol.BATCH_STATE_FAILED
+					result.Error = apiErrorMessage(body, status)
+					return errBatchOperationFailed
+				}
+
+				result.State = protocol.BATCH_STATE_APPLIED
+				undos = append(undos, undo)
+			}
+			return nil
+		})
+		if err == nil {
+			response.Applied = true
+			zlog.Info().Int("operations", len(targets)).Msg("Batch applied")
+			c.JSON(http.StatusOK, response)
+			return
+		}
+
+		// aborted transaction leaves caches, script files and broker endpoints
+		// behind, so applied operations are undone in any case; base context
+		// is not cancelled with failed request and keeps storage of server
+		ctx := s.baseCtx
+		for i := len(undos) - 1; i >= 0; i-- {
+			result := &response.Results[i]
+			if err := s.rollbackBatchTarget(ctx, c, &undos[i]); err != nil {
+				zlog.Error().Err(err).Str("key", undos[i].target.key).Msg("Failed to roll back batch operation")
+				result.Error = fmt.Sprintf("rollback failed: %s", err.Error())
+				continue
+			}
+			result.State = protocol.BATCH_STATE_ROLLED_BACK
+		}
+
+		if err == errBatchOperationFailed {
+			fail(failedStatus, "batch operation failed, batch is rolled back")
+		} else {
+			fail(http.StatusInternalServerError, fmt.Sprintf("batch is rolled back: %s", err.Error()))
+		}
+	})
+}
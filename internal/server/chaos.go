@@ -0,0 +1,72 @@
+package server
+
+import (
+	"mock-server/internal/chaos"
+	"mock-server/internal/database"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	zlog "github.com/rs/zerolog/log"
+)
+
+// closes client connection with tcp reset instead of response
+func resetConnection(c *gin.Context) {
+	conn, _, err := c.Writer.Hijack()
+	if err != nil {
+		zlog.Error().Err(err).Msg("Failed to hijack connection for reset")
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	if tcpConn, ok := conn.(*net.TCPConn); ok {
+		// pending data is discarded and RST is sent on close
+		tcpConn.SetLinger(0)
+	}
+	conn.Close()
+	c.Abort()
+}
+
+// injects faults of chaos profile of route namespace or whole server,
+// aborts request if it is failed or reset
+func (s *server) applyChaos(c *gin.Context, route *database.Route) {
+	if !chaos.Enabled() {
+		return
+	}
+
+	prefix := chaos.GLOBAL_PREFIX
+	namespace, err := database.MatchNamespace(c, route.Path)
+	switch err {
+	case nil:
+		prefix = namespace.Prefix
+	case database.ErrNoSuchNamespace:
+	default:
+		zlog.Error().Err(err).Msg("Failed to match namespace of chaos profile")
+	}
+
+	decision, ok := chaos.Decide(prefix)
+	if !ok {
+		return
+	}
+
+	if decision.Delay > 0 {
+		zlog.Info().Str("path", route.Path).Dur("delay", decision.Delay).Msg("Chaos delay")
+		timer := time.NewTimer(decision.Delay)
+		select {
+		case <-c.Request.Context().Done():
+			timer.Stop()
+			c.Abort()
+			return
+		case <-timer.C:
+		}
+	}
+
+	switch {
+	case decision.Reset:
+		zlog.Info().Str("path", route.Path).Msg("Chaos connection reset")
+		resetConnection(c)
+	case decision.Status != 0:
+		zlog.Info().Str("path", route.Path).Int("status", decision.Status).Msg("Chaos failure")
+		c.AbortWithStatusJSON(decision.Status, gin.H{"error": "failure injected by chaos profile"})
+	}
+}
@@ -0,0 +1,119 @@
+package server
+
+import (
+	"errors"
+	"mock-server/internal/chaos"
+	"mock-server/internal/database"
+	"mock-server/internal/server/protocol"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	zlog "github.com/rs/zerolog/log"
+)
+
+func validateChaosProfile(profile *protocol.ChaosProfile) error {
+	if profile.Prefix != chaos.GLOBAL_PREFIX && profile.DropWritePercent != 0 {
+		return errors.New("write tasks can be dropped only by whole server profile")
+	}
+	return nil
+}
+
+func toChaosProfile(profile *protocol.ChaosProfile) chaos.Profile {
+	return chaos.Profile{
+		Prefix:           profile.Prefix,
+		ErrorPercent:     profile.ErrorPercent,
+		ErrorStatuses:    profile.ErrorStatuses,
+		DelayPercent:     profile.DelayPercent,
+		DelayMin:         time.Duration(profile.DelayMinMs) * time.Millisecond,
+		DelayMax:         time.Duration(profile.DelayMaxMs) * time.Millisecond,
+		ResetPercent:     profile.ResetPercent,
+		DropWritePercent: profile.DropWritePercent,
+		Seed:             profile.Seed,
+	}
+}
+
+func toProtocolChaosProfile(profile *chaos.Profile) protocol.ChaosProfile {
+	return protocol.ChaosProfile{
+		Prefix:           profile.Prefix,
+		ErrorPercent:     profile.ErrorPercent,
+		ErrorStatuses:    profile.ErrorStatuses,
+		DelayPercent:     profile.DelayPercent,
+		DelayMinMs:       profile.DelayMin.Milliseconds(),
+		DelayMaxMs:       profile.DelayMax.Milliseconds(),
+		ResetPercent:     profile.ResetPercent,
+		DropWritePercent: profile.DropWritePercent,
+		Seed:             profile.Seed,
+	}
+}
+
+// chaos profiles of whole server and namespaces, kept in memory of the instance
+func (s *server) initChaosApi(admin *gin.RouterGroup) {
+	chaosEndpoint := "/chaos"
+
+	admin.GET(chaosEndpoint, func(c *gin.Context) {
+		zlog.Info().Msg("Get chaos profiles request")
+
+		profiles := chaos.ListProfiles()
+		converted := make([]protocol.ChaosProfile, len(profiles))
+		for i := range profiles {
+			converted[i] = toProtocolChaosProfile(&profiles[i])
+		}
+
+		c.JSON(http.StatusOK, gin.H{"profiles": converted})
+	})
+
+	// enables profile or replaces enabled one, decisions restart from seed
+	admin.PUT(chaosEndpoint, func(c *gin.Context) {
+		var profile protocol.ChaosProfile
+		if err := c.Bind(&profile); err != nil {
+			zlog.Error().Err(err).Msg("Failed to bind request")
+			apiError(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
+
+		zlog.Info().Str("prefix", profile.Prefix).Msg("Received set chaos profile request")
+
+		if err := validateChaosProfile(&profile); err != nil {
+			zlog.Error().Err(err).Msg("Invalid chaos profile")
+			apiError(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
+
+		if profile.Prefix != chaos.GLOBAL_PREFIX {
+			_, err := database.GetNamespace(c, profile.Prefix)
+			switch err {
+			case nil:
+			case database.ErrNoSuchNamespace:
+				zlog.Error().Str("prefix", profile.Prefix).Msg("Chaos profile for unexisting namespace")
+				apiError(c, http.StatusNotFound, err, "Received prefix was not created before")
+				return
+			default:
+				zlog.Error().Err(err).Msg("Failed to query namespace")
+				apiError(c, http.StatusInternalServerError, err, err.Error())
+				return
+			}
+		}
+
+		enabled := chaos.SetProfile(toChaosProfile(&profile))
+
+		zlog.Warn().Str("prefix", enabled.Prefix).Int64("seed", enabled.Seed).Msg("Chaos profile enabled")
+		c.JSON(http.StatusOK, toProtocolChaosProfile(&enabled))
+	})
+
+	// prefix param selects namespace profile, whole server profile is disabled without it
+	admin.DELETE(chaosEndpoint, func(c *gin.Context) {
+		prefix := c.Query("prefix")
+
+		zlog.Info().Str("prefix", prefix).Msg("Received disable chaos profile request")
+
+		if !chaos.RemoveProfile(prefix) {
+			zlog.Error().Str("prefix", prefix).Msg("Chaos profile is not enabled")
+			apiError(c, http.StatusNotFound, nil, "Chaos profile is not enabled")
+			return
+		}
+
+		zlog.Warn().Str("prefix", prefix).Msg("Chaos profile disabled")
+		c.JSON(http.StatusNoContent, "Chaos profile successfully disabled")
+	})
+}
@@ -0,0 +1,199 @@
+package server
+
+import (
+	"fmt"
+	"mock-server/internal/configs"
+	"mock-server/internal/database"
+	"mock-server/internal/server/protocol"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+	zlog "github.com/rs/zerolog/log"
+)
+
+// handlers of policies not used for this long are dropped by sweep
+const corsSweepInterval = time.Minute
+
+func toCorsConfig(policy *database.CorsPolicy) cors.Config {
+	cfg := cors.DefaultConfig()
+	cfg.AllowOrigins = policy.AllowOrigins
+	if len(policy.AllowMethods) != 0 {
+		cfg.AllowMethods = policy.AllowMethods
+	}
+	cfg.AddAllowHeaders(policy.AllowHeaders...)
+	cfg.ExposeHeaders = policy.ExposeHeaders
+	cfg.AllowCredentials = policy.AllowCredentials
+	if policy.MaxAgeSec != 0 {
+		cfg.MaxAge = time.Duration(policy.MaxAgeSec) * time.Second
+	}
+	cfg.AllowWildcard = true
+
+	// browsers reject "*" in allowed origin for credentialed requests,
+	// so request origin is reflected instead
+	if policy.AllowCredentials {
+		for _, origin := range policy.AllowOrigins {
+			if origin == "*" {
+				cfg.AllowOrigins = nil
+				cfg.AllowOriginFunc = func(string) bool { return true }
+				break
+			}
+		}
+	}
+
+	return cfg
+}
+
+// cors.New panics on invalid config and Validate does not check all of it
+// (e.g. origin with several wildcards), so panic is returned as error
+func newCorsHandler(policy *database.CorsPolicy) (handler gin.HandlerFunc, err error) {
+	cfg := toCorsConfig(policy)
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			handler, err = nil, fmt.Errorf("%v", r)
+		}
+	}()
+	return cors.New(cfg), nil
+}
+
+func validateCorsPolicy(policy *protocol.CorsPolicy) error {
+	_, err := newCorsHandler(toDatabaseCorsPolicy(policy))
+	return err
+}
+
+type corsHandler struct {
+	handle   gin.HandlerFunc
+	err      error // policy is invalid, requests are rejected
+	lastUsed time.Time
+}
+
+// handlers of route and namespace policies, handler is built on the first request
+// after policy is set and reused by next ones; policy is a part of the key,
+// so handler is rebuilt on policy change
+type corsHandlers struct {
+	handlers  map[string]*corsHandler
+	lastSweep time.Time
+	mtx       sync.Mutex
+}
+
+func newCorsHandlers() *corsHandlers {
+	return &corsHandlers{
+		handlers:  make(map[string]*corsHandler),
+		lastSweep: time.Now(),
+	}
+}
+
+func corsPolicyKey(policy *database.CorsPolicy) string {
+	return fmt.Sprintf("%q|%q|%q|%q|%t|%d",
+		policy.AllowOrigins,
+		policy.AllowMethods,
+		policy.AllowHeaders,
+		policy.ExposeHeaders,
+		policy.AllowCredentials,
+		policy.MaxAgeSec,
+	)
+}
+
+func (ch *corsHandlers) get(policy *database.CorsPolicy, now time.Time) (gin.HandlerFunc, error) {
+	ch.mtx.Lock()
+	defer ch.mtx.Unlock()
+
+	if now.Sub(ch.lastSweep) > corsSweepInterval {
+		ch.sweep(now)
+	}
+
+	key := corsPolicyKey(policy)
+	handler, ok := ch.handlers[key]
+	if !ok {
+		handle, err := newCorsHandler(policy)
+		handler = &corsHandler{handle: handle, err: err}
+		ch.handlers[key] = handler
+	}
+	handler.lastUsed = now
+	return handler.handle, handler.err
+}
+
+// must be called under lock
+func (ch *corsHandlers) sweep(now time.Time) {
+	for key, handler := range ch.handlers {
+		if now.Sub(handler.lastUsed) > corsSweepInterval {
+			delete(ch.handlers, key)
+		}
+	}
+	ch.lastSweep = now
+}
+
+func toDatabaseCorsPolicy(policy *protocol.CorsPolicy) *database.CorsPolicy {
+	if policy == nil {
+		return nil
+	}
+	return &database.CorsPolicy{
+		AllowOrigins:     policy.AllowOrigins,
+		AllowMethods:     policy.AllowMethods,
+		AllowHeaders:     policy.AllowHeaders,
+		ExposeHeaders:    policy.ExposeHeaders,
+		AllowCredentials: policy.AllowCredentials,
+		MaxAgeSec:        policy.MaxAgeSec,
+	}
+}
+
+func toProtocolCorsPolicy(policy *database.CorsPolicy) *protocol.CorsPolicy {
+	if policy == nil {
+		return nil
+	}
+	return &protocol.CorsPolicy{
+		AllowOrigins:     policy.AllowOrigins,
+		AllowMethods:     policy.AllowMethods,
+		AllowHeaders:     policy.AllowHeaders,
+		ExposeHeaders:    policy.ExposeHeaders,
+		AllowCredentials: policy.AllowCredentials,
+		MaxAgeSec:        policy.MaxAgeSec,
+	}
+}
+
+func newAdminCors(cfg *configs.CorsConfig) gin.HandlerFunc {
+	// needs when routing development-mode frontend app
+	if cfg == nil {
+		return cors.Default()
+	}
+
+	return cors.New(toCorsConfig(&database.CorsPolicy{
+		AllowOrigins:     cfg.AllowOrigins,
+		AllowMethods:     cfg.AllowMethods,
+		AllowHeaders:     cfg.AllowHeaders,
+		ExposeHeaders:    cfg.ExposeHeaders,
+		AllowCredentials: cfg.AllowCredentials,
+		MaxAgeSec:        int64(cfg.MaxAge / time.Second),
+	}))
+}
+
+// admin api policy is applied on engine level, because preflight requests
+// have no registered handlers and reach only engine middlewares,
+// mock routes (even under /api prefix) are skipped and resolved by dispatcher
+func (s *server) adminCorsMiddleware(cfg *configs.CorsConfig) gin.HandlerFunc {
+	adminCors := newAdminCors(cfg)
+	return func(c *gin.Context) {
+		if c.FullPath() == "" && !s.adminPaths[c.Request.URL.Path] {
+			return
+		}
+		adminCors(c)
+	}
+}
+
+// aborts request on preflight or forbidden origin
+func (s *server) applyCorsPolicy(c *gin.Context, policy *database.CorsPolicy) {
+	handle, err := s.corsHandlers.get(policy, time.Now())
+	if err != nil {
+		// policies are validated when set, but stored ones may come from older versions
+		zlog.Error().Err(err).Msg("Invalid cors policy")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "invalid cors policy: " + err.Error()})
+		return
+	}
+	handle(c)
+}
@@ -5,6 +5,7 @@ import (
 	"mock-server/internal/server/protocol"
 	"mock-server/internal/util"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	zlog "github.com/rs/zerolog/log"
@@ -14,24 +15,13 @@ import (
 func (s *server) initRoutesApiDynamic(routes *gin.RouterGroup) {
 	dynamicRoutesEndpoint := "/dynamic"
 
-	routes.GET(dynamicRoutesEndpoint, func(c *gin.Context) {
-		zlog.Info().Msg("Get all routes dynamic request")
-
-		endpoints, err := database.ListAllDynamicEndpointPaths(c)
-		if err != nil {
-			zlog.Error().Err(err).Msg("Failed to list all dynamic endpoint paths")
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
-
-		c.JSON(http.StatusOK, gin.H{"endpoints": endpoints})
-	})
+	routes.GET(dynamicRoutesEndpoint, listRoutePathsHandler(database.DYNAMIC_ENDPOINT_TYPE))
 
 	routes.GET(dynamicRoutesEndpoint+"/code", func(c *gin.Context) {
 		path := c.Query("path")
 		if path == "" {
 			zlog.Error().Msg("Path param not specified")
-			c.JSON(http.StatusBadRequest, gin.H{"error": "specify path param"})
+			apiError(c, http.StatusBadRequest, nil, "specify path param")
 			return
 		}
 
@@ -43,44 +33,93 @@ func (s *server) initRoutesApiDynamic(routes *gin.RouterGroup) {
 			zlog.Info().Str("script name", scriptName).Msg("Got script")
 		case database.ErrNoSuchPath, database.ErrBadRouteType:
 			zlog.Error().Msg("Request for unexisting script")
-			c.JSON(http.StatusNotFound, gin.H{"error": "Received path was not created before"})
+			apiError(c, http.StatusNotFound, err, "Received path was not created before")
 			return
 		default:
 			zlog.Error().Err(err).Msg("Failed to query script name")
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			apiError(c, http.StatusInternalServerError, err, err.Error())
 			return
 		}
 
 		code, err := s.fs.Read(FS_DYN_HANDLE_DIR, scriptName)
 		if err != nil {
 			zlog.Error().Err(err).Str("script name", scriptName).Msg("Failed to read script code")
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			apiError(c, http.StatusInternalServerError, err, err.Error())
 			return
 		}
 
 		c.JSON(http.StatusOK, util.UnwrapCodeForDynHandle(code))
 	})
 
+	routes.GET(dynamicRoutesEndpoint+"/config", func(c *gin.Context) {
+		path := c.Query("path")
+		if path == "" {
+			zlog.Error().Msg("Path param not specified")
+			apiError(c, http.StatusBadRequest, nil, "specify path param")
+			return
+		}
+
+		zlog.Info().Str("path", path).Msg("Received get config dynamic request")
+
+		route, err := database.GetDynamicEndpoint(c, path)
+		switch err {
+		case nil:
+			zlog.Info().Str("script name", route.ScriptName).Msg("Got dynamic route")
+		case database.ErrNoSuchPath, database.ErrBadRouteType:
+			zlog.Error().Msg("Request for unexisting dynamic route")
+			apiError(c, http.StatusNotFound, err, "Received path was not created before")
+			return
+		default:
+			zlog.Error().Err(err).Msg("Failed to query dynamic route")
+			apiError(c, http.StatusInternalServerError, err, err.Error())
+			return
+		}
+
+		code, err := s.fs.Read(FS_DYN_HANDLE_DIR, route.ScriptName)
+		if err != nil {
+			zlog.Error().Err(err).Str("script name", route.ScriptName).Msg("Failed to read script code")
+			apiError(c, http.StatusInternalServerError, err, err.Error())
+			return
+		}
+
+		c.JSON(http.StatusOK, protocol.DynamicEndpoint{
+			Path:        route.Path,
+			Code:        util.UnwrapCodeForDynHandle(code),
+			RouteWindow: toProtocolRouteWindow(&route.ActivityWindow),
+		})
+	})
+
 	routes.POST(dynamicRoutesEndpoint, func(c *gin.Context) {
 		var dynamicEndpoint protocol.DynamicEndpoint
 		if err := c.Bind(&dynamicEndpoint); err != nil {
 			zlog.Error().Err(err).Msg("Failed to bind request")
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			apiError(c, http.StatusBadRequest, err, err.Error())
 			return
 		}
 
 		zlog.Info().Str("path", dynamicEndpoint.Path).Msg("Received create dynamic request")
 
+		if err := validateRouteWindow(&dynamicEndpoint.RouteWindow, time.Now()); err != nil {
+			zlog.Error().Err(err).Msg("Invalid route window")
+			apiError(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
+
 		scriptName := util.GenUniqueFilename("py")
 		zlog.Info().Str("filename", scriptName).Msg("Generated script name")
 
 		if err := s.fs.Write(FS_DYN_HANDLE_DIR, scriptName, util.WrapCodeForDynHandle(dynamicEndpoint.Code)); err != nil {
 			zlog.Error().Err(err).Msg("Failed to write code to file")
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			apiError(c, http.StatusInternalServerError, err, err.Error())
 			return
 		}
 
-		err := database.AddDynamicEndpoint(c, dynamicEndpoint.Path, scriptName)
+		err := database.AddDynamicEndpoint(
+			c,
+			dynamicEndpoint.Path,
+			scriptName,
+			routeWindowOption(&dynamicEndpoint.RouteWindow),
+		)
 
 		switch err {
 		case nil:
@@ -91,10 +130,10 @@ func (s *server) initRoutesApiDynamic(routes *gin.RouterGroup) {
 			c.JSON(http.StatusOK, "Dynamic endpoint successfully added")
 		case database.ErrDuplicateKey:
 			zlog.Error().Err(err).Msg("Failed to add dynamic endpoint")
-			c.JSON(http.StatusConflict, gin.H{"error": "The same endpoint already exists"})
+			apiError(c, http.StatusConflict, err, "The same endpoint already exists")
 		default:
 			zlog.Error().Err(err).Msg("Failed to add dynamic endpoint")
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			apiError(c, http.StatusInternalServerError, err, err.Error())
 		}
 	})
 
@@ -102,28 +141,32 @@ func (s *server) initRoutesApiDynamic(routes *gin.RouterGroup) {
 		var dynamicEndpoint protocol.DynamicEndpoint
 		if err := c.Bind(&dynamicEndpoint); err != nil {
 			zlog.Error().Err(err).Msg("Failed to bind request")
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			apiError(c, http.StatusBadRequest, err, err.Error())
 			return
 		}
 
 		zlog.Info().Str("path", dynamicEndpoint.Path).Msg("Received update dynamic request")
 
+		if err := validateRouteWindow(&dynamicEndpoint.RouteWindow, time.Now()); err != nil {
+			zlog.Error().Err(err).Msg("Invalid route window")
+			apiError(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
+
 		scriptName, err := database.GetDynamicEndpointScriptName(c, dynamicEndpoint.Path)
 		switch err {
 		case nil:
-			zlog.Info().Str("path", dynamicEndpoint.Path).Msg("Dynamic endpoint updated")
-			c.JSON(http.StatusNoContent, "Dynamic endpoint successfully updated")
 		case database.ErrNoSuchPath:
 			zlog.Error().Msg("Update on unexisting path")
-			c.JSON(http.StatusNotFound, gin.H{"error": "Received path was not created before"})
+			apiError(c, http.StatusNotFound, err, "Received path was not created before")
 			return
 		case database.ErrBadRouteType:
 			zlog.Error().Msg("Update on route with different type")
-			c.JSON(http.StatusNotFound, gin.H{"error": "Received path has different route type"})
+			apiError(c, http.StatusNotFound, err, "Received path has different route type")
 			return
 		default:
 			zlog.Error().Err(err).Msg("Failed to get script name")
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			apiError(c, http.StatusInternalServerError, err, err.Error())
 			return
 		}
 
@@ -131,16 +174,34 @@ func (s *server) initRoutesApiDynamic(routes *gin.RouterGroup) {
 
 		if err := s.fs.Write(FS_DYN_HANDLE_DIR, scriptName, util.WrapCodeForDynHandle(dynamicEndpoint.Code)); err != nil {
 			zlog.Error().Err(err).Msg("Failed to write code to file")
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			apiError(c, http.StatusInternalServerError, err, err.Error())
 			return
 		}
+
+		err = database.UpdateDynamicEndpoint(
+			c,
+			dynamicEndpoint.Path,
+			scriptName,
+			routeWindowOption(&dynamicEndpoint.RouteWindow),
+		)
+		switch err {
+		case nil:
+			zlog.Info().Str("path", dynamicEndpoint.Path).Msg("Dynamic endpoint updated")
+			c.JSON(http.StatusNoContent, "Dynamic endpoint successfully updated")
+		case database.ErrNoSuchPath:
+			zlog.Error().Msg("Update on unexisting path")
+			apiError(c, http.StatusNotFound, err, "Received path was not created before")
+		default:
+			zlog.Error().Err(err).Msg("Failed to update dynamic endpoint")
+			apiError(c, http.StatusInternalServerError, err, err.Error())
+		}
 	})
 
 	routes.DELETE(dynamicRoutesEndpoint, func(c *gin.Context) {
 		path := c.Query("path")
 		if path == "" {
 			zlog.Error().Msg("Path param not specified")
-			c.JSON(http.StatusBadRequest, gin.H{"error": "specify path param"})
+			apiError(c, http.StatusBadRequest, nil, "specify path param")
 			return
 		}
 
@@ -148,7 +209,7 @@ func (s *server) initRoutesApiDynamic(routes *gin.RouterGroup) {
 
 		if err := database.RemoveDynamicEndpoint(c, path); err != nil {
 			zlog.Error().Err(err).Msg("Failed to dynamic endpoint")
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			apiError(c, http.StatusInternalServerError, err, err.Error())
 			return
 		}
 
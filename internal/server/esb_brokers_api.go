@@ -18,15 +18,20 @@ func (s *server) initBrokersApiEsb(brokersApi *gin.RouterGroup) {
 	brokersApi.GET(esbBrokersEndpoint, func(c *gin.Context) {
 		zlog.Info().Msg("Get all esb records request")
 
-		esbRecords, err := database.ListESBRecords(c)
+		query, ok := bindListQuery(c)
+		if !ok {
+			return
+		}
+
+		esbRecords, err := database.FindESBRecords(c, query)
 		if err != nil {
-			zlog.Error().Err(err).Msg("Failed to list all esb records")
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			zlog.Error().Err(err).Msg("Failed to list esb records")
+			listPageError(c, err)
 			return
 		}
 
 		respEsbRecords := make([]protocol.EsbRecord, 0)
-		for _, esbRecord := range esbRecords {
+		for _, esbRecord := range esbRecords.Items {
 			respEsbRecords = append(respEsbRecords, protocol.EsbRecord{
 				PoolNameIn:  esbRecord.PoolNameIn,
 				PoolNameOut: esbRecord.PoolNameOut,
@@ -34,7 +39,7 @@ func (s *server) initBrokersApiEsb(brokersApi *gin.RouterGroup) {
 		}
 
 		zlog.Debug().Interface("records", respEsbRecords).Msg("Successfully queried all esb records")
-		c.JSON(http.StatusOK, gin.H{"records": respEsbRecords})
+		c.JSON(http.StatusOK, pageResponse("records", respEsbRecords, esbRecords.NextCursor))
 	})
 
 	// get mapper code for esb record by in-pool name
@@ -42,7 +47,7 @@ func (s *server) initBrokersApiEsb(brokersApi *gin.RouterGroup) {
 		poolInName := c.Query("pool_in")
 		if poolInName == "" {
 			zlog.Error().Msg("Pool param not specified")
-			c.JSON(http.StatusBadRequest, gin.H{"error": "specify pool param"})
+			apiError(c, http.StatusBadRequest, nil, "specify pool param")
 			return
 		}
 
@@ -52,23 +57,23 @@ func (s *server) initBrokersApiEsb(brokersApi *gin.RouterGroup) {
 			zlog.Info().Str("pool", esbRecord.PoolNameIn).Msg("Queried")
 			if esbRecord.MapperScriptName == brokers.EMPTY_MAPPER {
 				zlog.Error().Str("pool", esbRecord.PoolNameIn).Msg("No code for esb record")
-				c.JSON(http.StatusBadRequest, gin.H{"error": "Such record does not have mapper code"})
+				apiError(c, http.StatusBadRequest, nil, "Such record does not have mapper code")
 				return
 			}
 		case database.ErrNoSuchRecord:
 			zlog.Error().Str("pool", esbRecord.PoolNameIn).Msg("Such record was not created before")
-			c.JSON(http.StatusNotFound, gin.H{"error": "Such record was not created before"})
+			apiError(c, http.StatusNotFound, err, "Such record was not created before")
 			return
 		default:
 			zlog.Error().Err(err).Msg("Failed to get esb record")
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			apiError(c, http.StatusInternalServerError, err, err.Error())
 			return
 		}
 
 		code, err := s.fs.Read(FS_ESB_DIR, esbRecord.MapperScriptName)
 		if err != nil {
 			zlog.Error().Err(err).Msg("Failed to read esb record code")
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			apiError(c, http.StatusInternalServerError, err, err.Error())
 			return
 		}
 
@@ -80,7 +85,7 @@ func (s *server) initBrokersApiEsb(brokersApi *gin.RouterGroup) {
 		var esbRecord protocol.EsbRecord
 		if err := c.Bind(&esbRecord); err != nil {
 			zlog.Error().Err(err).Msg("Failed to bind request")
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			apiError(c, http.StatusBadRequest, err, err.Error())
 			return
 		}
 
@@ -101,10 +106,10 @@ func (s *server) initBrokersApiEsb(brokersApi *gin.RouterGroup) {
 				c.JSON(http.StatusOK, "Esb record successfully added!")
 			case database.ErrDuplicateKey:
 				zlog.Error().Msg("Esb record with the same in-pool already exists")
-				c.JSON(http.StatusConflict, gin.H{"error": "Esb record with the same in-pool already exists"})
+				apiError(c, http.StatusConflict, err, "Esb record with the same in-pool already exists")
 			default:
 				zlog.Error().Err(err).Msg("Failed to add esb record")
-				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				apiError(c, http.StatusInternalServerError, err, err.Error())
 			}
 		default:
 			zlog.Info().
@@ -115,7 +120,7 @@ func (s *server) initBrokersApiEsb(brokersApi *gin.RouterGroup) {
 			scriptName := util.GenUniqueFilename("py")
 			if err := s.fs.Write(FS_ESB_DIR, scriptName, util.WrapCodeForEsb(esbRecord.Code)); err != nil {
 				zlog.Error().Err(err).Msg("Failed to create script file")
-				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				apiError(c, http.StatusInternalServerError, err, err.Error())
 				return
 			}
 
@@ -130,10 +135,10 @@ func (s *server) initBrokersApiEsb(brokersApi *gin.RouterGroup) {
 				c.JSON(http.StatusOK, "Esb record successfully added!")
 			case database.ErrDuplicateKey:
 				zlog.Error().Msg("Esb record with the same in-pool already exists")
-				c.JSON(http.StatusConflict, gin.H{"error": "Esb record with the same in-pool already exists"})
+				apiError(c, http.StatusConflict, err, "Esb record with the same in-pool already exists")
 			default:
 				zlog.Error().Err(err).Msg("Failed to add esb record")
-				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				apiError(c, http.StatusInternalServerError, err, err.Error())
 			}
 		}
 	})
@@ -143,24 +148,24 @@ func (s *server) initBrokersApiEsb(brokersApi *gin.RouterGroup) {
 		var brokerTask protocol.BrokerTask
 		if err := c.Bind(&brokerTask); err != nil {
 			zlog.Error().Err(err).Msg("Failed to bind request")
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			apiError(c, http.StatusBadRequest, err, err.Error())
 			return
 		}
 		zlog.Info().Interface("task", brokerTask).Msg("Received")
 
 		zlog.Info().Str("pool", brokerTask.PoolName).Msg("Received pool write task")
 
-		pool, err := brokers.GetMessagePool(brokerTask.PoolName)
+		pool, err := brokers.GetMessagePool(c, brokerTask.PoolName)
 		switch err {
 		case nil:
 			zlog.Info().Str("pool", brokerTask.PoolName).Msg("Queried pool")
 		case database.ErrNoSuchPool:
 			zlog.Error().Str("pool", brokerTask.PoolName).Msg("No such pool")
-			c.JSON(http.StatusNotFound, gin.H{"error": "No such pool"})
+			apiError(c, http.StatusNotFound, err, "No such pool")
 			return
 		default:
 			zlog.Error().Err(err).Msg("Failed to get pool")
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			apiError(c, http.StatusInternalServerError, err, err.Error())
 			return
 		}
 
@@ -173,12 +178,69 @@ func (s *server) initBrokersApiEsb(brokersApi *gin.RouterGroup) {
 		c.JSON(http.StatusNoContent, "Task successfully submited to esb pair in-pool")
 	})
 
+	// update out-pool and mapper code of esb pair, empty code removes mapper
+	brokersApi.PUT(esbBrokersEndpoint, func(c *gin.Context) {
+		var esbRecord protocol.EsbRecord
+		if err := c.Bind(&esbRecord); err != nil {
+			zlog.Error().Err(err).Msg("Failed to bind request")
+			apiError(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
+
+		zlog.Info().
+			Str("pool in", esbRecord.PoolNameIn).
+			Str("pool out", esbRecord.PoolNameOut).
+			Msg("Received update request for esb record")
+
+		stored, err := brokers.GetEsbRecord(c, esbRecord.PoolNameIn)
+		switch err {
+		case nil:
+		case database.ErrNoSuchRecord:
+			zlog.Error().Str("pool", esbRecord.PoolNameIn).Msg("Update on unexisting esb record")
+			apiError(c, http.StatusNotFound, err, "Such record was not created before")
+			return
+		default:
+			zlog.Error().Err(err).Msg("Failed to get esb record")
+			apiError(c, http.StatusInternalServerError, err, err.Error())
+			return
+		}
+
+		scriptName := brokers.EMPTY_MAPPER
+		if esbRecord.Code != "" {
+			scriptName = stored.MapperScriptName
+			if scriptName == brokers.EMPTY_MAPPER {
+				scriptName = util.GenUniqueFilename("py")
+			}
+			if err := s.fs.Write(FS_ESB_DIR, scriptName, util.WrapCodeForEsb(esbRecord.Code)); err != nil {
+				zlog.Error().Err(err).Msg("Failed to write mapper code")
+				apiError(c, http.StatusInternalServerError, err, err.Error())
+				return
+			}
+		}
+
+		err = brokers.UpdateEsbRecord(c, esbRecord.PoolNameIn, esbRecord.PoolNameOut, scriptName)
+		switch err {
+		case nil:
+			zlog.Info().
+				Str("pool in", esbRecord.PoolNameIn).
+				Str("mapper script name", scriptName).
+				Msg("Esb record updated")
+			c.JSON(http.StatusNoContent, "Esb record successfully updated")
+		case database.ErrNoSuchRecord:
+			zlog.Error().Str("pool", esbRecord.PoolNameIn).Msg("Update on unexisting esb record")
+			apiError(c, http.StatusNotFound, err, "Such record was not created before")
+		default:
+			zlog.Error().Err(err).Msg("Failed to update esb record")
+			apiError(c, http.StatusInternalServerError, err, err.Error())
+		}
+	})
+
 	// create delete esb pair
 	brokersApi.DELETE(esbBrokersEndpoint, func(c *gin.Context) {
 		poolInName := c.Query("pool_in")
 		if poolInName == "" {
 			zlog.Error().Msg("Pool param not specified")
-			c.JSON(http.StatusBadRequest, gin.H{"error": "specify pool param"})
+			apiError(c, http.StatusBadRequest, nil, "specify pool param")
 			return
 		}
 
@@ -189,10 +251,10 @@ func (s *server) initBrokersApiEsb(brokersApi *gin.RouterGroup) {
 			c.JSON(http.StatusNoContent, "Esb record successfully removed")
 		case database.ErrNoSuchRecord:
 			zlog.Error().Msg("No such esb record")
-			c.JSON(http.StatusNotFound, "No such esb record was created before")
+			apiErrorWithBody(c, http.StatusNotFound, err, "No such esb record was created before", "No such esb record was created before")
 		default:
 			zlog.Error().Err(err).Msg("Failed to delete Esb record")
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			apiError(c, http.StatusInternalServerError, err, err.Error())
 		}
 	})
 }
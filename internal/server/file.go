@@ -0,0 +1,104 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mock-server/internal/database"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	zlog "github.com/rs/zerolog/log"
+)
+
+// enough for http.DetectContentType
+const sniffLen = 512
+
+type storedFile struct {
+	name        string
+	size        int64
+	contentType string
+}
+
+// content type by extension of uploaded file name, sniffed from content if extension is unknown
+func detectContentType(header *multipart.FileHeader, file io.ReadSeeker) (string, error) {
+	if contentType := mime.TypeByExtension(filepath.Ext(header.Filename)); contentType != "" {
+		return contentType, nil
+	}
+
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return http.DetectContentType(head[:n]), nil
+}
+
+// stores uploaded file under sha256 of its content, so files of
+// previous route revisions stay in place and equal uploads are kept once
+func (s *server) storeUploadedFile(header *multipart.FileHeader, contentType string) (storedFile, error) {
+	file, err := header.Open()
+	if err != nil {
+		return storedFile{}, err
+	}
+	defer file.Close()
+
+	if contentType == "" {
+		if contentType, err = detectContentType(header, file); err != nil {
+			return storedFile{}, err
+		}
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return storedFile{}, err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return storedFile{}, err
+	}
+	name := hex.EncodeToString(hash.Sum(nil))
+
+	size, err := s.fs.WriteFrom(FS_FILES_DIR, name, file)
+	if err != nil {
+		return storedFile{}, err
+	}
+	return storedFile{name: name, size: size, contentType: contentType}, nil
+}
+
+// serves stored file without reading it into memory, range and conditional
+// requests are handled by http.ServeContent
+func (s *server) handleFileRouteRequest(c *gin.Context, route *database.Route) {
+	file, err := s.fs.Open(FS_FILES_DIR, route.FileName)
+	if errors.Is(err, os.ErrNotExist) {
+		zlog.Error().Str("path", route.Path).Str("file", route.FileName).Msg("Stored file is missing")
+		c.JSON(http.StatusNotFound, gin.H{"error": "file of route is missing in storage"})
+		return
+	}
+	if err != nil {
+		zlog.Error().Err(err).Str("path", route.Path).Msg("Failed to open stored file")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	// content may change with any update of route
+	modTime := time.Time{}
+	if route.UpdatedAt != nil {
+		modTime = *route.UpdatedAt
+	} else if route.CreatedAt != nil {
+		modTime = *route.CreatedAt
+	}
+
+	c.Header("Content-Type", route.ContentType)
+	c.Header("ETag", `"`+route.FileName+`"`)
+	http.ServeContent(c.Writer, c.Request, "", modTime, file)
+}
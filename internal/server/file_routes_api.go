@@ -0,0 +1,161 @@
+package server
+
+import (
+	"mock-server/internal/database"
+	"mock-server/internal/server/protocol"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	zlog "github.com/rs/zerolog/log"
+)
+
+// file routes (content uploaded as multipart form and served from file storage)
+func (s *server) initRoutesApiFile(routes *gin.RouterGroup) {
+	fileRoutesEndpoint := "/file"
+
+	routes.GET(fileRoutesEndpoint, listRoutePathsHandler(database.FILE_ENDPOINT_TYPE))
+
+	routes.GET(fileRoutesEndpoint+"/config", func(c *gin.Context) {
+		path := c.Query("path")
+		if path == "" {
+			zlog.Error().Msg("Path param not specified")
+			apiError(c, http.StatusBadRequest, nil, "specify path param")
+			return
+		}
+
+		zlog.Info().Str("path", path).Msg("Received get config file request")
+
+		route, err := database.GetFileEndpoint(c, path)
+		switch err {
+		case nil:
+			zlog.Info().Str("path", path).Msg("Got file route")
+		case database.ErrNoSuchPath, database.ErrBadRouteType:
+			zlog.Error().Msg("Request for unexisting file route")
+			apiError(c, http.StatusNotFound, err, "Received path was not created before")
+			return
+		default:
+			zlog.Error().Err(err).Msg("Failed to query file route")
+			apiError(c, http.StatusInternalServerError, err, err.Error())
+			return
+		}
+
+		c.JSON(http.StatusOK, protocol.FileEndpointInfo{
+			Path:        route.Path,
+			ContentType: route.ContentType,
+			Size:        route.FileSize,
+			Digest:      route.FileName,
+			RouteWindow: toProtocolRouteWindow(&route.ActivityWindow),
+		})
+	})
+
+	routes.POST(fileRoutesEndpoint, func(c *gin.Context) {
+		var fileEndpoint protocol.FileEndpoint
+		if err := c.Bind(&fileEndpoint); err != nil {
+			zlog.Error().Err(err).Msg("Failed to bind request")
+			apiError(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
+
+		zlog.Info().Str("path", fileEndpoint.Path).Msg("Received create file request")
+
+		if err := validateRouteWindow(&fileEndpoint.RouteWindow, time.Now()); err != nil {
+			zlog.Error().Err(err).Msg("Invalid route window")
+			apiError(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
+
+		stored, err := s.storeUploadedFile(fileEndpoint.File, fileEndpoint.ContentType)
+		if err != nil {
+			zlog.Error().Err(err).Msg("Failed to store uploaded file")
+			apiError(c, http.StatusInternalServerError, err, err.Error())
+			return
+		}
+
+		err = database.AddFileEndpoint(
+			c,
+			fileEndpoint.Path,
+			stored.name,
+			stored.size,
+			stored.contentType,
+			routeWindowOption(&fileEndpoint.RouteWindow),
+		)
+
+		switch err {
+		case nil:
+			zlog.Info().Str("path", fileEndpoint.Path).Str("file", stored.name).Msg("File endpoint created")
+			c.JSON(http.StatusOK, "File endpoint successfully added!")
+		case database.ErrDuplicateKey:
+			zlog.Error().Str("path", fileEndpoint.Path).Msg("Endpoint with this path already exists")
+			apiError(c, http.StatusConflict, err, "The same endpoint already exists")
+		default:
+			zlog.Error().Err(err).Msg("Failed to add file endpoint")
+			apiError(c, http.StatusInternalServerError, err, err.Error())
+		}
+	})
+
+	routes.PUT(fileRoutesEndpoint, func(c *gin.Context) {
+		var fileEndpoint protocol.FileEndpoint
+		if err := c.Bind(&fileEndpoint); err != nil {
+			zlog.Error().Err(err).Msg("Failed to bind request")
+			apiError(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
+
+		zlog.Info().Str("path", fileEndpoint.Path).Msg("Received update file request")
+
+		if err := validateRouteWindow(&fileEndpoint.RouteWindow, time.Now()); err != nil {
+			zlog.Error().Err(err).Msg("Invalid route window")
+			apiError(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
+
+		stored, err := s.storeUploadedFile(fileEndpoint.File, fileEndpoint.ContentType)
+		if err != nil {
+			zlog.Error().Err(err).Msg("Failed to store uploaded file")
+			apiError(c, http.StatusInternalServerError, err, err.Error())
+			return
+		}
+
+		err = database.UpdateFileEndpoint(
+			c,
+			fileEndpoint.Path,
+			stored.name,
+			stored.size,
+			stored.contentType,
+			routeWindowOption(&fileEndpoint.RouteWindow),
+		)
+		switch err {
+		case nil:
+			zlog.Info().Str("path", fileEndpoint.Path).Str("file", stored.name).Msg("File endpoint updated")
+			c.JSON(http.StatusNoContent, "File endpoint successfully updated!")
+		case database.ErrNoSuchPath:
+			zlog.Error().Msg("Update on unexisting path")
+			apiError(c, http.StatusNotFound, err, "Received path was not created before")
+		default:
+			zlog.Error().Err(err).Msg("Failed to update file endpoint")
+			apiError(c, http.StatusInternalServerError, err, err.Error())
+		}
+	})
+
+	// stored file is kept, it may be served again after revision rollback
+	routes.DELETE(fileRoutesEndpoint, func(c *gin.Context) {
+		path := c.Query("path")
+		if path == "" {
+			zlog.Error().Msg("Path param not specified")
+			apiError(c, http.StatusBadRequest, nil, "specify path param")
+			return
+		}
+
+		zlog.Info().Str("path", path).Msg("Received delete file request")
+
+		if err := database.RemoveFileEndpoint(c, path); err != nil {
+			zlog.Error().Err(err).Msg("Failed to remove file endpoint")
+			apiError(c, http.StatusInternalServerError, err, err.Error())
+			return
+		}
+
+		zlog.Info().Str("path", path).Msg("File endpoint removed")
+		c.JSON(http.StatusNoContent, "File endpoint successfully removed!")
+	})
+}
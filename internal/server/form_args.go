@@ -0,0 +1,102 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+)
+
+// body passed to dynamic handlers instead of raw form request body
+type dynHandleForm struct {
+	// every field may be repeated in form, so values are always listed
+	Fields map[string][]string `json:"fields"`
+	Files  []dynHandleFile     `json:"files"`
+}
+
+type dynHandleFile struct {
+	// name of form field carrying the file
+	Field       string `json:"field"`
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	// base64 encoded in json
+	Content []byte `json:"content"`
+}
+
+func readFormFile(field string, header *multipart.FileHeader) (dynHandleFile, error) {
+	file, err := header.Open()
+	if err != nil {
+		return dynHandleFile{}, err
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return dynHandleFile{}, err
+	}
+
+	return dynHandleFile{
+		Field:       field,
+		Filename:    header.Filename,
+		ContentType: header.Header.Get("Content-Type"),
+		Size:        header.Size,
+		Content:     content,
+	}, nil
+}
+
+// json document of form fields and uploaded files, false if request carries no form
+func dynHandleFormBody(c *gin.Context, maxMemory int64) ([]byte, bool, error) {
+	form := dynHandleForm{Fields: make(map[string][]string), Files: make([]dynHandleFile, 0)}
+
+	switch c.ContentType() {
+	case gin.MIMEPOSTForm:
+		if err := c.Request.ParseForm(); err != nil {
+			return nil, true, err
+		}
+		// fields of query string are not part of the body
+		for name, values := range c.Request.PostForm {
+			form.Fields[name] = values
+		}
+	case gin.MIMEMultipartPOSTForm:
+		if err := c.Request.ParseMultipartForm(maxMemory); err != nil {
+			return nil, true, err
+		}
+		for name, values := range c.Request.MultipartForm.Value {
+			form.Fields[name] = values
+		}
+
+		fields := make([]string, 0, len(c.Request.MultipartForm.File))
+		for field := range c.Request.MultipartForm.File {
+			fields = append(fields, field)
+		}
+		// files are listed in stable order
+		sort.Strings(fields)
+		for _, field := range fields {
+			for _, header := range c.Request.MultipartForm.File[field] {
+				file, err := readFormFile(field, header)
+				if err != nil {
+					return nil, true, err
+				}
+				form.Files = append(form.Files, file)
+			}
+		}
+	default:
+		return nil, false, nil
+	}
+
+	body, err := json.Marshal(form)
+	return body, true, err
+}
+
+// raw body of request, or json document of fields and files if it is a form
+func (s *server) dynHandleBody(c *gin.Context) ([]byte, error) {
+	defer c.Request.Body.Close()
+
+	if body, isForm, err := dynHandleFormBody(c, s.router.MaxMultipartMemory); isForm {
+		return body, err
+	}
+	return io.ReadAll(c.Request.Body)
+}
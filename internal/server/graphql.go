@@ -0,0 +1,334 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mock-server/internal/coderun"
+	"mock-server/internal/database"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	zlog "github.com/rs/zerolog/log"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"github.com/vektah/gqlparser/v2/parser"
+	"github.com/vektah/gqlparser/v2/validator"
+)
+
+// https://graphql.org/learn/serving-over-http/
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+func parseGraphQLRequest(c *gin.Context) (*graphQLRequest, error) {
+	var req graphQLRequest
+
+	switch c.Request.Method {
+	case http.MethodGet:
+		req.Query = c.Query("query")
+		req.OperationName = c.Query("operationName")
+		if variables := c.Query("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				return nil, fmt.Errorf("failed to parse variables: %s", err.Error())
+			}
+		}
+	case http.MethodPost:
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			return nil, err
+		}
+		defer c.Request.Body.Close()
+
+		if c.ContentType() == "application/graphql" {
+			req.Query = string(body)
+		} else if err := json.Unmarshal(body, &req); err != nil {
+			return nil, fmt.Errorf("failed to parse request body: %s", err.Error())
+		}
+	default:
+		return nil, fmt.Errorf("method %s is not supported by graphql endpoint", c.Request.Method)
+	}
+
+	if strings.TrimSpace(req.Query) == "" {
+		return nil, errors.New("query is required")
+	}
+	return &req, nil
+}
+
+func loadGraphQLSchema(schema string) (*ast.Schema, error) {
+	return gqlparser.LoadSchema(&ast.Source{Name: "schema.graphql", Input: schema})
+}
+
+func selectGraphQLOperation(doc *ast.QueryDocument, operationName string) (*ast.OperationDefinition, error) {
+	if operationName != "" {
+		for _, op := range doc.Operations {
+			if op.Name == operationName {
+				return op, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown operation named %q", operationName)
+	}
+
+	if len(doc.Operations) != 1 {
+		return nil, errors.New("operationName is required when document contains several operations")
+	}
+	return doc.Operations[0], nil
+}
+
+// the most specific operation (with the largest number of matched variables) wins
+func matchGraphQLOperation(operations []database.GraphQLOperation, name string, variables map[string]interface{}) *database.GraphQLOperation {
+	var match *database.GraphQLOperation
+	matchedVariables := -1
+
+	for i := range operations {
+		op := &operations[i]
+		if op.OperationName != name {
+			continue
+		}
+
+		expected := make(map[string]interface{})
+		if op.Variables != "" {
+			if err := json.Unmarshal([]byte(op.Variables), &expected); err != nil {
+				zlog.Error().Err(err).Str("operation", op.OperationName).Msg("Failed to parse stored variables")
+				continue
+			}
+		}
+
+		matched := true
+		for key, value := range expected {
+			if actual, ok := variables[key]; !ok || !reflect.DeepEqual(value, actual) {
+				matched = false
+				break
+			}
+		}
+
+		if matched && len(expected) > matchedVariables {
+			match = op
+			matchedVariables = len(expected)
+		}
+	}
+
+	return match
+}
+
+func graphQLPlaceholderScalar(def *ast.Definition) interface{} {
+	switch def.Name {
+	case "Int":
+		return 0
+	case "Float":
+		return 0.0
+	case "Boolean":
+		return false
+	case "ID":
+		return "1"
+	case "String":
+		return "string"
+	default:
+		return def.Name
+	}
+}
+
+func graphQLFragmentApplies(schema *ast.Schema, typeCondition string, def *ast.Definition) bool {
+	if typeCondition == "" || typeCondition == def.Name {
+		return true
+	}
+	condition, ok := schema.Types[typeCondition]
+	if !ok {
+		return false
+	}
+	for _, possible := range schema.GetPossibleTypes(condition) {
+		if possible.Name == def.Name {
+			return true
+		}
+	}
+	return false
+}
+
+func graphQLPlaceholderValue(schema *ast.Schema, selectionSet ast.SelectionSet, t *ast.Type) interface{} {
+	if t.Elem != nil {
+		return []interface{}{graphQLPlaceholderValue(schema, selectionSet, t.Elem)}
+	}
+
+	def, ok := schema.Types[t.NamedType]
+	if !ok {
+		return nil
+	}
+
+	switch def.Kind {
+	case ast.Scalar:
+		return graphQLPlaceholderScalar(def)
+	case ast.Enum:
+		if len(def.EnumValues) == 0 {
+			return nil
+		}
+		return def.EnumValues[0].Name
+	case ast.Object:
+		return graphQLPlaceholderObject(schema, selectionSet, def)
+	case ast.Interface, ast.Union:
+		possible := schema.GetPossibleTypes(def)
+		if len(possible) == 0 {
+			return nil
+		}
+		return graphQLPlaceholderObject(schema, selectionSet, possible[0])
+	default:
+		return nil
+	}
+}
+
+func graphQLPlaceholderObject(schema *ast.Schema, selectionSet ast.SelectionSet, def *ast.Definition) map[string]interface{} {
+	obj := make(map[string]interface{})
+
+	for _, selection := range selectionSet {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			if selection.Name == "__typename" {
+				obj[selection.Alias] = def.Name
+				continue
+			}
+			if selection.Definition == nil {
+				continue
+			}
+			obj[selection.Alias] = graphQLPlaceholderValue(schema, selection.SelectionSet, selection.Definition.Type)
+		case *ast.InlineFragment:
+			if graphQLFragmentApplies(schema, selection.TypeCondition, def) {
+				for key, value := range graphQLPlaceholderObject(schema, selection.SelectionSet, def) {
+					obj[key] = value
+				}
+			}
+		case *ast.FragmentSpread:
+			if selection.Definition != nil && graphQLFragmentApplies(schema, selection.Definition.TypeCondition, def) {
+				for key, value := range graphQLPlaceholderObject(schema, selection.Definition.SelectionSet, def) {
+					obj[key] = value
+				}
+			}
+		}
+	}
+
+	return obj
+}
+
+// generates data shaped as the operation selection set (operation must be validated against schema)
+func graphQLPlaceholderData(schema *ast.Schema, op *ast.OperationDefinition) interface{} {
+	var root *ast.Definition
+	switch op.Operation {
+	case ast.Query:
+		root = schema.Query
+	case ast.Mutation:
+		root = schema.Mutation
+	case ast.Subscription:
+		root = schema.Subscription
+	}
+	if root == nil {
+		return nil
+	}
+	return graphQLPlaceholderObject(schema, op.SelectionSet, root)
+}
+
+func toGraphQLError(err error) *gqlerror.Error {
+	var gqlErr *gqlerror.Error
+	if errors.As(err, &gqlErr) {
+		return gqlErr
+	}
+	return gqlerror.Errorf("%s", err.Error())
+}
+
+func respondGraphQLErrors(c *gin.Context, status int, errs gqlerror.List) {
+	c.JSON(status, gin.H{"errors": errs})
+}
+
+func (s *server) handleGraphQLRouteRequest(c *gin.Context, route *database.Route) {
+	req, err := parseGraphQLRequest(c)
+	if err != nil {
+		zlog.Error().Err(err).Msg("Failed to parse graphql request")
+		respondGraphQLErrors(c, http.StatusBadRequest, gqlerror.List{toGraphQLError(err)})
+		return
+	}
+
+	var schema *ast.Schema
+	var doc *ast.QueryDocument
+	if route.Schema != "" {
+		schema, err = loadGraphQLSchema(route.Schema)
+		if err != nil {
+			zlog.Error().Err(err).Str("path", route.Path).Msg("Failed to load stored schema")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		var errs gqlerror.List
+		doc, errs = gqlparser.LoadQuery(schema, req.Query)
+		if errs != nil {
+			zlog.Info().Str("path", route.Path).Msg("Query failed schema validation")
+			respondGraphQLErrors(c, http.StatusBadRequest, errs)
+			return
+		}
+	} else {
+		doc, err = parser.ParseQuery(&ast.Source{Input: req.Query})
+		if err != nil {
+			zlog.Info().Err(err).Str("path", route.Path).Msg("Failed to parse query")
+			respondGraphQLErrors(c, http.StatusBadRequest, gqlerror.List{toGraphQLError(err)})
+			return
+		}
+	}
+
+	op, err := selectGraphQLOperation(doc, req.OperationName)
+	if err != nil {
+		respondGraphQLErrors(c, http.StatusBadRequest, gqlerror.List{toGraphQLError(err)})
+		return
+	}
+
+	if schema != nil {
+		if _, err := validator.VariableValues(schema, op, req.Variables); err != nil {
+			respondGraphQLErrors(c, http.StatusBadRequest, gqlerror.List{toGraphQLError(err)})
+			return
+		}
+	}
+
+	zlog.Info().Str("path", route.Path).Str("operation", op.Name).Msg("Resolving graphql operation")
+
+	mock := matchGraphQLOperation(route.Operations, op.Name, req.Variables)
+	switch {
+	case mock == nil && schema != nil:
+		zlog.Info().Str("operation", op.Name).Msg("No mock for operation, generating placeholder data")
+		c.JSON(http.StatusOK, gin.H{"data": graphQLPlaceholderData(schema, op)})
+
+	case mock == nil:
+		zlog.Info().Str("operation", op.Name).Msg("No mock for operation")
+		respondGraphQLErrors(c, http.StatusNotFound, gqlerror.List{gqlerror.Errorf("no mock for operation %q", op.Name)})
+
+	case mock.ScriptName != "":
+		body, err := json.Marshal(req)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		output, err := runDynHandleScript(c.Request.Context(), mock.ScriptName, c.Request.Header, body)
+		switch err {
+		case nil:
+		case coderun.ErrCodeRunFailed:
+			zlog.Warn().Str("output", string(output)).Msg("Failed to run script")
+			respondGraphQLErrors(c, http.StatusOK, gqlerror.List{gqlerror.Errorf("%s", output)})
+			return
+		default:
+			zlog.Error().Err(err).Msg("Worker failed")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		if !json.Valid(output) {
+			zlog.Warn().Str("output", string(output)).Msg("Script returned invalid json")
+			respondGraphQLErrors(c, http.StatusOK, gqlerror.List{gqlerror.Errorf("handler returned invalid json: %s", output)})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": json.RawMessage(output)})
+
+	default:
+		c.JSON(http.StatusOK, gin.H{"data": json.RawMessage(mock.Response)})
+	}
+}
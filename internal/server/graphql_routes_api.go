@@ -0,0 +1,304 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mock-server/internal/database"
+	"mock-server/internal/server/protocol"
+	"mock-server/internal/util"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	zlog "github.com/rs/zerolog/log"
+)
+
+func validateGraphQLEndpoint(endpoint *protocol.GraphQLEndpoint) error {
+	if err := validateRouteWindow(&endpoint.RouteWindow, time.Now()); err != nil {
+		return err
+	}
+
+	if endpoint.Schema == "" && len(endpoint.Operations) == 0 {
+		return errors.New("either schema or operations must be specified")
+	}
+
+	if endpoint.Schema != "" {
+		if _, err := loadGraphQLSchema(endpoint.Schema); err != nil {
+			return fmt.Errorf("invalid schema: %s", err.Error())
+		}
+	}
+
+	for _, op := range endpoint.Operations {
+		if (len(op.Response) == 0) == (op.Code == "") {
+			return fmt.Errorf("operation %s: exactly one of response and code must be specified", op.OperationName)
+		}
+		if len(op.Response) != 0 && !json.Valid(op.Response) {
+			return fmt.Errorf("operation %s: response must be a valid json", op.OperationName)
+		}
+	}
+
+	return nil
+}
+
+// stores dynamic operations handlers and converts operations to database format
+func (s *server) storeGraphQLOperations(operations []protocol.GraphQLOperation) ([]database.GraphQLOperation, error) {
+	stored := make([]database.GraphQLOperation, 0, len(operations))
+
+	for _, op := range operations {
+		storedOp := database.GraphQLOperation{
+			OperationName: op.OperationName,
+			Response:      string(op.Response),
+		}
+
+		if len(op.Variables) != 0 {
+			variables, err := json.Marshal(op.Variables)
+			if err != nil {
+				return nil, err
+			}
+			storedOp.Variables = string(variables)
+		}
+
+		if op.Code != "" {
+			scriptName := util.GenUniqueFilename("py")
+			zlog.Info().
+				Str("operation", op.OperationName).
+				Str("filename", scriptName).
+				Msg("Generated script name")
+
+			if err := s.fs.Write(FS_DYN_HANDLE_DIR, scriptName, util.WrapCodeForDynHandle(op.Code)); err != nil {
+				return nil, err
+			}
+			storedOp.ScriptName = scriptName
+		}
+
+		stored = append(stored, storedOp)
+	}
+
+	return stored, nil
+}
+
+// removes scripts of operations which are not saved or were replaced
+func (s *server) discardGraphQLOperations(operations []database.GraphQLOperation) {
+	s.removeScripts(routeScripts(&database.Route{Operations: operations})...)
+}
+
+func (s *server) loadGraphQLOperations(operations []database.GraphQLOperation) ([]protocol.GraphQLOperation, error) {
+	loaded := make([]protocol.GraphQLOperation, 0, len(operations))
+
+	for _, op := range operations {
+		loadedOp := protocol.GraphQLOperation{
+			OperationName: op.OperationName,
+		}
+
+		if op.Variables != "" {
+			if err := json.Unmarshal([]byte(op.Variables), &loadedOp.Variables); err != nil {
+				return nil, err
+			}
+		}
+
+		if op.ScriptName != "" {
+			code, err := s.fs.Read(FS_DYN_HANDLE_DIR, op.ScriptName)
+			if err != nil {
+				return nil, err
+			}
+			loadedOp.Code = util.UnwrapCodeForDynHandle(code)
+		} else {
+			loadedOp.Response = json.RawMessage(op.Response)
+		}
+
+		loaded = append(loaded, loadedOp)
+	}
+
+	return loaded, nil
+}
+
+// graphql routes (requests are resolved by operation name and variables)
+func (s *server) initRoutesApiGraphQL(routes *gin.RouterGroup) {
+	graphqlRoutesEndpoint := "/graphql"
+
+	routes.GET(graphqlRoutesEndpoint, listRoutePathsHandler(database.GRAPHQL_ENDPOINT_TYPE))
+
+	routes.GET(graphqlRoutesEndpoint+"/config", func(c *gin.Context) {
+		path := c.Query("path")
+		if path == "" {
+			zlog.Error().Msg("Path param not specified")
+			apiError(c, http.StatusBadRequest, nil, "specify path param")
+			return
+		}
+
+		zlog.Info().Str("path", path).Msg("Received get config graphql request")
+
+		route, err := database.GetGraphQLEndpoint(c, path)
+		switch err {
+		case nil:
+			zlog.Info().Str("path", path).Msg("Got graphql route")
+		case database.ErrNoSuchPath, database.ErrBadRouteType:
+			zlog.Error().Msg("Request for unexisting graphql route")
+			apiError(c, http.StatusNotFound, err, "Received path was not created before")
+			return
+		default:
+			zlog.Error().Err(err).Msg("Failed to query graphql route")
+			apiError(c, http.StatusInternalServerError, err, err.Error())
+			return
+		}
+
+		operations, err := s.loadGraphQLOperations(route.Operations)
+		if err != nil {
+			zlog.Error().Err(err).Str("path", path).Msg("Failed to load graphql operations")
+			apiError(c, http.StatusInternalServerError, err, err.Error())
+			return
+		}
+
+		c.JSON(http.StatusOK, protocol.GraphQLEndpoint{
+			Path:        route.Path,
+			Schema:      route.Schema,
+			Operations:  operations,
+			RouteWindow: toProtocolRouteWindow(&route.ActivityWindow),
+		})
+	})
+
+	routes.POST(graphqlRoutesEndpoint, func(c *gin.Context) {
+		var graphqlEndpoint protocol.GraphQLEndpoint
+		if err := c.Bind(&graphqlEndpoint); err != nil {
+			zlog.Error().Err(err).Msg("Failed to bind request")
+			apiError(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
+
+		zlog.Info().Str("path", graphqlEndpoint.Path).Msg("Received create graphql request")
+
+		if err := validateGraphQLEndpoint(&graphqlEndpoint); err != nil {
+			zlog.Error().Err(err).Msg("Invalid graphql endpoint")
+			apiError(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
+
+		operations, err := s.storeGraphQLOperations(graphqlEndpoint.Operations)
+		if err != nil {
+			zlog.Error().Err(err).Msg("Failed to store graphql operations")
+			apiError(c, http.StatusInternalServerError, err, err.Error())
+			return
+		}
+
+		err = database.AddGraphQLEndpoint(
+			c,
+			graphqlEndpoint.Path,
+			graphqlEndpoint.Schema,
+			operations,
+			routeWindowOption(&graphqlEndpoint.RouteWindow),
+		)
+
+		if err != nil {
+			s.discardGraphQLOperations(operations)
+		}
+
+		switch err {
+		case nil:
+			zlog.Info().Str("path", graphqlEndpoint.Path).Msg("GraphQL endpoint created")
+			c.JSON(http.StatusOK, "GraphQL endpoint successfully added!")
+		case database.ErrDuplicateKey:
+			zlog.Error().Str("path", graphqlEndpoint.Path).Msg("Endpoint with this path already exists")
+			apiError(c, http.StatusConflict, err, "The same endpoint already exists")
+		default:
+			zlog.Error().Err(err).Msg("Failed to add graphql endpoint")
+			apiError(c, http.StatusInternalServerError, err, err.Error())
+		}
+	})
+
+	routes.PUT(graphqlRoutesEndpoint, func(c *gin.Context) {
+		var graphqlEndpoint protocol.GraphQLEndpoint
+		if err := c.Bind(&graphqlEndpoint); err != nil {
+			zlog.Error().Err(err).Msg("Failed to bind request")
+			apiError(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
+
+		zlog.Info().Str("path", graphqlEndpoint.Path).Msg("Received update graphql request")
+
+		if err := validateGraphQLEndpoint(&graphqlEndpoint); err != nil {
+			zlog.Error().Err(err).Msg("Invalid graphql endpoint")
+			apiError(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
+
+		replaced, err := database.GetGraphQLEndpoint(c, graphqlEndpoint.Path)
+		switch err {
+		case nil:
+		case database.ErrNoSuchPath, database.ErrBadRouteType:
+			zlog.Error().Msg("Update on unexisting path")
+			apiError(c, http.StatusNotFound, err, "Received path was not created before")
+			return
+		default:
+			zlog.Error().Err(err).Msg("Failed to query graphql route")
+			apiError(c, http.StatusInternalServerError, err, err.Error())
+			return
+		}
+
+		operations, err := s.storeGraphQLOperations(graphqlEndpoint.Operations)
+		if err != nil {
+			zlog.Error().Err(err).Msg("Failed to store graphql operations")
+			apiError(c, http.StatusInternalServerError, err, err.Error())
+			return
+		}
+
+		err = database.UpdateGraphQLEndpoint(
+			c,
+			graphqlEndpoint.Path,
+			graphqlEndpoint.Schema,
+			operations,
+			routeWindowOption(&graphqlEndpoint.RouteWindow),
+		)
+		if err != nil {
+			s.discardGraphQLOperations(operations)
+		} else {
+			s.discardGraphQLOperations(replaced.Operations)
+		}
+
+		switch err {
+		case nil:
+			zlog.Info().Str("path", graphqlEndpoint.Path).Msg("GraphQL endpoint updated")
+			c.JSON(http.StatusNoContent, "GraphQL endpoint successfully updated!")
+		case database.ErrNoSuchPath:
+			zlog.Error().Msg("Update on unexisting path")
+			apiError(c, http.StatusNotFound, err, "Received path was not created before")
+		default:
+			zlog.Error().Err(err).Msg("Failed to update graphql endpoint")
+			apiError(c, http.StatusInternalServerError, err, err.Error())
+		}
+	})
+
+	routes.DELETE(graphqlRoutesEndpoint, func(c *gin.Context) {
+		path := c.Query("path")
+		if path == "" {
+			zlog.Error().Msg("Path param not specified")
+			apiError(c, http.StatusBadRequest, nil, "specify path param")
+			return
+		}
+
+		zlog.Info().Str("path", path).Msg("Received delete graphql request")
+
+		route, err := database.GetGraphQLEndpoint(c, path)
+		switch err {
+		case nil:
+		case database.ErrNoSuchPath, database.ErrBadRouteType:
+			zlog.Error().Msg("Delete on unexisting path")
+			apiError(c, http.StatusNotFound, err, "Received path was not created before")
+			return
+		default:
+			zlog.Error().Err(err).Msg("Failed to query graphql route")
+			apiError(c, http.StatusInternalServerError, err, err.Error())
+			return
+		}
+
+		if err := database.RemoveGraphQLEndpoint(c, path); err != nil {
+			zlog.Error().Err(err).Msg("Failed to remove graphql endpoint")
+			apiError(c, http.StatusInternalServerError, err, err.Error())
+			return
+		}
+		s.discardGraphQLOperations(route.Operations)
+
+		zlog.Info().Str("path", path).Msg("GraphQL endpoint removed")
+		c.JSON(http.StatusNoContent, "GraphQL endpoint successfully removed!")
+	})
+}
@@ -0,0 +1,142 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"mock-server/internal/brokers"
+	"mock-server/internal/coderun"
+	"mock-server/internal/configs"
+	"mock-server/internal/database"
+	"mock-server/internal/server/protocol"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	zlog "github.com/rs/zerolog/log"
+)
+
+// timeout of all component checks of one request
+const HEALTH_CHECK_TIMEOUT = 3 * time.Second
+
+type healthCheck struct {
+	name string
+	// service can not serve mocks at all without critical component
+	critical bool
+	check    func(ctx context.Context) protocol.ComponentHealth
+}
+
+func healthFromError(err error) protocol.ComponentHealth {
+	if err != nil {
+		return protocol.ComponentHealth{Status: protocol.HEALTH_STATUS_DOWN, Error: err.Error()}
+	}
+	return protocol.ComponentHealth{Status: protocol.HEALTH_STATUS_UP}
+}
+
+func checkCoderun(context.Context) protocol.ComponentHealth {
+	running, total := coderun.WorkerWatcher.RunningWorkers()
+
+	health := healthFromError(nil)
+	if running == 0 {
+		health = healthFromError(fmt.Errorf("no running workers"))
+	}
+	health.RunningWorkers = &running
+	health.Workers = &total
+	return health
+}
+
+// checks of enabled components, database is always used
+func enabledHealthChecks(cfg *configs.ComponentsConfig) []healthCheck {
+	checks := []healthCheck{{
+		name:     "database",
+		critical: true,
+		check: func(ctx context.Context) protocol.ComponentHealth {
+			return healthFromError(database.Ping(ctx))
+		},
+	}}
+
+	if cfg.Brokers {
+		checks = append(checks,
+			healthCheck{
+				name: "rabbitmq",
+				check: func(ctx context.Context) protocol.ComponentHealth {
+					return healthFromError(brokers.PingRabbitMQ(ctx))
+				},
+			},
+			healthCheck{
+				name: "kafka",
+				check: func(ctx context.Context) protocol.ComponentHealth {
+					return healthFromError(brokers.PingKafka(ctx))
+				},
+			},
+		)
+	}
+	if cfg.Coderun {
+		checks = append(checks, healthCheck{name: "coderun", check: checkCoderun})
+	}
+
+	return checks
+}
+
+// runs checks concurrently, returns report and whether critical components are up
+func checkHealth(ctx context.Context, checks []healthCheck) (protocol.HealthReport, bool) {
+	ctx, cancel := context.WithTimeout(ctx, HEALTH_CHECK_TIMEOUT)
+	defer cancel()
+
+	results := make([]protocol.ComponentHealth, len(checks))
+
+	var wg sync.WaitGroup
+	for i := range checks {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = checks[i].check(ctx)
+		}(i)
+	}
+	wg.Wait()
+
+	report := protocol.HealthReport{
+		Status:     protocol.HEALTH_STATUS_UP,
+		Components: make(map[string]protocol.ComponentHealth, len(checks)),
+	}
+	alive := true
+	for i, check := range checks {
+		report.Components[check.name] = results[i]
+		if results[i].Status == protocol.HEALTH_STATUS_DOWN {
+			zlog.Warn().Str("component", check.name).Str("error", results[i].Error).Msg("Component is down")
+			report.Status = protocol.HEALTH_STATUS_DOWN
+			alive = alive && !check.critical
+		}
+	}
+
+	return report, alive
+}
+
+// health endpoints are not protected like ping, so probes need no credentials
+func (s *server) initHealthRoutes() {
+	components := s.components
+	if components == nil {
+		components = configs.GetComponentsConfig()
+	}
+	checks := enabledHealthChecks(components)
+
+	// liveness: fails only without critical components
+	s.router.GET("/healthz", func(c *gin.Context) {
+		report, alive := checkHealth(c.Request.Context(), checks)
+		if !alive {
+			c.JSON(http.StatusServiceUnavailable, report)
+			return
+		}
+		c.JSON(http.StatusOK, report)
+	})
+
+	// readiness: fails if any enabled component is down
+	s.router.GET("/readyz", func(c *gin.Context) {
+		report, _ := checkHealth(c.Request.Context(), checks)
+		if report.Status != protocol.HEALTH_STATUS_UP {
+			c.JSON(http.StatusServiceUnavailable, report)
+			return
+		}
+		c.JSON(http.StatusOK, report)
+	})
+}
@@ -0,0 +1,89 @@
+package server
+
+import (
+	"mock-server/internal/database"
+	"mock-server/internal/server/protocol"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	zlog "github.com/rs/zerolog/log"
+)
+
+// v1 lists return all items unless client asks for pages,
+// v2 lists are always paged
+func pageLimit(c *gin.Context, query *protocol.ListQuery) int64 {
+	if query.Limit == 0 && (query.Cursor != "" || isApiV2(c)) {
+		return database.DEFAULT_PAGE_LIMIT
+	}
+	return query.Limit
+}
+
+func toDatabaseListQuery(c *gin.Context, query *protocol.ListQuery) database.ListQuery {
+	sortBy := query.Sort
+	if sortBy == protocol.LIST_SORT_CREATED {
+		sortBy = database.ID_FIELD
+	}
+
+	return database.ListQuery{
+		Limit:  pageLimit(c, query),
+		Cursor: query.Cursor,
+		SortBy: sortBy,
+		Desc:   query.Order == protocol.LIST_ORDER_DESC,
+		Prefix: query.Prefix,
+		Search: query.Search,
+		Broker: query.Broker,
+		Type:   query.Type,
+	}
+}
+
+// responds 400 and returns false if list query params are invalid
+func bindListQuery(c *gin.Context) (database.ListQuery, bool) {
+	var query protocol.ListQuery
+	if err := c.BindQuery(&query); err != nil {
+		zlog.Error().Err(err).Msg("Invalid list query")
+		apiError(c, http.StatusBadRequest, err, err.Error())
+		return database.ListQuery{}, false
+	}
+	return toDatabaseListQuery(c, &query), true
+}
+
+// responds to failed page query
+func listPageError(c *gin.Context, err error) {
+	switch err {
+	case database.ErrInvalidCursor, database.ErrInvalidSort:
+		zlog.Error().Err(err).Msg("Invalid list query")
+		apiError(c, http.StatusBadRequest, err, err.Error())
+	default:
+		zlog.Error().Err(err).Msg("Failed to query page")
+		apiError(c, http.StatusInternalServerError, err, err.Error())
+	}
+}
+
+// next_cursor is set only if there are more items
+func pageResponse(key string, items interface{}, nextCursor string) gin.H {
+	resp := gin.H{key: items}
+	if nextCursor != "" {
+		resp["next_cursor"] = nextCursor
+	}
+	return resp
+}
+
+// lists paths of mock routes of one type
+func listRoutePathsHandler(routeType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		zlog.Info().Str("type", routeType).Msg("List routes request")
+
+		query, ok := bindListQuery(c)
+		if !ok {
+			return
+		}
+
+		page, err := database.FindRoutePaths(c, routeType, query)
+		if err != nil {
+			listPageError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, pageResponse("endpoints", page.Items, page.NextCursor))
+	}
+}
@@ -0,0 +1,164 @@
+package server
+
+import (
+	"mock-server/internal/chaos"
+	"mock-server/internal/database"
+	"mock-server/internal/server/protocol"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	zlog "github.com/rs/zerolog/log"
+)
+
+func validateNamespace(namespace *protocol.Namespace) error {
+	if namespace.Cors != nil {
+		if err := validateCorsPolicy(namespace.Cors); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func toDatabaseNamespace(namespace *protocol.Namespace) database.Namespace {
+	return database.Namespace{
+		Prefix:    namespace.Prefix,
+		Cors:      toDatabaseCorsPolicy(namespace.Cors),
+		RateLimit: toDatabaseRateLimitPolicy(namespace.RateLimit),
+	}
+}
+
+func toProtocolNamespace(namespace *database.Namespace) protocol.Namespace {
+	return protocol.Namespace{
+		Prefix:    namespace.Prefix,
+		Cors:      toProtocolCorsPolicy(namespace.Cors),
+		RateLimit: toProtocolRateLimitPolicy(namespace.RateLimit),
+	}
+}
+
+// namespaces (policies shared by mock routes under path prefix)
+func (s *server) initNamespacesApi(api *gin.RouterGroup) {
+	namespacesEndpoint := "/namespaces"
+
+	api.GET(namespacesEndpoint, func(c *gin.Context) {
+		zlog.Info().Msg("Get all namespaces request")
+
+		namespaces, err := database.ListNamespaces(c)
+		if err != nil {
+			zlog.Error().Err(err).Msg("Failed to list namespaces")
+			apiError(c, http.StatusInternalServerError, err, err.Error())
+			return
+		}
+
+		converted := make([]protocol.Namespace, len(namespaces))
+		for i := range namespaces {
+			converted[i] = toProtocolNamespace(&namespaces[i])
+		}
+
+		c.JSON(http.StatusOK, gin.H{"namespaces": converted})
+	})
+
+	api.GET(namespacesEndpoint+"/config", func(c *gin.Context) {
+		prefix := c.Query("prefix")
+		if prefix == "" {
+			zlog.Error().Msg("Prefix param not specified")
+			apiError(c, http.StatusBadRequest, nil, "specify prefix param")
+			return
+		}
+
+		zlog.Info().Str("prefix", prefix).Msg("Received get namespace request")
+
+		namespace, err := database.GetNamespace(c, prefix)
+		switch err {
+		case nil:
+			c.JSON(http.StatusOK, toProtocolNamespace(&namespace))
+		case database.ErrNoSuchNamespace:
+			zlog.Error().Msg("Request for unexisting namespace")
+			apiError(c, http.StatusNotFound, err, "Received prefix was not created before")
+		default:
+			zlog.Error().Err(err).Msg("Failed to query namespace")
+			apiError(c, http.StatusInternalServerError, err, err.Error())
+		}
+	})
+
+	api.POST(namespacesEndpoint, func(c *gin.Context) {
+		var namespace protocol.Namespace
+		if err := c.Bind(&namespace); err != nil {
+			zlog.Error().Err(err).Msg("Failed to bind request")
+			apiError(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
+
+		zlog.Info().Str("prefix", namespace.Prefix).Msg("Received create namespace request")
+
+		if err := validateNamespace(&namespace); err != nil {
+			zlog.Error().Err(err).Msg("Invalid namespace")
+			apiError(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
+
+		err := database.AddNamespace(c, toDatabaseNamespace(&namespace))
+		switch err {
+		case nil:
+			zlog.Info().Str("prefix", namespace.Prefix).Msg("Namespace created")
+			c.JSON(http.StatusOK, "Namespace successfully added!")
+		case database.ErrDuplicateKey:
+			zlog.Error().Str("prefix", namespace.Prefix).Msg("Namespace with this prefix already exists")
+			apiError(c, http.StatusConflict, err, "The same namespace already exists")
+		default:
+			zlog.Error().Err(err).Msg("Failed to add namespace")
+			apiError(c, http.StatusInternalServerError, err, err.Error())
+		}
+	})
+
+	api.PUT(namespacesEndpoint, func(c *gin.Context) {
+		var namespace protocol.Namespace
+		if err := c.Bind(&namespace); err != nil {
+			zlog.Error().Err(err).Msg("Failed to bind request")
+			apiError(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
+
+		zlog.Info().Str("prefix", namespace.Prefix).Msg("Received update namespace request")
+
+		if err := validateNamespace(&namespace); err != nil {
+			zlog.Error().Err(err).Msg("Invalid namespace")
+			apiError(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
+
+		err := database.UpdateNamespace(c, toDatabaseNamespace(&namespace))
+		switch err {
+		case nil:
+			zlog.Info().Str("prefix", namespace.Prefix).Msg("Namespace updated")
+			c.JSON(http.StatusNoContent, "Namespace successfully updated!")
+		case database.ErrNoSuchNamespace:
+			zlog.Error().Msg("Update on unexisting namespace")
+			apiError(c, http.StatusNotFound, err, "Received prefix was not created before")
+		default:
+			zlog.Error().Err(err).Msg("Failed to update namespace")
+			apiError(c, http.StatusInternalServerError, err, err.Error())
+		}
+	})
+
+	api.DELETE(namespacesEndpoint, func(c *gin.Context) {
+		prefix := c.Query("prefix")
+		if prefix == "" {
+			zlog.Error().Msg("Prefix param not specified")
+			apiError(c, http.StatusBadRequest, nil, "specify prefix param")
+			return
+		}
+
+		zlog.Info().Str("prefix", prefix).Msg("Received delete namespace request")
+
+		if err := database.RemoveNamespace(c, prefix); err != nil {
+			zlog.Error().Err(err).Msg("Failed to remove namespace")
+			apiError(c, http.StatusInternalServerError, err, err.Error())
+			return
+		}
+		// otherwise profile would apply to namespace created with the same prefix
+		chaos.RemoveProfile(prefix)
+
+		zlog.Info().Str("prefix", prefix).Msg("Namespace removed")
+		c.JSON(http.StatusNoContent, "Namespace successfully removed!")
+	})
+}
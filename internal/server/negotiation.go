@@ -0,0 +1,90 @@
+package server
+
+import (
+	"mime"
+	"strconv"
+	"strings"
+)
+
+// media range of accept header
+type acceptRange struct {
+	mediaType string
+	subType   string
+	quality   float64
+}
+
+var acceptAnything = []acceptRange{{mediaType: "*", subType: "*", quality: 1}}
+
+// ranges with invalid media types are skipped, missing or malformed header accepts anything
+func parseAccept(header string) []acceptRange {
+	ranges := make([]acceptRange, 0)
+	for _, part := range strings.Split(header, ",") {
+		mediaRange, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		mediaType, subType, ok := strings.Cut(mediaRange, "/")
+		if !ok || (mediaType == "*" && subType != "*") {
+			continue
+		}
+
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil || quality < 0 || quality > 1 {
+				continue
+			}
+		}
+		ranges = append(ranges, acceptRange{mediaType: mediaType, subType: subType, quality: quality})
+	}
+
+	if len(ranges) == 0 {
+		return acceptAnything
+	}
+	return ranges
+}
+
+// 2 for exact match, 1 for type/*, 0 for */*, -1 if range does not match
+func (r *acceptRange) specificity(contentType string) int {
+	mediaType, subType, _ := strings.Cut(contentType, "/")
+	switch {
+	case r.mediaType == "*":
+		return 0
+	case r.mediaType != mediaType:
+		return -1
+	case r.subType == "*":
+		return 1
+	case r.subType == subType:
+		return 2
+	}
+	return -1
+}
+
+// quality of content type given by the most specific matching range, 0 if none matches
+func acceptQuality(ranges []acceptRange, contentType string) float64 {
+	mediaRange, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return 0
+	}
+
+	best, quality := -1, 0.0
+	for i := range ranges {
+		if specificity := ranges[i].specificity(mediaRange); specificity > best {
+			best, quality = specificity, ranges[i].quality
+		}
+	}
+	return quality
+}
+
+// index of offered content type with the highest quality, earlier offers win ties,
+// -1 if request accepts none of them
+func negotiateContentType(accept string, offers []string) int {
+	ranges := parseAccept(accept)
+
+	chosen, chosenQuality := -1, 0.0
+	for i, offer := range offers {
+		if quality := acceptQuality(ranges, offer); quality > chosenQuality {
+			chosen, chosenQuality = i, quality
+		}
+	}
+	return chosen
+}
@@ -2,37 +2,90 @@ package server
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"mock-server/internal/coderun"
 	"mock-server/internal/database"
+	"mock-server/internal/metrics"
+	"mock-server/internal/tracing"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog"
 	zlog "github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func (s *server) initNoRoute() {
 	s.router.NoRoute(func(c *gin.Context) {
+		start := time.Now()
 		path := c.Request.RequestURI
 		zlog.Info().Str("path", path).Msg("Received path")
 
-		route, err := database.GetRoute(c, path)
+		// continues trace of the caller if request carries one
+		ctx, span := tracing.Start(tracing.Extract(c.Request.Context(), c.Request.Header), "mock dispatch",
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.method", c.Request.Method),
+				attribute.String("http.target", path),
+			),
+		)
+		defer func() {
+			status := c.Writer.Status()
+			span.SetAttributes(attribute.Int("http.status_code", status))
+			if status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(status))
+			}
+			span.End()
+		}()
+		c.Request = c.Request.WithContext(ctx)
+
+		route, err := lookupRoute(c)
+		if err == nil && !route.IsActive(time.Now()) {
+			// routes outside of activity window are invisible for clients
+			zlog.Info().Str("path", path).Msg("Route is not active")
+			err = database.ErrNoSuchPath
+		}
 		if err == database.ErrNoSuchPath {
 			zlog.Info().Str("path", path).Msg("No such path")
 			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("no such path: %s", path)})
+			metrics.ObserveMockRequest(metrics.UNMATCHED_ROUTE, metrics.UNMATCHED_ROUTE, c.Writer.Status(), time.Since(start))
 			return
 		}
 		if err != nil {
 			zlog.Error().Err(err).Msg("Failed to find path")
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			metrics.ObserveMockRequest(metrics.UNMATCHED_ROUTE, metrics.UNMATCHED_ROUTE, c.Writer.Status(), time.Since(start))
 			return
 		}
 		zlog.Debug().Interface("route", route).Msg("Queried")
+		span.SetAttributes(attribute.String("route.path", route.Path), attribute.String("route.type", route.Type))
+
+		// also counts requests rejected by route policies
+		database.RecordRouteHit(c, route.Path)
+		defer func() {
+			metrics.ObserveMockRequest(route.Path, route.Type, c.Writer.Status(), time.Since(start))
+		}()
+
+		policies, err := resolveRoutePolicies(c, &route)
+		if err != nil {
+			zlog.Error().Err(err).Msg("Failed to resolve route policies")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if s.applyRoutePolicies(c, &policies); c.IsAborted() {
+			return
+		}
+		if s.applyChaos(c, &route); c.IsAborted() {
+			return
+		}
 
 		switch route.Type {
 		case database.STATIC_ENDPOINT_TYPE:
@@ -44,6 +97,18 @@ func (s *server) initNoRoute() {
 		case database.DYNAMIC_ENDPOINT_TYPE:
 			s.handleDynamicRouteRequest(c, &route)
 
+		case database.GRAPHQL_ENDPOINT_TYPE:
+			s.handleGraphQLRouteRequest(c, &route)
+
+		case database.WEBSOCKET_ENDPOINT_TYPE:
+			s.handleWebSocketRouteRequest(c, &route)
+
+		case database.STREAM_ENDPOINT_TYPE:
+			s.handleStreamRouteRequest(c, &route)
+
+		case database.FILE_ENDPOINT_TYPE:
+			s.handleFileRouteRequest(c, &route)
+
 		default:
 			zlog.Fatal().Msg(fmt.Sprintf("Can't resolve route type: %s", route.Type))
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Can't resolve route type"})
@@ -51,8 +116,50 @@ func (s *server) initNoRoute() {
 	})
 }
 
+// route types that receive their arguments in the query string
+// and therefore are matched by the path without it
+var queryAgnosticRouteTypes = map[string]bool{
+	database.GRAPHQL_ENDPOINT_TYPE:   true,
+	database.WEBSOCKET_ENDPOINT_TYPE: true,
+}
+
+func lookupRoute(c *gin.Context) (database.Route, error) {
+	route, err := database.GetRoute(c.Request.Context(), c.Request.RequestURI)
+	if err != database.ErrNoSuchPath || c.Request.URL.RawQuery == "" {
+		return route, err
+	}
+
+	route, err = database.GetRoute(c.Request.Context(), c.Request.URL.Path)
+	if err != nil {
+		return route, err
+	}
+	if !queryAgnosticRouteTypes[route.Type] {
+		return database.Route{}, database.ErrNoSuchPath
+	}
+	return route, nil
+}
+
 func (s *server) handleStaticRouteRequest(c *gin.Context, route *database.Route) {
-	c.JSON(http.StatusOK, route.Response)
+	if len(route.Representations) == 0 {
+		c.JSON(http.StatusOK, route.Response)
+		return
+	}
+
+	offers := make([]string, len(route.Representations))
+	for i := range route.Representations {
+		offers[i] = route.Representations[i].ContentType
+	}
+
+	// caches must not serve representation chosen for other accept header
+	c.Writer.Header().Add("Vary", "Accept")
+
+	chosen := negotiateContentType(c.GetHeader("Accept"), offers)
+	if chosen < 0 {
+		zlog.Info().Str("path", route.Path).Str("accept", c.GetHeader("Accept")).Msg("No acceptable representation")
+		c.JSON(http.StatusNotAcceptable, gin.H{"error": "no representation of accepted media types", "available": offers})
+		return
+	}
+	c.Data(http.StatusOK, offers[chosen], []byte(route.Representations[chosen].Body))
 }
 
 func (s *server) handleProxyRouteRequest(c *gin.Context, route *database.Route) {
@@ -66,10 +173,17 @@ func (s *server) handleProxyRouteRequest(c *gin.Context, route *database.Route)
 		return
 	}
 
+	ctx, span := tracing.Start(c.Request.Context(), "proxy upstream",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("proxy.url", route.ProxyURL)),
+	)
+	defer span.End()
+
 	proxy := httputil.NewSingleHostReverseProxy(target)
 	proxy.Director = func(req *http.Request) {
 		req.Header = c.Request.Header
 		req.Method = c.Request.Method
+		tracing.Inject(ctx, req.Header)
 
 		req.Host = target.Host
 		req.URL = target
@@ -116,35 +230,43 @@ func (s *server) handleProxyRouteRequest(c *gin.Context, route *database.Route)
 		}
 	}
 
+	proxy.ErrorHandler = func(w http.ResponseWriter, req *http.Request, err error) {
+		zlog.Error().Err(err).Str("proxy url", route.ProxyURL).Msg("Proxy upstream request failed")
+		metrics.IncProxyUpstreamError(route.Path)
+		tracing.RecordError(span, err)
+		w.WriteHeader(http.StatusBadGateway)
+	}
+
 	proxy.ServeHTTP(c.Writer, c.Request)
+	span.SetAttributes(attribute.Int("http.status_code", c.Writer.Status()))
 }
 
-func (s *server) handleDynamicRouteRequest(c *gin.Context, route *database.Route) {
-	worker, err := coderun.WorkerWatcher.BorrowWorker()
+func runDynHandleScript(ctx context.Context, scriptName string, headers http.Header, body []byte) ([]byte, error) {
+	worker, err := coderun.WorkerWatcher.BorrowWorker(ctx)
 	if err != nil {
 		zlog.Error().Err(err).Msg("Failed to borrow worker")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
+		return nil, err
 	}
 	defer worker.Return()
 
-	headers := c.Request.Header.Clone()
-	headersBytes, err := json.Marshal(headers)
+	headersBytes, err := json.Marshal(headers.Clone())
 	if err != nil {
 		zlog.Error().Err(err).Msg("Failed to parse headers")
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
+		return nil, err
 	}
 
-	bodyBytes, err := io.ReadAll(c.Request.Body)
+	return worker.RunScript(ctx, FS_DYN_HANDLE_DIR, scriptName, coderun.NewDynHandleArgs(headersBytes, body))
+}
+
+func (s *server) handleDynamicRouteRequest(c *gin.Context, route *database.Route) {
+	bodyBytes, err := s.dynHandleBody(c)
 	if err != nil {
 		zlog.Error().Err(err).Msg("Failed to read request body")
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	defer c.Request.Body.Close()
 
-	output, err := worker.RunScript(FS_DYN_HANDLE_DIR, route.ScriptName, coderun.NewDynHandleArgs(headersBytes, bodyBytes))
+	output, err := runDynHandleScript(c.Request.Context(), route.ScriptName, c.Request.Header, bodyBytes)
 	switch err {
 	case nil:
 		c.JSON(http.StatusOK, string(output))
@@ -152,7 +274,7 @@ func (s *server) handleDynamicRouteRequest(c *gin.Context, route *database.Route
 		zlog.Warn().Str("output", string(output)).Msg("Failed to run script")
 		c.JSON(http.StatusBadRequest, gin.H{"error": string(output)})
 	default:
-		zlog.Error().Str("output", string(output)).Msg("Worker failed")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": string(output)})
+		zlog.Error().Err(err).Str("output", string(output)).Msg("Worker failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 	}
 }
@@ -0,0 +1,146 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	SECURITY_BEARER = "bearer"
+	SECURITY_BASIC  = "basic"
+)
+
+const errorSchemaName = "Error"
+
+type QueryParam struct {
+	Name        string
+	Description string
+	Required    bool
+}
+
+// description of one admin api endpoint, bodies are sample values
+// of the types that are bound from request and written to response
+type Endpoint struct {
+	Method  string
+	Path    string
+	Tag     string
+	Summary string
+	Query   []QueryParam
+	// nil if endpoint has no request body
+	Body interface{}
+	// body is sent as multipart form instead of json
+	Multipart bool
+	// success status, 200 if not set
+	Status int
+	// nil if response has no body
+	Response interface{}
+	// client error statuses besides authentication ones
+	Errors []int
+	// accessible without credentials
+	Public bool
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func New(title string, version string, description string) *Document {
+	d := &Document{
+		OpenAPI: VERSION,
+		Info: Info{
+			Title:       title,
+			Description: description,
+			Version:     version,
+		},
+		Paths: make(map[string]PathItem),
+		Components: Components{
+			Schemas: make(map[string]*Schema),
+			SecuritySchemes: map[string]SecurityScheme{
+				SECURITY_BEARER: {Type: "http", Scheme: "bearer", Description: "token from auth.tokens of server config"},
+				SECURITY_BASIC:  {Type: "http", Scheme: "basic", Description: "user from auth.users of server config"},
+			},
+		},
+		// credentials are checked only if auth is configured on the server
+		Security: []SecurityRequirement{{SECURITY_BEARER: {}}, {SECURITY_BASIC: {}}},
+	}
+	d.Components.Schemas[errorSchemaName] = d.structSchema(reflect.TypeOf(errorResponse{}))
+	return d
+}
+
+func jsonContent(schema *Schema) map[string]MediaType {
+	return map[string]MediaType{"application/json": {Schema: schema}}
+}
+
+// operation id from method and path, e.g. GET /api/routes/static/config -> getRoutesStaticConfig
+func operationId(method string, path string) string {
+	var id strings.Builder
+	id.WriteString(strings.ToLower(method))
+	for _, segment := range strings.Split(strings.TrimPrefix(path, "/api"), "/") {
+		for _, word := range strings.Split(segment, "_") {
+			if word != "" {
+				id.WriteString(strings.ToUpper(word[:1]) + word[1:])
+			}
+		}
+	}
+	return id.String()
+}
+
+func (d *Document) Add(e Endpoint) {
+	op := &Operation{
+		Summary:     e.Summary,
+		OperationId: operationId(e.Method, e.Path),
+		Responses:   make(map[string]Response),
+	}
+	if e.Tag != "" {
+		op.Tags = []string{e.Tag}
+	}
+
+	for _, param := range e.Query {
+		op.Parameters = append(op.Parameters, Parameter{
+			Name:        param.Name,
+			In:          "query",
+			Description: param.Description,
+			Required:    param.Required,
+			Schema:      &Schema{Type: "string"},
+		})
+	}
+
+	if e.Body != nil {
+		content := jsonContent(d.schemaOf(reflect.TypeOf(e.Body)))
+		if e.Multipart {
+			content = map[string]MediaType{"multipart/form-data": content["application/json"]}
+		}
+		op.RequestBody = &RequestBody{Required: true, Content: content}
+	}
+
+	status := e.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := Response{Description: http.StatusText(status)}
+	if e.Response != nil && status != http.StatusNoContent {
+		success.Content = jsonContent(d.schemaOf(reflect.TypeOf(e.Response)))
+	}
+	op.Responses[strconv.Itoa(status)] = success
+
+	errorStatuses := append([]int{}, e.Errors...)
+	if e.Public {
+		op.Security = &[]SecurityRequirement{}
+	} else {
+		errorStatuses = append(errorStatuses, http.StatusUnauthorized, http.StatusForbidden)
+	}
+	errorStatuses = append(errorStatuses, http.StatusInternalServerError)
+	for _, errorStatus := range errorStatuses {
+		op.Responses[strconv.Itoa(errorStatus)] = Response{
+			Description: http.StatusText(errorStatus),
+			Content:     jsonContent(refTo(errorSchemaName)),
+		}
+	}
+
+	if d.Paths[e.Path] == nil {
+		d.Paths[e.Path] = make(PathItem)
+	}
+	d.Paths[e.Path][strings.ToLower(e.Method)] = op
+}
@@ -0,0 +1,166 @@
+package openapi
+
+import (
+	"encoding/json"
+	"mime/multipart"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+	fileType       = reflect.TypeOf(multipart.FileHeader{})
+)
+
+func refTo(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// schema of value of type t, named structs are placed into components
+// and referenced, so each protocol struct is described once
+func (d *Document) schemaOf(t reflect.Type) *Schema {
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == rawMessageType:
+		return &Schema{Description: "any json value"}
+	case t == fileType:
+		return &Schema{Type: "string", Format: "binary"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return d.schemaOf(t.Elem())
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: d.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: d.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return d.structSchema(t)
+		}
+		if _, ok := d.Components.Schemas[t.Name()]; !ok {
+			// placeholder breaks recursion of self referencing types
+			d.Components.Schemas[t.Name()] = &Schema{}
+			d.Components.Schemas[t.Name()] = d.structSchema(t)
+		}
+		return refTo(t.Name())
+	default:
+		// interface{} accepts anything
+		return &Schema{}
+	}
+}
+
+func (d *Document) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	d.addFields(schema, t)
+	return schema
+}
+
+func (d *Document) addFields(schema *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		// fields of embedded structs are inlined like encoding/json does
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			d.addFields(schema, field.Type)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		fieldSchema := d.schemaOf(field.Type)
+		if applyBinding(fieldSchema, field.Type, field.Tag.Get("binding")) {
+			schema.Required = append(schema.Required, name)
+		}
+		schema.Properties[name] = fieldSchema
+	}
+}
+
+// applies validator rules of gin binding tag to schema of a field,
+// rules after "dive" apply to elements and are skipped;
+// returns whether field is required
+func applyBinding(schema *Schema, t reflect.Type, binding string) bool {
+	if binding == "" {
+		return false
+	}
+	if schema.Ref != "" {
+		// constraints can not be placed next to reference
+		return strings.Contains(binding, "required") && !strings.Contains(binding, "required_if")
+	}
+
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	required := false
+	for _, rule := range strings.Split(binding, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "dive":
+			return required
+		case "required":
+			required = true
+		case "required_if":
+			if field, value, ok := strings.Cut(param, " "); ok {
+				schema.Description = "required if " + field + " is " + value
+			}
+		case "startswith":
+			schema.Pattern = "^" + regexp.QuoteMeta(param)
+		case "oneof":
+			schema.Enum = strings.Fields(param)
+		case "min", "max":
+			n, err := strconv.ParseInt(param, 10, 64)
+			if err != nil {
+				continue
+			}
+			setLimit(schema, t, name == "min", n)
+		}
+	}
+	return required
+}
+
+func setLimit(schema *Schema, t reflect.Type, min bool, n int64) {
+	var limit **int64
+	switch t.Kind() {
+	case reflect.String:
+		limit = &schema.MaxLength
+		if min {
+			limit = &schema.MinLength
+		}
+	case reflect.Slice, reflect.Array, reflect.Map:
+		limit = &schema.MaxItems
+		if min {
+			limit = &schema.MinItems
+		}
+	default:
+		limit = &schema.Maximum
+		if min {
+			limit = &schema.Minimum
+		}
+	}
+	*limit = &n
+}
@@ -0,0 +1,9 @@
+package openapi
+
+import _ "embed"
+
+// swagger ui page that loads openapi.json next to it,
+// ui assets are fetched from unpkg by the browser
+//
+//go:embed viewer.html
+var Viewer []byte
@@ -0,0 +1,586 @@
+package server
+
+import (
+	"encoding/json"
+	"mock-server/internal/server/openapi"
+	"mock-server/internal/server/protocol"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	zlog "github.com/rs/zerolog/log"
+)
+
+const API_VERSION = "1.0.0"
+
+var (
+	pathQuery   = openapi.QueryParam{Name: "path", Description: "path of mock route", Required: true}
+	prefixQuery = openapi.QueryParam{Name: "prefix", Description: "path prefix of namespace", Required: true}
+	poolQuery   = openapi.QueryParam{Name: "pool", Description: "name of message pool", Required: true}
+	poolInQuery = openapi.QueryParam{Name: "pool_in", Description: "name of in-pool of esb record", Required: true}
+)
+
+// paging, sorting and filtering params of list endpoint,
+// sort accepts created and listed fields
+func listQueryParams(sortFields string, filters ...openapi.QueryParam) []openapi.QueryParam {
+	params := []openapi.QueryParam{
+		{Name: "limit", Description: "page size, 1..1000; all items if omitted, 100 under /api/v2 or with cursor"},
+		{Name: "cursor", Description: "next_cursor of previous page"},
+		{Name: "sort", Description: "sort field: created (default), " + sortFields},
+		{Name: "order", Description: "asc (default) or desc"},
+	}
+	return append(params, filters...)
+}
+
+// list, get, create, update and delete endpoints of one mock route type,
+// the route is read with path query at base path + getSuffix
+func routeTypeEndpoints(kind string, body interface{}, getSuffix string, getResponse interface{}) []openapi.Endpoint {
+	path := "/api/routes/" + kind
+	tag := kind + " routes"
+
+	return []openapi.Endpoint{
+		{
+			Method:  http.MethodGet,
+			Path:    path,
+			Tag:     tag,
+			Summary: "List paths of " + kind + " routes",
+			Query: listQueryParams("path",
+				openapi.QueryParam{Name: "prefix", Description: "path prefix"},
+				openapi.QueryParam{Name: "search", Description: "case insensitive substring of path"},
+			),
+			Response: struct {
+				Endpoints  []string `json:"endpoints"`
+				NextCursor string   `json:"next_cursor,omitempty"`
+			}{},
+			Errors: []int{http.StatusBadRequest},
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     path + getSuffix,
+			Tag:      tag,
+			Summary:  "Get " + kind + " route",
+			Query:    []openapi.QueryParam{pathQuery},
+			Response: getResponse,
+			Errors:   []int{http.StatusBadRequest, http.StatusNotFound},
+		},
+		{
+			Method:   http.MethodPost,
+			Path:     path,
+			Tag:      tag,
+			Summary:  "Create " + kind + " route",
+			Body:     body,
+			Response: "",
+			Errors:   []int{http.StatusBadRequest, http.StatusConflict},
+		},
+		{
+			Method:  http.MethodPut,
+			Path:    path,
+			Tag:     tag,
+			Summary: "Update " + kind + " route",
+			Body:    body,
+			Status:  http.StatusNoContent,
+			Errors:  []int{http.StatusBadRequest, http.StatusNotFound},
+		},
+		{
+			Method:  http.MethodDelete,
+			Path:    path,
+			Tag:     tag,
+			Summary: "Delete " + kind + " route",
+			Query:   []openapi.QueryParam{pathQuery},
+			Status:  http.StatusNoContent,
+			Errors:  []int{http.StatusBadRequest, http.StatusNotFound},
+		},
+	}
+}
+
+// get, set and delete endpoints of policy attached to a single mock route
+func routePolicyEndpoints(kind string, policy interface{}) []openapi.Endpoint {
+	path := "/api/routes/policy/" + kind
+	tag := "policies"
+	name := strings.ReplaceAll(kind, "_", " ") + " policy"
+
+	return []openapi.Endpoint{
+		{
+			Method:   http.MethodGet,
+			Path:     path,
+			Tag:      tag,
+			Summary:  "Get " + name + " of route",
+			Query:    []openapi.QueryParam{pathQuery},
+			Response: policy,
+			Errors:   []int{http.StatusBadRequest, http.StatusNotFound},
+		},
+		{
+			Method:  http.MethodPut,
+			Path:    path,
+			Tag:     tag,
+			Summary: "Set " + name + " of route",
+			Body:    policy,
+			Status:  http.StatusNoContent,
+			Errors:  []int{http.StatusBadRequest, http.StatusNotFound},
+		},
+		{
+			Method:  http.MethodDelete,
+			Path:    path,
+			Tag:     tag,
+			Summary: "Delete " + name + " of route",
+			Query:   []openapi.QueryParam{pathQuery},
+			Status:  http.StatusNoContent,
+			Errors:  []int{http.StatusBadRequest, http.StatusNotFound},
+		},
+	}
+}
+
+// description of every admin api endpoint, endpoints missing here
+// are still published, but without request and response shapes
+func apiEndpoints() []openapi.Endpoint {
+	var endpoints []openapi.Endpoint
+
+	// service
+	endpoints = append(endpoints,
+		openapi.Endpoint{
+			Method:   http.MethodGet,
+			Path:     "/api/ping",
+			Tag:      "service",
+			Summary:  "Check that server is running",
+			Response: "",
+			Public:   true,
+		},
+		openapi.Endpoint{
+			Method:   http.MethodGet,
+			Path:     "/api/openapi.json",
+			Tag:      "service",
+			Summary:  "This document",
+			Response: json.RawMessage{},
+			Public:   true,
+		},
+		openapi.Endpoint{
+			Method:  http.MethodGet,
+			Path:    "/api/docs",
+			Tag:     "service",
+			Summary: "Interactive viewer of this document",
+			Public:  true,
+		},
+	)
+
+	// mock routes
+	endpoints = append(endpoints, openapi.Endpoint{
+		Method:  http.MethodGet,
+		Path:    "/api/routes",
+		Tag:     "routes",
+		Summary: "List routes of all types with details",
+		Query: listQueryParams("path, type, updated_at, hits",
+			openapi.QueryParam{Name: "type", Description: "route type, e.g. static_endpoint"},
+			openapi.QueryParam{Name: "prefix", Description: "path prefix"},
+			openapi.QueryParam{Name: "search", Description: "case insensitive substring of path, static response or proxy url"},
+		),
+		Response: struct {
+			Routes     []protocol.RouteInfo `json:"routes"`
+			NextCursor string               `json:"next_cursor,omitempty"`
+		}{},
+		Errors: []int{http.StatusBadRequest},
+	})
+	endpoints = append(endpoints, routeTypeEndpoints("static", protocol.StaticEndpoint{}, "/expected_response", "")...)
+	endpoints = append(endpoints, openapi.Endpoint{
+		Method:   http.MethodGet,
+		Path:     "/api/routes/static/config",
+		Tag:      "static routes",
+		Summary:  "Get static route with its representations",
+		Query:    []openapi.QueryParam{pathQuery},
+		Response: protocol.StaticEndpoint{},
+		Errors:   []int{http.StatusBadRequest, http.StatusNotFound},
+	})
+	endpoints = append(endpoints, routeTypeEndpoints("proxy", protocol.ProxyEndpoint{}, "/proxy_url", "")...)
+	endpoints = append(endpoints, openapi.Endpoint{
+		Method:   http.MethodGet,
+		Path:     "/api/routes/proxy/config",
+		Tag:      "proxy routes",
+		Summary:  "Get proxy route with its activity window",
+		Query:    []openapi.QueryParam{pathQuery},
+		Response: protocol.ProxyEndpoint{},
+		Errors:   []int{http.StatusBadRequest, http.StatusNotFound},
+	})
+	endpoints = append(endpoints, routeTypeEndpoints("dynamic", protocol.DynamicEndpoint{}, "/code", "")...)
+	endpoints = append(endpoints, openapi.Endpoint{
+		Method:   http.MethodGet,
+		Path:     "/api/routes/dynamic/config",
+		Tag:      "dynamic routes",
+		Summary:  "Get dynamic route with its activity window",
+		Query:    []openapi.QueryParam{pathQuery},
+		Response: protocol.DynamicEndpoint{},
+		Errors:   []int{http.StatusBadRequest, http.StatusNotFound},
+	})
+	endpoints = append(endpoints, routeTypeEndpoints("graphql", protocol.GraphQLEndpoint{}, "/config", protocol.GraphQLEndpoint{})...)
+	endpoints = append(endpoints, routeTypeEndpoints("websocket", protocol.WebSocketEndpoint{}, "/config", protocol.WebSocketEndpoint{})...)
+	endpoints = append(endpoints, routeTypeEndpoints("stream", protocol.StreamEndpoint{}, "/config", protocol.StreamEndpoint{})...)
+	for _, endpoint := range routeTypeEndpoints("file", protocol.FileEndpoint{}, "/config", protocol.FileEndpointInfo{}) {
+		endpoint.Multipart = endpoint.Body != nil
+		endpoints = append(endpoints, endpoint)
+	}
+	endpoints = append(endpoints, openapi.Endpoint{
+		Method:  http.MethodPost,
+		Path:    "/api/routes/websocket/broadcast",
+		Tag:     "websocket routes",
+		Summary: "Send message to all clients connected to websocket route",
+		Body:    protocol.WebSocketBroadcast{},
+		Response: struct {
+			Clients int `json:"clients"`
+		}{},
+		Errors: []int{http.StatusBadRequest, http.StatusNotFound},
+	})
+
+	// policies
+	endpoints = append(endpoints, routePolicyEndpoints("cors", protocol.RouteCorsPolicy{})...)
+	endpoints = append(endpoints, routePolicyEndpoints("rate_limit", protocol.RouteRateLimitPolicy{})...)
+
+	// namespaces
+	endpoints = append(endpoints,
+		openapi.Endpoint{
+			Method:  http.MethodGet,
+			Path:    "/api/namespaces",
+			Tag:     "namespaces",
+			Summary: "List namespaces",
+			Response: struct {
+				Namespaces []protocol.Namespace `json:"namespaces"`
+			}{},
+		},
+		openapi.Endpoint{
+			Method:   http.MethodGet,
+			Path:     "/api/namespaces/config",
+			Tag:      "namespaces",
+			Summary:  "Get namespace",
+			Query:    []openapi.QueryParam{prefixQuery},
+			Response: protocol.Namespace{},
+			Errors:   []int{http.StatusBadRequest, http.StatusNotFound},
+		},
+		openapi.Endpoint{
+			Method:   http.MethodPost,
+			Path:     "/api/namespaces",
+			Tag:      "namespaces",
+			Summary:  "Create namespace",
+			Body:     protocol.Namespace{},
+			Response: "",
+			Errors:   []int{http.StatusBadRequest, http.StatusConflict},
+		},
+		openapi.Endpoint{
+			Method:  http.MethodPut,
+			Path:    "/api/namespaces",
+			Tag:     "namespaces",
+			Summary: "Update namespace",
+			Body:    protocol.Namespace{},
+			Status:  http.StatusNoContent,
+			Errors:  []int{http.StatusBadRequest, http.StatusNotFound},
+		},
+		openapi.Endpoint{
+			Method:  http.MethodDelete,
+			Path:    "/api/namespaces",
+			Tag:     "namespaces",
+			Summary: "Delete namespace",
+			Query:   []openapi.QueryParam{prefixQuery},
+			Status:  http.StatusNoContent,
+			Errors:  []int{http.StatusBadRequest, http.StatusNotFound},
+		},
+	)
+
+	// message pools
+	messages := struct {
+		Messages   []string `json:"messages"`
+		NextCursor string   `json:"next_cursor,omitempty"`
+	}{}
+	messagesQuery := append(
+		[]openapi.QueryParam{poolQuery},
+		listQueryParams("message", openapi.QueryParam{Name: "search", Description: "case insensitive substring of message"})...,
+	)
+	endpoints = append(endpoints,
+		openapi.Endpoint{
+			Method:  http.MethodGet,
+			Path:    "/api/brokers/pool",
+			Tag:     "pools",
+			Summary: "List message pools",
+			Query: listQueryParams("name, broker",
+				openapi.QueryParam{Name: "prefix", Description: "pool name prefix"},
+				openapi.QueryParam{Name: "search", Description: "case insensitive substring of pool name or queue"},
+				openapi.QueryParam{Name: "broker", Description: "rabbitmq or kafka"},
+			),
+			Response: struct {
+				Pools      []protocol.MessagePool `json:"pools"`
+				NextCursor string                 `json:"next_cursor,omitempty"`
+			}{},
+			Errors: []int{http.StatusBadRequest},
+		},
+		openapi.Endpoint{
+			Method:   http.MethodGet,
+			Path:     "/api/brokers/pool/config",
+			Tag:      "pools",
+			Summary:  "Get broker config of message pool, layout depends on broker",
+			Query:    []openapi.QueryParam{poolQuery},
+			Response: json.RawMessage{},
+			Errors:   []int{http.StatusBadRequest, http.StatusNotFound},
+		},
+		openapi.Endpoint{
+			Method:   http.MethodPost,
+			Path:     "/api/brokers/pool",
+			Tag:      "pools",
+			Summary:  "Create message pool",
+			Body:     protocol.MessagePool{},
+			Response: "",
+			Errors:   []int{http.StatusBadRequest, http.StatusConflict},
+		},
+		openapi.Endpoint{
+			Method:  http.MethodDelete,
+			Path:    "/api/brokers/pool",
+			Tag:     "pools",
+			Summary: "Delete message pool",
+			Query:   []openapi.QueryParam{poolQuery},
+			Status:  http.StatusNoContent,
+			Errors:  []int{http.StatusBadRequest, http.StatusNotFound},
+		},
+		openapi.Endpoint{
+			Method:   http.MethodGet,
+			Path:     "/api/brokers/pool/read",
+			Tag:      "pools",
+			Summary:  "Get messages read from message pool",
+			Query:    messagesQuery,
+			Response: messages,
+			Errors:   []int{http.StatusBadRequest, http.StatusNotFound},
+		},
+		openapi.Endpoint{
+			Method:   http.MethodGet,
+			Path:     "/api/brokers/pool/write",
+			Tag:      "pools",
+			Summary:  "Get messages written to message pool",
+			Query:    messagesQuery,
+			Response: messages,
+			Errors:   []int{http.StatusBadRequest, http.StatusNotFound},
+		},
+		openapi.Endpoint{
+			Method:  http.MethodPost,
+			Path:    "/api/brokers/pool/read",
+			Tag:     "pools",
+			Summary: "Schedule reading of message pool",
+			Query:   []openapi.QueryParam{poolQuery},
+			Status:  http.StatusNoContent,
+			Errors:  []int{http.StatusBadRequest, http.StatusNotFound},
+		},
+		openapi.Endpoint{
+			Method:  http.MethodPost,
+			Path:    "/api/brokers/pool/write",
+			Tag:     "pools",
+			Summary: "Schedule writing of messages to message pool",
+			Body:    protocol.BrokerTask{},
+			Status:  http.StatusNoContent,
+			Errors:  []int{http.StatusBadRequest, http.StatusNotFound},
+		},
+	)
+
+	// esb
+	endpoints = append(endpoints,
+		openapi.Endpoint{
+			Method:  http.MethodGet,
+			Path:    "/api/brokers/esb",
+			Tag:     "esb",
+			Summary: "List esb records",
+			Query: listQueryParams("pool_name_in, pool_name_out",
+				openapi.QueryParam{Name: "prefix", Description: "in-pool name prefix"},
+				openapi.QueryParam{Name: "search", Description: "case insensitive substring of in-pool or out-pool name"},
+			),
+			Response: struct {
+				Records    []protocol.EsbRecord `json:"records"`
+				NextCursor string               `json:"next_cursor,omitempty"`
+			}{},
+			Errors: []int{http.StatusBadRequest},
+		},
+		openapi.Endpoint{
+			Method:   http.MethodGet,
+			Path:     "/api/brokers/esb/code",
+			Tag:      "esb",
+			Summary:  "Get mapper code of esb record",
+			Query:    []openapi.QueryParam{poolInQuery},
+			Response: "",
+			Errors:   []int{http.StatusBadRequest, http.StatusNotFound},
+		},
+		openapi.Endpoint{
+			Method:   http.MethodPost,
+			Path:     "/api/brokers/esb",
+			Tag:      "esb",
+			Summary:  "Create esb record linking two message pools",
+			Body:     protocol.EsbRecord{},
+			Response: "",
+			Errors:   []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict},
+		},
+		openapi.Endpoint{
+			Method:  http.MethodPost,
+			Path:    "/api/brokers/esb/task",
+			Tag:     "esb",
+			Summary: "Submit messages to in-pool of esb record",
+			Body:    protocol.BrokerTask{},
+			Status:  http.StatusNoContent,
+			Errors:  []int{http.StatusBadRequest, http.StatusNotFound},
+		},
+		openapi.Endpoint{
+			Method:  http.MethodPut,
+			Path:    "/api/brokers/esb",
+			Tag:     "esb",
+			Summary: "Update out-pool and mapper code of esb record, empty code removes mapper",
+			Body:    protocol.EsbRecord{},
+			Status:  http.StatusNoContent,
+			Errors:  []int{http.StatusBadRequest, http.StatusNotFound},
+		},
+		openapi.Endpoint{
+			Method:  http.MethodDelete,
+			Path:    "/api/brokers/esb",
+			Tag:     "esb",
+			Summary: "Delete esb record",
+			Query:   []openapi.QueryParam{poolInQuery},
+			Status:  http.StatusNoContent,
+			Errors:  []int{http.StatusBadRequest, http.StatusNotFound},
+		},
+	)
+
+	// batch
+	endpoints = append(endpoints, openapi.Endpoint{
+		Method: http.MethodPost,
+		Path:   "/api/batch",
+		Tag:    "batch",
+		Summary: "Apply create, update and delete operations on routes, message pools and esb records " +
+			"all or nothing, failed batch responds with status of failed operation",
+		Body:     protocol.BatchRequest{},
+		Response: protocol.BatchResponse{},
+		Errors:   []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict},
+	})
+
+	// audit log
+	endpoints = append(endpoints, openapi.Endpoint{
+		Method:  http.MethodGet,
+		Path:    "/api/audit",
+		Tag:     "audit",
+		Summary: "List recorded changes of routes, message pools, esb records and namespaces",
+		Query: listQueryParams("timestamp",
+			openapi.QueryParam{Name: "resource", Description: "route, pool, esb or namespace"},
+			openapi.QueryParam{Name: "key", Description: "route path, pool name, esb in-pool name or namespace prefix"},
+			openapi.QueryParam{Name: "prefix", Description: "key prefix"},
+			openapi.QueryParam{Name: "actor", Description: "authenticated identity of caller"},
+			openapi.QueryParam{Name: "since", Description: "RFC 3339 time, inclusive"},
+			openapi.QueryParam{Name: "until", Description: "RFC 3339 time, exclusive"},
+		),
+		Response: struct {
+			Records    []protocol.AuditRecord `json:"records"`
+			NextCursor string                 `json:"next_cursor,omitempty"`
+		}{},
+		Errors: []int{http.StatusBadRequest},
+	})
+
+	// revisions
+	revisionQuery := []openapi.QueryParam{
+		{Name: "resource", Description: "route or esb"},
+		{Name: "key", Description: "route path or esb in-pool name"},
+	}
+	endpoints = append(endpoints,
+		openapi.Endpoint{
+			Method:  http.MethodGet,
+			Path:    "/api/revisions",
+			Tag:     "revisions",
+			Summary: "List numbered definitions of route or esb record, one per change",
+			Query:   listQueryParams("revision", revisionQuery...),
+			Response: struct {
+				Revisions  []protocol.Revision `json:"revisions"`
+				NextCursor string              `json:"next_cursor,omitempty"`
+			}{},
+			Errors: []int{http.StatusBadRequest},
+		},
+		openapi.Endpoint{
+			Method:  http.MethodGet,
+			Path:    "/api/revisions/diff",
+			Tag:     "revisions",
+			Summary: "Compare two revisions field by field and code line by line",
+			Query: append(revisionQuery,
+				openapi.QueryParam{Name: "from", Description: "revision number"},
+				openapi.QueryParam{Name: "to", Description: "revision number"},
+			),
+			Response: protocol.RevisionDiff{},
+			Errors:   []int{http.StatusBadRequest, http.StatusNotFound},
+		},
+		openapi.Endpoint{
+			Method:  http.MethodPost,
+			Path:    "/api/revisions/rollback",
+			Tag:     "revisions",
+			Summary: "Restore definition and code of revision, recreating removed resource, rollback is recorded as new revision",
+			Body:    protocol.RevisionRollback{},
+			Status:  http.StatusNoContent,
+			Errors:  []int{http.StatusBadRequest, http.StatusNotFound},
+		},
+	)
+
+	// chaos profiles
+	endpoints = append(endpoints,
+		openapi.Endpoint{
+			Method:  http.MethodGet,
+			Path:    "/api/chaos",
+			Tag:     "chaos",
+			Summary: "List enabled chaos profiles of whole server and namespaces",
+			Response: struct {
+				Profiles []protocol.ChaosProfile `json:"profiles"`
+			}{},
+		},
+		openapi.Endpoint{
+			Method:   http.MethodPut,
+			Path:     "/api/chaos",
+			Tag:      "chaos",
+			Summary:  "Enable or tune chaos profile injecting delays, connection resets, failures and dropped broker writes, responds with seed in use",
+			Body:     protocol.ChaosProfile{},
+			Response: protocol.ChaosProfile{},
+			Errors:   []int{http.StatusBadRequest, http.StatusNotFound},
+		},
+		openapi.Endpoint{
+			Method:  http.MethodDelete,
+			Path:    "/api/chaos",
+			Tag:     "chaos",
+			Summary: "Disable chaos profile",
+			Query:   []openapi.QueryParam{{Name: "prefix", Description: "namespace prefix, whole server profile if omitted"}},
+			Status:  http.StatusNoContent,
+			Errors:  []int{http.StatusNotFound},
+		},
+	)
+
+	return endpoints
+}
+
+// documents registered /api routes only, so disabled or removed
+// endpoints never appear in the document
+func buildOpenApiDocument(routes gin.RoutesInfo) *openapi.Document {
+	described := make(map[string]openapi.Endpoint)
+	for _, endpoint := range apiEndpoints() {
+		described[endpoint.Method+" "+endpoint.Path] = endpoint
+	}
+
+	doc := openapi.New("mock-server admin API", API_VERSION, "Management of mock routes, policies, message pools and ESB records. "+
+		"Every endpoint is also served under /api/v2 with responses wrapped into {data} or {error: {code, message, details}} envelope")
+	for _, route := range routes {
+		if !strings.HasPrefix(route.Path, "/api/") {
+			continue
+		}
+		// v2 mirrors v1 endpoints, only responses are enveloped
+		if strings.HasPrefix(route.Path, API_V2_PREFIX) {
+			continue
+		}
+
+		endpoint, ok := described[route.Method+" "+route.Path]
+		if !ok {
+			zlog.Warn().Str("method", route.Method).Str("path", route.Path).Msg("Admin api endpoint is not described in openapi document")
+			endpoint = openapi.Endpoint{Method: route.Method, Path: route.Path}
+		}
+		doc.Add(endpoint)
+	}
+
+	return doc
+}
+
+// document and its viewer are public like ping
+func (s *server) initOpenApi(api *gin.RouterGroup) {
+	api.GET("/openapi.json", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json; charset=utf-8", s.openapiDoc)
+	})
+
+	api.GET("/docs", func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", openapi.Viewer)
+	})
+}
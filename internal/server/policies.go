@@ -0,0 +1,58 @@
+package server
+
+import (
+	"mock-server/internal/database"
+
+	"github.com/gin-gonic/gin"
+)
+
+// policies applied to mock route request, nil if not configured
+type routePolicies struct {
+	cors           *database.CorsPolicy
+	rateLimit      *database.RateLimitPolicy
+	rateLimitScope string // namespace limit is shared by all its routes
+}
+
+// route own policies take precedence over namespace ones
+func resolveRoutePolicies(c *gin.Context, route *database.Route) (routePolicies, error) {
+	policies := routePolicies{
+		cors:           route.Cors,
+		rateLimit:      route.RateLimit,
+		rateLimitScope: "route:" + route.Path,
+	}
+	if policies.cors != nil && policies.rateLimit != nil {
+		return policies, nil
+	}
+
+	namespace, err := database.MatchNamespace(c, route.Path)
+	switch err {
+	case nil:
+	case database.ErrNoSuchNamespace:
+		return policies, nil
+	default:
+		return routePolicies{}, err
+	}
+
+	if policies.cors == nil {
+		policies.cors = namespace.Cors
+	}
+	if policies.rateLimit == nil && namespace.RateLimit != nil {
+		policies.rateLimit = namespace.RateLimit
+		policies.rateLimitScope = "namespace:" + namespace.Prefix
+	}
+	return policies, nil
+}
+
+// aborts request if any policy has already replied
+func (s *server) applyRoutePolicies(c *gin.Context, policies *routePolicies) {
+	if policies.cors != nil {
+		// preflight and requests from forbidden origins are finished here
+		if s.applyCorsPolicy(c, policies.cors); c.IsAborted() {
+			return
+		}
+	}
+
+	if policies.rateLimit != nil {
+		s.applyRateLimitPolicy(c, policies.rateLimitScope, policies.rateLimit)
+	}
+}
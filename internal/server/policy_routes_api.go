@@ -0,0 +1,186 @@
+package server
+
+import (
+	"mock-server/internal/database"
+	"mock-server/internal/server/protocol"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	zlog "github.com/rs/zerolog/log"
+)
+
+// policies of any route type (cors, rate limit)
+func (s *server) initRoutesApiPolicy(routes *gin.RouterGroup) {
+	corsPolicyEndpoint := "/policy/cors"
+	rateLimitPolicyEndpoint := "/policy/rate_limit"
+
+	routes.GET(corsPolicyEndpoint, func(c *gin.Context) {
+		path := c.Query("path")
+		if path == "" {
+			zlog.Error().Msg("Path param not specified")
+			apiError(c, http.StatusBadRequest, nil, "specify path param")
+			return
+		}
+
+		zlog.Info().Str("path", path).Msg("Received get route cors policy request")
+
+		route, err := database.GetRoute(c, path)
+		switch err {
+		case nil:
+		case database.ErrNoSuchPath:
+			zlog.Error().Msg("Request for unexisting route")
+			apiError(c, http.StatusNotFound, err, "Received path was not created before")
+			return
+		default:
+			zlog.Error().Err(err).Msg("Failed to query route")
+			apiError(c, http.StatusInternalServerError, err, err.Error())
+			return
+		}
+
+		if route.Cors == nil {
+			apiError(c, http.StatusNotFound, nil, "Route has no own cors policy")
+			return
+		}
+
+		c.JSON(http.StatusOK, protocol.RouteCorsPolicy{
+			Path: route.Path,
+			Cors: toProtocolCorsPolicy(route.Cors),
+		})
+	})
+
+	routes.PUT(corsPolicyEndpoint, func(c *gin.Context) {
+		var routePolicy protocol.RouteCorsPolicy
+		if err := c.Bind(&routePolicy); err != nil {
+			zlog.Error().Err(err).Msg("Failed to bind request")
+			apiError(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
+
+		zlog.Info().Str("path", routePolicy.Path).Msg("Received set route cors policy request")
+
+		if err := validateCorsPolicy(routePolicy.Cors); err != nil {
+			zlog.Error().Err(err).Msg("Invalid cors policy")
+			apiError(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
+
+		err := database.SetRouteCorsPolicy(c, routePolicy.Path, toDatabaseCorsPolicy(routePolicy.Cors))
+		switch err {
+		case nil:
+			zlog.Info().Str("path", routePolicy.Path).Msg("Route cors policy set")
+			c.JSON(http.StatusNoContent, "Route cors policy successfully set!")
+		case database.ErrNoSuchPath:
+			zlog.Error().Msg("Set policy on unexisting path")
+			apiError(c, http.StatusNotFound, err, "Received path was not created before")
+		default:
+			zlog.Error().Err(err).Msg("Failed to set route cors policy")
+			apiError(c, http.StatusInternalServerError, err, err.Error())
+		}
+	})
+
+	routes.DELETE(corsPolicyEndpoint, func(c *gin.Context) {
+		path := c.Query("path")
+		if path == "" {
+			zlog.Error().Msg("Path param not specified")
+			apiError(c, http.StatusBadRequest, nil, "specify path param")
+			return
+		}
+
+		zlog.Info().Str("path", path).Msg("Received delete route cors policy request")
+
+		err := database.SetRouteCorsPolicy(c, path, nil)
+		switch err {
+		case nil:
+			zlog.Info().Str("path", path).Msg("Route cors policy removed")
+			c.JSON(http.StatusNoContent, "Route cors policy successfully removed!")
+		case database.ErrNoSuchPath:
+			zlog.Error().Msg("Remove policy on unexisting path")
+			apiError(c, http.StatusNotFound, err, "Received path was not created before")
+		default:
+			zlog.Error().Err(err).Msg("Failed to remove route cors policy")
+			apiError(c, http.StatusInternalServerError, err, err.Error())
+		}
+	})
+
+	routes.GET(rateLimitPolicyEndpoint, func(c *gin.Context) {
+		path := c.Query("path")
+		if path == "" {
+			zlog.Error().Msg("Path param not specified")
+			apiError(c, http.StatusBadRequest, nil, "specify path param")
+			return
+		}
+
+		zlog.Info().Str("path", path).Msg("Received get route rate limit policy request")
+
+		route, err := database.GetRoute(c, path)
+		switch err {
+		case nil:
+		case database.ErrNoSuchPath:
+			zlog.Error().Msg("Request for unexisting route")
+			apiError(c, http.StatusNotFound, err, "Received path was not created before")
+			return
+		default:
+			zlog.Error().Err(err).Msg("Failed to query route")
+			apiError(c, http.StatusInternalServerError, err, err.Error())
+			return
+		}
+
+		if route.RateLimit == nil {
+			apiError(c, http.StatusNotFound, nil, "Route has no own rate limit policy")
+			return
+		}
+
+		c.JSON(http.StatusOK, protocol.RouteRateLimitPolicy{
+			Path:      route.Path,
+			RateLimit: toProtocolRateLimitPolicy(route.RateLimit),
+		})
+	})
+
+	routes.PUT(rateLimitPolicyEndpoint, func(c *gin.Context) {
+		var routePolicy protocol.RouteRateLimitPolicy
+		if err := c.Bind(&routePolicy); err != nil {
+			zlog.Error().Err(err).Msg("Failed to bind request")
+			apiError(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
+
+		zlog.Info().Str("path", routePolicy.Path).Msg("Received set route rate limit policy request")
+
+		err := database.SetRouteRateLimitPolicy(c, routePolicy.Path, toDatabaseRateLimitPolicy(routePolicy.RateLimit))
+		switch err {
+		case nil:
+			zlog.Info().Str("path", routePolicy.Path).Msg("Route rate limit policy set")
+			c.JSON(http.StatusNoContent, "Route rate limit policy successfully set!")
+		case database.ErrNoSuchPath:
+			zlog.Error().Msg("Set policy on unexisting path")
+			apiError(c, http.StatusNotFound, err, "Received path was not created before")
+		default:
+			zlog.Error().Err(err).Msg("Failed to set route rate limit policy")
+			apiError(c, http.StatusInternalServerError, err, err.Error())
+		}
+	})
+
+	routes.DELETE(rateLimitPolicyEndpoint, func(c *gin.Context) {
+		path := c.Query("path")
+		if path == "" {
+			zlog.Error().Msg("Path param not specified")
+			apiError(c, http.StatusBadRequest, nil, "specify path param")
+			return
+		}
+
+		zlog.Info().Str("path", path).Msg("Received delete route rate limit policy request")
+
+		err := database.SetRouteRateLimitPolicy(c, path, nil)
+		switch err {
+		case nil:
+			zlog.Info().Str("path", path).Msg("Route rate limit policy removed")
+			c.JSON(http.StatusNoContent, "Route rate limit policy successfully removed!")
+		case database.ErrNoSuchPath:
+			zlog.Error().Msg("Remove policy on unexisting path")
+			apiError(c, http.StatusNotFound, err, "Received path was not created before")
+		default:
+			zlog.Error().Err(err).Msg("Failed to remove route rate limit policy")
+			apiError(c, http.StatusInternalServerError, err, err.Error())
+		}
+	})
+}
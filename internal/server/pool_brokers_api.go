@@ -16,15 +16,20 @@ func (s *server) initBrokersApiPool(brokersApi *gin.RouterGroup) {
 	brokersApi.GET(poolBrokersEndpoint, func(c *gin.Context) {
 		zlog.Info().Msg("Get all broker pools request")
 
-		pools, err := database.ListMessagePools(c)
+		query, ok := bindListQuery(c)
+		if !ok {
+			return
+		}
+
+		pools, err := database.FindMessagePools(c, query)
 		if err != nil {
-			zlog.Error().Err(err).Msg("Failed to list all broker pools")
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			zlog.Error().Err(err).Msg("Failed to list broker pools")
+			listPageError(c, err)
 			return
 		}
 
 		respPools := make([]protocol.MessagePool, 0)
-		for _, pool := range pools {
+		for _, pool := range pools.Items {
 			switch pool.Broker {
 			case "rabbitmq":
 				respPools = append(respPools, protocol.MessagePool{
@@ -40,33 +45,33 @@ func (s *server) initBrokersApiPool(brokersApi *gin.RouterGroup) {
 				})
 
 			default:
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Database inconsistency found"})
+				apiError(c, http.StatusInternalServerError, nil, "Database inconsistency found")
 			}
 		}
 
 		zlog.Debug().Interface("pools", respPools).Msg("Successfully queried all pools")
-		c.JSON(http.StatusOK, gin.H{"pools": respPools})
+		c.JSON(http.StatusOK, pageResponse("pools", respPools, pools.NextCursor))
 	})
 
 	brokersApi.GET(poolBrokersEndpoint+"/config", func(c *gin.Context) {
 		poolName := c.Query("pool")
 		if poolName == "" {
 			zlog.Error().Msg("Pool param not specified")
-			c.JSON(http.StatusBadRequest, gin.H{"error": "specify pool param"})
+			apiError(c, http.StatusBadRequest, nil, "specify pool param")
 			return
 		}
 
-		pool, err := brokers.GetMessagePool(poolName)
+		pool, err := brokers.GetMessagePool(c, poolName)
 		switch err {
 		case nil:
 			zlog.Info().Str("pool", poolName).Msg("Queried pool")
 		case database.ErrNoSuchPool:
 			zlog.Error().Str("pool", poolName).Msg("No such pool")
-			c.JSON(http.StatusNotFound, gin.H{"error": "No such pool"})
+			apiError(c, http.StatusNotFound, err, "No such pool")
 			return
 		default:
 			zlog.Error().Err(err).Msg("Failed to get pool")
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			apiError(c, http.StatusInternalServerError, err, err.Error())
 			return
 		}
 
@@ -85,35 +90,40 @@ func (s *server) initBrokersApiPool(brokersApi *gin.RouterGroup) {
 			poolName := c.Query("pool")
 			if poolName == "" {
 				zlog.Error().Msg("Pool param not specified")
-				c.JSON(http.StatusBadRequest, gin.H{"error": "specify pool param"})
+				apiError(c, http.StatusBadRequest, nil, "specify pool param")
 				return
 			}
 
 			zlog.Info().Str("pool", poolName).Msg("Received pool read tasks list request")
 
+			query, ok := bindListQuery(c)
+			if !ok {
+				return
+			}
+
 			pool, err := database.GetMessagePool(c, poolName)
 			switch err {
 			case nil:
 				zlog.Info().Str("pool", poolName).Msg("Queried pool")
 			case database.ErrNoSuchPool:
 				zlog.Error().Str("pool", poolName).Msg("No such pool")
-				c.JSON(http.StatusNotFound, gin.H{"error": "No such pool"})
+				apiError(c, http.StatusNotFound, err, "No such pool")
 				return
 			default:
 				zlog.Error().Err(err).Msg("Failed to get pool")
-				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				apiError(c, http.StatusInternalServerError, err, err.Error())
 				return
 			}
 
-			messages, err := database.GetMessagePoolReadMessages(c, pool)
+			messages, err := database.FindMessagePoolReadMessages(c, pool, query)
 			if err != nil {
 				zlog.Error().Err(err).Msg("Failed to get pool read messages")
-				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				listPageError(c, err)
 				return
 			}
 
-			zlog.Debug().Interface("messages", messages).Msg("Queried messages")
-			c.JSON(http.StatusOK, gin.H{"messages": messages})
+			zlog.Debug().Interface("messages", messages.Items).Msg("Queried messages")
+			c.JSON(http.StatusOK, pageResponse("messages", messages.Items, messages.NextCursor))
 		})
 
 		// list all write messages by pool name
@@ -121,35 +131,40 @@ func (s *server) initBrokersApiPool(brokersApi *gin.RouterGroup) {
 			poolName := c.Query("pool")
 			if poolName == "" {
 				zlog.Error().Msg("Pool param not specified")
-				c.JSON(http.StatusBadRequest, gin.H{"error": "specify pool param"})
+				apiError(c, http.StatusBadRequest, nil, "specify pool param")
 				return
 			}
 
 			zlog.Info().Str("pool", poolName).Msg("Received pool write tasks list request")
 
+			query, ok := bindListQuery(c)
+			if !ok {
+				return
+			}
+
 			pool, err := database.GetMessagePool(c, poolName)
 			switch err {
 			case nil:
 				zlog.Info().Str("pool", poolName).Msg("Queried pool")
 			case database.ErrNoSuchPool:
 				zlog.Error().Str("pool", poolName).Msg("No such pool")
-				c.JSON(http.StatusNotFound, gin.H{"error": "No such pool"})
+				apiError(c, http.StatusNotFound, err, "No such pool")
 				return
 			default:
 				zlog.Error().Err(err).Msg("Failed to get pool")
-				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				apiError(c, http.StatusInternalServerError, err, err.Error())
 				return
 			}
 
-			messages, err := database.GetMessagePoolWriteMessages(c, pool)
+			messages, err := database.FindMessagePoolWriteMessages(c, pool, query)
 			if err != nil {
 				zlog.Error().Err(err).Msg("Failed to get pool write messages")
-				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				listPageError(c, err)
 				return
 			}
 
-			zlog.Debug().Interface("messages", messages).Msg("Queried messages")
-			c.JSON(http.StatusOK, gin.H{"messages": messages})
+			zlog.Debug().Interface("messages", messages.Items).Msg("Queried messages")
+			c.JSON(http.StatusOK, pageResponse("messages", messages.Items, messages.NextCursor))
 		})
 
 		// start read task in given pool
@@ -157,23 +172,23 @@ func (s *server) initBrokersApiPool(brokersApi *gin.RouterGroup) {
 			poolName := c.Query("pool")
 			if poolName == "" {
 				zlog.Error().Msg("Pool param not specified")
-				c.JSON(http.StatusBadRequest, gin.H{"error": "specify pool param"})
+				apiError(c, http.StatusBadRequest, nil, "specify pool param")
 				return
 			}
 
 			zlog.Info().Str("pool", poolName).Msg("Received pool read task")
 
-			pool, err := brokers.GetMessagePool(poolName)
+			pool, err := brokers.GetMessagePool(c, poolName)
 			switch err {
 			case nil:
 				zlog.Info().Str("pool", poolName).Msg("Queried pool")
 			case database.ErrNoSuchPool:
 				zlog.Error().Str("pool", poolName).Msg("No such pool")
-				c.JSON(http.StatusNotFound, gin.H{"error": "No such pool"})
+				apiError(c, http.StatusNotFound, err, "No such pool")
 				return
 			default:
 				zlog.Error().Err(err).Msg("Failed to get pool")
-				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				apiError(c, http.StatusInternalServerError, err, err.Error())
 				return
 			}
 
@@ -188,24 +203,24 @@ func (s *server) initBrokersApiPool(brokersApi *gin.RouterGroup) {
 			var brokerTask protocol.BrokerTask
 			if err := c.Bind(&brokerTask); err != nil {
 				zlog.Error().Err(err).Msg("Failed to bind request")
-				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				apiError(c, http.StatusBadRequest, err, err.Error())
 				return
 			}
 			zlog.Info().Interface("task", brokerTask).Msg("Received")
 
 			zlog.Info().Str("pool", brokerTask.PoolName).Msg("Received pool write task")
 
-			pool, err := brokers.GetMessagePool(brokerTask.PoolName)
+			pool, err := brokers.GetMessagePool(c, brokerTask.PoolName)
 			switch err {
 			case nil:
 				zlog.Info().Str("pool", brokerTask.PoolName).Msg("Queried pool")
 			case database.ErrNoSuchPool:
 				zlog.Error().Str("pool", brokerTask.PoolName).Msg("No such pool")
-				c.JSON(http.StatusNotFound, gin.H{"error": "No such pool"})
+				apiError(c, http.StatusNotFound, err, "No such pool")
 				return
 			default:
 				zlog.Error().Err(err).Msg("Failed to get pool")
-				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				apiError(c, http.StatusInternalServerError, err, err.Error())
 				return
 			}
 
@@ -220,7 +235,7 @@ func (s *server) initBrokersApiPool(brokersApi *gin.RouterGroup) {
 		var messagePool protocol.MessagePool
 		if err := c.Bind(&messagePool); err != nil {
 			zlog.Error().Err(err).Msg("Failed to bind request")
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			apiError(c, http.StatusBadRequest, err, err.Error())
 			return
 		}
 
@@ -228,7 +243,7 @@ func (s *server) initBrokersApiPool(brokersApi *gin.RouterGroup) {
 		case "rabbitmq":
 			if messagePool.QueueName == "" {
 				zlog.Error().Msg("Received rabbitmq create request with empty queue name")
-				c.JSON(http.StatusBadRequest, gin.H{"error": "queue_name field required for rabbitmq pool"})
+				apiError(c, http.StatusBadRequest, nil, "queue_name field required for rabbitmq pool")
 				return
 			}
 			zlog.Info().
@@ -239,7 +254,7 @@ func (s *server) initBrokersApiPool(brokersApi *gin.RouterGroup) {
 		case "kafka":
 			if messagePool.TopicName == "" {
 				zlog.Error().Msg("Received kafka create request with empty topic name")
-				c.JSON(http.StatusBadRequest, gin.H{"error": "topic_name field required for kafka pool"})
+				apiError(c, http.StatusBadRequest, nil, "topic_name field required for kafka pool")
 				return
 			}
 			zlog.Info().
@@ -252,7 +267,7 @@ func (s *server) initBrokersApiPool(brokersApi *gin.RouterGroup) {
 			zlog.Error().
 				Str("broker", messagePool.Broker).
 				Msg("Received request with unsupported broker")
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Such pool is unsupported"})
+			apiError(c, http.StatusBadRequest, nil, "Such pool is unsupported")
 			return
 		}
 
@@ -264,7 +279,7 @@ func (s *server) initBrokersApiPool(brokersApi *gin.RouterGroup) {
 			pool = brokers.NewKafkaMessagePool(messagePool.PoolName, messagePool.TopicName)
 		}
 
-		_, err := brokers.AddMessagePool(pool)
+		_, err := brokers.AddMessagePool(c, pool)
 		switch err {
 		case nil:
 			zlog.Info().
@@ -274,10 +289,10 @@ func (s *server) initBrokersApiPool(brokersApi *gin.RouterGroup) {
 			c.JSON(http.StatusOK, "Message pool successfully created!")
 		case database.ErrDuplicateKey:
 			zlog.Error().Err(err).Msg("Failed to add message")
-			c.JSON(http.StatusConflict, gin.H{"error": "The same message pool already exists"})
+			apiError(c, http.StatusConflict, err, "The same message pool already exists")
 		default:
 			zlog.Error().Err(err).Msg("Failed to add message pool")
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			apiError(c, http.StatusInternalServerError, err, err.Error())
 		}
 	})
 
@@ -285,13 +300,13 @@ func (s *server) initBrokersApiPool(brokersApi *gin.RouterGroup) {
 		poolName := c.Query("pool")
 		if poolName == "" {
 			zlog.Error().Msg("Pool param not specified")
-			c.JSON(http.StatusBadRequest, gin.H{"error": "specify pool param"})
+			apiError(c, http.StatusBadRequest, nil, "specify pool param")
 			return
 		}
 
-		if err := brokers.RemoveMessagePool(poolName); err != nil {
+		if err := brokers.RemoveMessagePool(c, poolName); err != nil {
 			zlog.Error().Err(err).Msg("Failed to remove pool")
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			apiError(c, http.StatusInternalServerError, err, err.Error())
 			return
 		}
 
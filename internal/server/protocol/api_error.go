@@ -0,0 +1,45 @@
+package protocol
+
+// stable error codes of v2 admin api
+const (
+	ERR_CODE_BAD_REQUEST         = "bad_request"
+	ERR_CODE_VALIDATION_FAILED   = "validation_failed"
+	ERR_CODE_INVALID_CURSOR      = "invalid_cursor"
+	ERR_CODE_INVALID_SORT        = "invalid_sort"
+	ERR_CODE_UNAUTHORIZED        = "unauthorized"
+	ERR_CODE_FORBIDDEN           = "forbidden"
+	ERR_CODE_NOT_FOUND           = "not_found"
+	ERR_CODE_ROUTE_NOT_FOUND     = "route_not_found"
+	ERR_CODE_ROUTE_TYPE_MISMATCH = "route_type_mismatch"
+	ERR_CODE_NAMESPACE_NOT_FOUND = "namespace_not_found"
+	ERR_CODE_POOL_NOT_FOUND      = "pool_not_found"
+	ERR_CODE_RECORD_NOT_FOUND    = "record_not_found"
+	ERR_CODE_REVISION_NOT_FOUND  = "revision_not_found"
+	ERR_CODE_ALREADY_EXISTS      = "already_exists"
+	ERR_CODE_CODERUN_FAILED      = "coderun_failed"
+	ERR_CODE_WORKER_FAILED       = "worker_failed"
+	ERR_CODE_NO_RUNNING_WORKER   = "no_running_worker"
+	ERR_CODE_BATCH_FAILED        = "batch_failed"
+	ERR_CODE_INTERNAL            = "internal"
+)
+
+type FieldError struct {
+	Field string `json:"field"`
+	Rule  string `json:"rule"`
+	Param string `json:"param,omitempty"`
+}
+
+type ApiError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// validation errors carry list of FieldError,
+	// failed batch carries list of BatchResult
+	Details interface{} `json:"details,omitempty"`
+}
+
+// body of every v2 admin api response except 204,
+// exactly one of fields is set
+type ApiResponse struct {
+	Data  interface{} `json:"data,omitempty"`
+	Error *ApiError   `json:"error,omitempty"`
+}
@@ -0,0 +1,37 @@
+package protocol
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// list params apply, prefix filters resource keys
+type AuditQuery struct {
+	ListQuery
+
+	Resource string `form:"resource" binding:"omitempty,oneof=route pool esb namespace"`
+	// exact route path, pool name, esb in-pool name or namespace prefix
+	Key   string `form:"key"`
+	Actor string `form:"actor"`
+	// RFC 3339, since is inclusive, until is exclusive
+	Since time.Time `form:"since" time_format:"2006-01-02T15:04:05Z07:00"`
+	Until time.Time `form:"until" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+type AuditRecord struct {
+	Id        string    `json:"id"`
+	Timestamp time.Time `json:"timestamp"`
+	Actor     string    `json:"actor,omitempty"`
+	IP        string    `json:"ip"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	Operation string    `json:"operation"`
+	Resource  string    `json:"resource"`
+	Key       string    `json:"key"`
+	// status of admin api response, missing for batch rollback
+	Status int `json:"status,omitempty"`
+	// stored resource in mongo relaxed extended json,
+	// missing if resource did not exist
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
@@ -0,0 +1,60 @@
+package protocol
+
+import "encoding/json"
+
+const (
+	BATCH_OP_CREATE = "create"
+	BATCH_OP_UPDATE = "update"
+	BATCH_OP_DELETE = "delete"
+
+	// route kinds are named as their admin api endpoints
+	BATCH_KIND_STATIC    = "static"
+	BATCH_KIND_PROXY     = "proxy"
+	BATCH_KIND_DYNAMIC   = "dynamic"
+	BATCH_KIND_GRAPHQL   = "graphql"
+	BATCH_KIND_WEBSOCKET = "websocket"
+	BATCH_KIND_STREAM    = "stream"
+	BATCH_KIND_POOL      = "pool"
+	BATCH_KIND_ESB       = "esb"
+
+	BATCH_STATE_APPLIED     = "applied"
+	BATCH_STATE_ROLLED_BACK = "rolled_back"
+	BATCH_STATE_FAILED      = "failed"
+	BATCH_STATE_SKIPPED     = "skipped"
+)
+
+type BatchOperation struct {
+	Op   string `json:"op" binding:"required,oneof=create update delete"`
+	Kind string `json:"kind" binding:"required,oneof=static proxy dynamic graphql websocket stream pool esb"`
+	// route path, pool name or esb in-pool name of deleted object,
+	// created and updated objects are identified by body
+	Key string `json:"key,omitempty"`
+	// request body of create or update endpoint of the kind
+	Body json.RawMessage `json:"body,omitempty"`
+}
+
+// operations are applied in order, all or none of them
+type BatchRequest struct {
+	Operations []BatchOperation `json:"operations" binding:"required,min=1,max=1000,dive"`
+}
+
+type BatchResult struct {
+	Index int    `json:"index"`
+	Op    string `json:"op"`
+	Kind  string `json:"kind"`
+	Key   string `json:"key,omitempty"`
+	// status of operation endpoint, missing for skipped operations
+	Status int    `json:"status,omitempty"`
+	State  string `json:"state"`
+	Error  string `json:"error,omitempty"`
+}
+
+type BatchResponse struct {
+	Applied bool `json:"applied"`
+	// whether batch ran in database transaction, otherwise
+	// applied operations are undone one by one on failure
+	Transaction bool   `json:"transaction"`
+	Error       string `json:"error,omitempty"`
+	// one result per operation in request order
+	Results []BatchResult `json:"results"`
+}
@@ -0,0 +1,18 @@
+package protocol
+
+// percentages are of affected requests or write tasks
+type ChaosProfile struct {
+	// namespace prefix, whole server if empty
+	Prefix       string  `json:"prefix,omitempty"`
+	ErrorPercent float64 `json:"error_percent" binding:"min=0,max=100"`
+	// statuses of failed requests, 500 if empty
+	ErrorStatuses []int   `json:"error_statuses,omitempty" binding:"omitempty,dive,min=500,max=599"`
+	DelayPercent  float64 `json:"delay_percent" binding:"min=0,max=100"`
+	DelayMinMs    int64   `json:"delay_min_ms" binding:"min=0"`
+	DelayMaxMs    int64   `json:"delay_max_ms" binding:"min=0,gtefield=DelayMinMs"`
+	ResetPercent  float64 `json:"reset_percent" binding:"min=0,max=100"`
+	// broker write tasks, whole server profile only
+	DropWritePercent float64 `json:"drop_write_percent" binding:"min=0,max=100"`
+	// random if not set, profiles in responses carry seed in use
+	Seed int64 `json:"seed,omitempty"`
+}
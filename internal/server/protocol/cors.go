@@ -0,0 +1,15 @@
+package protocol
+
+type CorsPolicy struct {
+	AllowOrigins     []string `json:"allow_origins" binding:"required,min=1"`
+	AllowMethods     []string `json:"allow_methods,omitempty"`
+	AllowHeaders     []string `json:"allow_headers,omitempty"`
+	ExposeHeaders    []string `json:"expose_headers,omitempty"`
+	AllowCredentials bool     `json:"allow_credentials,omitempty"`
+	MaxAgeSec        int64    `json:"max_age_sec,omitempty" binding:"min=0"`
+}
+
+type RouteCorsPolicy struct {
+	Path string      `json:"path" binding:"required,startswith=/,min=2"`
+	Cors *CorsPolicy `json:"cors" binding:"required"`
+}
@@ -0,0 +1,23 @@
+package protocol
+
+import "mime/multipart"
+
+// multipart form with uploaded file
+type FileEndpoint struct {
+	Path string `json:"path" form:"path" binding:"required,startswith=/,min=2"`
+	// detected from file name extension or content if not set
+	ContentType string                `json:"content_type,omitempty" form:"content_type"`
+	File        *multipart.FileHeader `json:"file" form:"file" binding:"required"`
+
+	RouteWindow
+}
+
+type FileEndpointInfo struct {
+	Path        string `json:"path"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	// sha256 of the content, also sent in ETag header
+	Digest string `json:"digest"`
+
+	RouteWindow
+}
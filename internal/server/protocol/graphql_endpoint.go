@@ -0,0 +1,18 @@
+package protocol
+
+import "encoding/json"
+
+type GraphQLOperation struct {
+	OperationName string                 `json:"operation_name" binding:"required"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+	Response      json.RawMessage        `json:"response,omitempty"`
+	Code          string                 `json:"code,omitempty" binding:"omitempty,startswith=def func"`
+}
+
+type GraphQLEndpoint struct {
+	Path       string             `json:"path" binding:"required,startswith=/,min=2"`
+	Schema     string             `json:"schema,omitempty"`
+	Operations []GraphQLOperation `json:"operations" binding:"dive"`
+
+	RouteWindow
+}
@@ -0,0 +1,20 @@
+package protocol
+
+const (
+	HEALTH_STATUS_UP   = "up"
+	HEALTH_STATUS_DOWN = "down"
+)
+
+type ComponentHealth struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+
+	// coderun only
+	RunningWorkers *int `json:"running_workers,omitempty"`
+	Workers        *int `json:"workers,omitempty"`
+}
+
+type HealthReport struct {
+	Status     string                     `json:"status"`
+	Components map[string]ComponentHealth `json:"components"`
+}
@@ -0,0 +1,24 @@
+package protocol
+
+const (
+	// sort by creation order, default
+	LIST_SORT_CREATED = "created"
+
+	LIST_ORDER_ASC  = "asc"
+	LIST_ORDER_DESC = "desc"
+)
+
+// query params of list endpoints, filters not supported
+// by listed collection are ignored
+type ListQuery struct {
+	Limit int64 `form:"limit" binding:"omitempty,min=1,max=1000"`
+	// next_cursor of previous page
+	Cursor string `form:"cursor"`
+	Sort   string `form:"sort"`
+	Order  string `form:"order" binding:"omitempty,oneof=asc desc"`
+
+	Prefix string `form:"prefix"`
+	Search string `form:"search"`
+	Broker string `form:"broker" binding:"omitempty,oneof=rabbitmq kafka"`
+	Type   string `form:"type" binding:"omitempty,oneof=static_endpoint proxy_endpoint dynamic_endpoint graphql_endpoint websocket_endpoint stream_endpoint file_endpoint"`
+}
@@ -0,0 +1,7 @@
+package protocol
+
+type Namespace struct {
+	Prefix    string           `json:"prefix" binding:"required,startswith=/"`
+	Cors      *CorsPolicy      `json:"cors,omitempty"`
+	RateLimit *RateLimitPolicy `json:"rate_limit,omitempty"`
+}
@@ -0,0 +1,14 @@
+package protocol
+
+type RateLimitPolicy struct {
+	Limit     int64  `json:"limit" binding:"required,min=1"`
+	PeriodSec int64  `json:"period_sec" binding:"required,min=1"`
+	Burst     int64  `json:"burst,omitempty" binding:"min=0"`
+	KeyBy     string `json:"key_by" binding:"required,oneof=ip header"`
+	Header    string `json:"header,omitempty" binding:"required_if=KeyBy header"`
+}
+
+type RouteRateLimitPolicy struct {
+	Path      string           `json:"path" binding:"required,startswith=/,min=2"`
+	RateLimit *RateLimitPolicy `json:"rate_limit" binding:"required"`
+}
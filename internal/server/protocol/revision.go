@@ -0,0 +1,53 @@
+package protocol
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type RevisionQuery struct {
+	ListQuery
+
+	Resource string `form:"resource" binding:"required,oneof=route esb"`
+	// exact route path or esb in-pool name
+	Key string `form:"key" binding:"required"`
+}
+
+type RevisionDiffQuery struct {
+	Resource string `form:"resource" binding:"required,oneof=route esb"`
+	Key      string `form:"key" binding:"required"`
+	From     int64  `form:"from" binding:"required,min=1"`
+	To       int64  `form:"to" binding:"required,min=1"`
+}
+
+type RevisionRollback struct {
+	Resource string `json:"resource" binding:"required,oneof=route esb"`
+	Key      string `json:"key" binding:"required"`
+	Revision int64  `json:"revision" binding:"required,min=1"`
+}
+
+type Revision struct {
+	Revision  int64     `json:"revision"`
+	Timestamp time.Time `json:"timestamp"`
+	Actor     string    `json:"actor,omitempty"`
+	// route or esb record definition in mongo relaxed extended json
+	State json.RawMessage `json:"state"`
+	// user code of dynamic handler or esb mapper
+	Code string `json:"code,omitempty"`
+}
+
+// field missing in one of revisions has no value on that side
+type FieldChange struct {
+	Field  string          `json:"field"`
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
+
+type RevisionDiff struct {
+	From   int64         `json:"from"`
+	To     int64         `json:"to"`
+	Fields []FieldChange `json:"fields"`
+	// lines of code prefixed with "  " if kept, "- " if removed, "+ " if added,
+	// missing if code did not change
+	Code []string `json:"code,omitempty"`
+}
@@ -0,0 +1,32 @@
+package protocol
+
+import "time"
+
+const (
+	// request uri with query must be equal to route path
+	ROUTE_MATCHER_URI = "uri"
+	// query of request is ignored if no route matches the whole uri
+	ROUTE_MATCHER_PATH = "path"
+
+	ROUTE_METHOD_ANY = "*"
+)
+
+// summary of mock route of any type
+type RouteInfo struct {
+	Path    string   `json:"path"`
+	Type    string   `json:"type"`
+	Methods []string `json:"methods"`
+	Matcher string   `json:"matcher"`
+
+	// static routes only
+	Response string `json:"response,omitempty"`
+	// proxy routes only
+	ProxyURL string `json:"proxy_url,omitempty"`
+	// name of stored script for routes handled by user code
+	ScriptName string `json:"script_name,omitempty"`
+
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	Hits      int64      `json:"hits"`
+	Active    bool       `json:"active"`
+}
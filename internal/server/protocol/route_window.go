@@ -0,0 +1,13 @@
+package protocol
+
+import "time"
+
+// optional lifetime of a mock route, embedded into endpoint configs,
+// form tags serve routes created from multipart forms
+type RouteWindow struct {
+	// ttl_sec and expire_at are mutually exclusive
+	TTLSec      int64      `json:"ttl_sec,omitempty" form:"ttl_sec" binding:"min=0"`
+	ExpireAt    *time.Time `json:"expire_at,omitempty" form:"expire_at"`
+	ActiveFrom  *time.Time `json:"active_from,omitempty" form:"active_from"`
+	ActiveUntil *time.Time `json:"active_until,omitempty" form:"active_until"`
+}
@@ -1,6 +1,17 @@
 package protocol
 
+type Representation struct {
+	// media type without wildcards, e.g. application/xml
+	ContentType string `json:"content_type" binding:"required"`
+	Body        string `json:"body"`
+}
+
+// with representations the body is chosen by accept header of request,
+// expected response is json encoded response to any request otherwise
 type StaticEndpoint struct {
-	Path             string `json:"path" binding:"required,startswith=/,min=2"`
-	ExpectedResponse string `json:"expected_response" binding:"required,min=1"`
+	Path             string           `json:"path" binding:"required,startswith=/,min=2"`
+	ExpectedResponse string           `json:"expected_response,omitempty" binding:"required_without=Representations"`
+	Representations  []Representation `json:"representations,omitempty" binding:"omitempty,min=1,dive"`
+
+	RouteWindow
 }
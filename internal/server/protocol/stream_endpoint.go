@@ -0,0 +1,18 @@
+package protocol
+
+type StreamEvent struct {
+	Event   string `json:"event,omitempty"`
+	Data    string `json:"data" binding:"required"`
+	Id      string `json:"id,omitempty"`
+	DelayMs int64  `json:"delay_ms,omitempty" binding:"min=0"`
+}
+
+type StreamEndpoint struct {
+	Path        string        `json:"path" binding:"required,startswith=/,min=2"`
+	Mode        string        `json:"mode" binding:"required,oneof=sse chunked"`
+	Events      []StreamEvent `json:"events" binding:"required,min=1,dive"`
+	Loop        bool          `json:"loop,omitempty"`
+	ContentType string        `json:"content_type,omitempty"`
+
+	RouteWindow
+}
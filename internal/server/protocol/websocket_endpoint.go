@@ -0,0 +1,21 @@
+package protocol
+
+type WebSocketReply struct {
+	Match    string `json:"match" binding:"required"`
+	Regexp   bool   `json:"regexp,omitempty"`
+	Response string `json:"response" binding:"required"`
+}
+
+type WebSocketEndpoint struct {
+	Path      string           `json:"path" binding:"required,startswith=/,min=2"`
+	OnConnect []string         `json:"on_connect,omitempty"`
+	Replies   []WebSocketReply `json:"replies,omitempty" binding:"dive"`
+	Code      string           `json:"code,omitempty" binding:"omitempty,startswith=def func"`
+
+	RouteWindow
+}
+
+type WebSocketBroadcast struct {
+	Path    string `json:"path" binding:"required,startswith=/,min=2"`
+	Message string `json:"message" binding:"required"`
+}
@@ -5,6 +5,7 @@ import (
 	"mock-server/internal/server/protocol"
 	"net/http"
 	"net/url"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	zlog "github.com/rs/zerolog/log"
@@ -14,24 +15,13 @@ import (
 func (s *server) initRoutesApiProxy(routes *gin.RouterGroup) {
 	proxyRoutesEndpoint := "/proxy"
 
-	routes.GET(proxyRoutesEndpoint, func(c *gin.Context) {
-		zlog.Info().Msg("Get all routes proxy request")
-		endpoints, err := database.ListAllProxyEndpointPaths(c)
-
-		if err != nil {
-			zlog.Error().Err(err).Msg("Failed to list all proxy endpoints paths")
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
-
-		c.JSON(http.StatusOK, gin.H{"endpoints": endpoints})
-	})
+	routes.GET(proxyRoutesEndpoint, listRoutePathsHandler(database.PROXY_ENDPOINT_TYPE))
 
 	routes.GET(proxyRoutesEndpoint+"/proxy_url", func(c *gin.Context) {
 		path := c.Query("path")
 		if path == "" {
 			zlog.Error().Msg("Path param not specified")
-			c.JSON(http.StatusBadRequest, gin.H{"error": "specify path param"})
+			apiError(c, http.StatusBadRequest, nil, "specify path param")
 			return
 		}
 
@@ -43,22 +33,53 @@ func (s *server) initRoutesApiProxy(routes *gin.RouterGroup) {
 			zlog.Info().Str("proxy url ", proxyUrl).Msg("Got url")
 		case database.ErrNoSuchPath, database.ErrBadRouteType:
 			zlog.Error().Msg("Request for unexisting proxy route")
-			c.JSON(http.StatusNotFound, gin.H{"error": "Received path was not created before"})
+			apiError(c, http.StatusNotFound, err, "Received path was not created before")
 			return
 		default:
 			zlog.Error().Err(err).Msg("Failed to query proxy url")
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			apiError(c, http.StatusInternalServerError, err, err.Error())
 			return
 		}
 
 		c.JSON(http.StatusOK, proxyUrl)
 	})
 
+	routes.GET(proxyRoutesEndpoint+"/config", func(c *gin.Context) {
+		path := c.Query("path")
+		if path == "" {
+			zlog.Error().Msg("Path param not specified")
+			apiError(c, http.StatusBadRequest, nil, "specify path param")
+			return
+		}
+
+		zlog.Info().Str("path", path).Msg("Received get config proxy request")
+
+		route, err := database.GetProxyEndpoint(c, path)
+		switch err {
+		case nil:
+			zlog.Info().Str("path", path).Msg("Got proxy route")
+		case database.ErrNoSuchPath, database.ErrBadRouteType:
+			zlog.Error().Msg("Request for unexisting proxy route")
+			apiError(c, http.StatusNotFound, err, "Received path was not created before")
+			return
+		default:
+			zlog.Error().Err(err).Msg("Failed to query proxy route")
+			apiError(c, http.StatusInternalServerError, err, err.Error())
+			return
+		}
+
+		c.JSON(http.StatusOK, protocol.ProxyEndpoint{
+			Path:        route.Path,
+			ProxyUrl:    route.ProxyURL,
+			RouteWindow: toProtocolRouteWindow(&route.ActivityWindow),
+		})
+	})
+
 	routes.POST(proxyRoutesEndpoint, func(c *gin.Context) {
 		var proxyEndpoint protocol.ProxyEndpoint
 		if err := c.Bind(&proxyEndpoint); err != nil {
 			zlog.Error().Err(err).Msg("Failed to bind request")
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			apiError(c, http.StatusBadRequest, err, err.Error())
 			return
 		}
 
@@ -69,11 +90,22 @@ func (s *server) initRoutesApiProxy(routes *gin.RouterGroup) {
 
 		if _, err := url.ParseRequestURI(proxyEndpoint.ProxyUrl); err != nil {
 			zlog.Error().Err(err).Msg("Failed to parse incoming proxy url")
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			apiError(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
+
+		if err := validateRouteWindow(&proxyEndpoint.RouteWindow, time.Now()); err != nil {
+			zlog.Error().Err(err).Msg("Invalid route window")
+			apiError(c, http.StatusBadRequest, err, err.Error())
 			return
 		}
 
-		err := database.AddProxyEndpoint(c, proxyEndpoint.Path, proxyEndpoint.ProxyUrl)
+		err := database.AddProxyEndpoint(
+			c,
+			proxyEndpoint.Path,
+			proxyEndpoint.ProxyUrl,
+			routeWindowOption(&proxyEndpoint.RouteWindow),
+		)
 
 		switch err {
 		case nil:
@@ -81,10 +113,10 @@ func (s *server) initRoutesApiProxy(routes *gin.RouterGroup) {
 			c.JSON(http.StatusOK, "Proxy endpoint successfully added!")
 		case database.ErrDuplicateKey:
 			zlog.Error().Str("path", proxyEndpoint.Path).Msg("Endpoint with this path already exists")
-			c.JSON(http.StatusConflict, gin.H{"error": "The same endpoint already exists"})
+			apiError(c, http.StatusConflict, err, "The same endpoint already exists")
 		default:
 			zlog.Error().Err(err).Msg("Failed to add proxy endpoint")
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			apiError(c, http.StatusInternalServerError, err, err.Error())
 		}
 	})
 
@@ -92,29 +124,40 @@ func (s *server) initRoutesApiProxy(routes *gin.RouterGroup) {
 		var proxyEndpoint protocol.ProxyEndpoint
 		if err := c.Bind(&proxyEndpoint); err != nil {
 			zlog.Error().Err(err).Msg("Failed to bind request")
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			apiError(c, http.StatusBadRequest, err, err.Error())
 			return
 		}
 
 		if _, err := url.ParseRequestURI(proxyEndpoint.ProxyUrl); err != nil {
 			zlog.Error().Err(err).Msg("Failed to parse incoming proxy url")
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			apiError(c, http.StatusBadRequest, err, err.Error())
 			return
 		}
 
 		zlog.Info().Str("path", proxyEndpoint.Path).Msg("Received update proxy request")
 
-		err := database.UpdateProxyEndpoint(c, proxyEndpoint.Path, proxyEndpoint.ProxyUrl)
+		if err := validateRouteWindow(&proxyEndpoint.RouteWindow, time.Now()); err != nil {
+			zlog.Error().Err(err).Msg("Invalid route window")
+			apiError(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
+
+		err := database.UpdateProxyEndpoint(
+			c,
+			proxyEndpoint.Path,
+			proxyEndpoint.ProxyUrl,
+			routeWindowOption(&proxyEndpoint.RouteWindow),
+		)
 		switch err {
 		case nil:
 			zlog.Info().Str("path", proxyEndpoint.Path).Msg("Proxy endpoint updated")
 			c.JSON(http.StatusNoContent, "Proxy endpoint successfully updated!")
 		case database.ErrNoSuchPath:
 			zlog.Error().Msg("Update on unexisting path")
-			c.JSON(http.StatusNotFound, gin.H{"error": "Received path was not created before"})
+			apiError(c, http.StatusNotFound, err, "Received path was not created before")
 		default:
 			zlog.Error().Err(err).Msg("Failed to add proxy endpoint")
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			apiError(c, http.StatusInternalServerError, err, err.Error())
 		}
 	})
 
@@ -122,7 +165,7 @@ func (s *server) initRoutesApiProxy(routes *gin.RouterGroup) {
 		path := c.Query("path")
 		if path == "" {
 			zlog.Error().Msg("Path param not specified")
-			c.JSON(http.StatusBadRequest, gin.H{"error": "specify path param"})
+			apiError(c, http.StatusBadRequest, nil, "specify path param")
 			return
 		}
 
@@ -136,10 +179,10 @@ func (s *server) initRoutesApiProxy(routes *gin.RouterGroup) {
 			c.JSON(http.StatusNoContent, "Proxy endpoint successfully removed!")
 		case database.ErrNoSuchPath:
 			zlog.Error().Msg("Delete on unexisting path")
-			c.JSON(http.StatusNotFound, gin.H{"error": "Received path was not created before"})
+			apiError(c, http.StatusNotFound, err, "Received path was not created before")
 		default:
 			zlog.Error().Err(err).Msg("Failed to remove proxy endpoint")
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			apiError(c, http.StatusInternalServerError, err, err.Error())
 		}
 	})
 }
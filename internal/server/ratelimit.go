@@ -0,0 +1,167 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"mock-server/internal/database"
+	"mock-server/internal/server/protocol"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	zlog "github.com/rs/zerolog/log"
+)
+
+// idle buckets are dropped by sweep not more often than this
+const rateLimitSweepInterval = time.Minute
+
+type tokenBucket struct {
+	tokens   float64
+	capacity float64
+	rate     float64 // tokens per second
+	updated  time.Time
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	b.tokens = math.Min(b.capacity, b.tokens+now.Sub(b.updated).Seconds()*b.rate)
+	b.updated = now
+}
+
+type rateLimitResult struct {
+	allowed    bool
+	limit      int64
+	remaining  int64
+	retryAfter time.Duration // until next token
+	reset      time.Duration // until bucket is full
+}
+
+// in-memory buckets of all rate limited routes and namespaces,
+// state is lost on restart and not shared between instances
+type rateLimiter struct {
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+	mtx       sync.Mutex
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		buckets:   make(map[string]*tokenBucket),
+		lastSweep: time.Now(),
+	}
+}
+
+func rateLimitCapacity(policy *database.RateLimitPolicy) int64 {
+	if policy.Burst > 0 {
+		return policy.Burst
+	}
+	return policy.Limit
+}
+
+// policy is a part of the key, so bucket is recreated on policy change
+func rateLimitBucketKey(scope string, policy *database.RateLimitPolicy, client string) string {
+	return fmt.Sprintf("%s|%d/%d/%d|%s", scope, policy.Limit, policy.PeriodSec, policy.Burst, client)
+}
+
+func (rl *rateLimiter) take(key string, policy *database.RateLimitPolicy, now time.Time) rateLimitResult {
+	rl.mtx.Lock()
+	defer rl.mtx.Unlock()
+
+	if now.Sub(rl.lastSweep) > rateLimitSweepInterval {
+		rl.sweep(now)
+	}
+
+	bucket, ok := rl.buckets[key]
+	if !ok {
+		capacity := float64(rateLimitCapacity(policy))
+		bucket = &tokenBucket{
+			tokens:   capacity,
+			capacity: capacity,
+			rate:     float64(policy.Limit) / float64(policy.PeriodSec),
+			updated:  now,
+		}
+		rl.buckets[key] = bucket
+	}
+	bucket.refill(now)
+
+	res := rateLimitResult{limit: int64(bucket.capacity)}
+	if bucket.tokens >= 1 {
+		bucket.tokens -= 1
+		res.allowed = true
+	} else {
+		res.retryAfter = time.Duration((1 - bucket.tokens) / bucket.rate * float64(time.Second))
+	}
+	res.remaining = int64(math.Floor(bucket.tokens))
+	res.reset = time.Duration((bucket.capacity - bucket.tokens) / bucket.rate * float64(time.Second))
+	return res
+}
+
+// must be called under lock
+func (rl *rateLimiter) sweep(now time.Time) {
+	for key, bucket := range rl.buckets {
+		bucket.refill(now)
+		if bucket.tokens >= bucket.capacity {
+			delete(rl.buckets, key)
+		}
+	}
+	rl.lastSweep = now
+}
+
+func rateLimitClientKey(c *gin.Context, policy *database.RateLimitPolicy) string {
+	if policy.KeyBy == database.RATE_LIMIT_KEY_BY_HEADER {
+		if value := c.GetHeader(policy.Header); value != "" {
+			return "header:" + value
+		}
+		// clients without header are limited by address
+	}
+	return "ip:" + c.ClientIP()
+}
+
+func ceilSeconds(d time.Duration) int64 {
+	return int64(math.Ceil(d.Seconds()))
+}
+
+// aborts request with 429 if limit is exceeded
+func (s *server) applyRateLimitPolicy(c *gin.Context, scope string, policy *database.RateLimitPolicy) {
+	key := rateLimitBucketKey(scope, policy, rateLimitClientKey(c, policy))
+	res := s.rateLimiter.take(key, policy, time.Now())
+
+	c.Header("X-RateLimit-Limit", strconv.FormatInt(res.limit, 10))
+	c.Header("X-RateLimit-Remaining", strconv.FormatInt(res.remaining, 10))
+	c.Header("X-RateLimit-Reset", strconv.FormatInt(ceilSeconds(res.reset), 10))
+
+	if res.allowed {
+		return
+	}
+
+	zlog.Info().Str("scope", scope).Str("key", key).Msg("Rate limit exceeded")
+	c.Header("Retry-After", strconv.FormatInt(ceilSeconds(res.retryAfter), 10))
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded"})
+}
+
+func toDatabaseRateLimitPolicy(policy *protocol.RateLimitPolicy) *database.RateLimitPolicy {
+	if policy == nil {
+		return nil
+	}
+	return &database.RateLimitPolicy{
+		Limit:     policy.Limit,
+		PeriodSec: policy.PeriodSec,
+		Burst:     policy.Burst,
+		KeyBy:     policy.KeyBy,
+		Header:    policy.Header,
+	}
+}
+
+func toProtocolRateLimitPolicy(policy *database.RateLimitPolicy) *protocol.RateLimitPolicy {
+	if policy == nil {
+		return nil
+	}
+	return &protocol.RateLimitPolicy{
+		Limit:     policy.Limit,
+		PeriodSec: policy.PeriodSec,
+		Burst:     policy.Burst,
+		KeyBy:     policy.KeyBy,
+		Header:    policy.Header,
+	}
+}
@@ -0,0 +1,266 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"mock-server/internal/brokers"
+	"mock-server/internal/database"
+	"mock-server/internal/server/protocol"
+	"mock-server/internal/util"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	zlog "github.com/rs/zerolog/log"
+)
+
+// resources whose definitions are numbered into revisions on every change
+var revisionedResources = map[string]bool{
+	database.AUDIT_RESOURCE_ROUTE: true,
+	database.AUDIT_RESOURCE_ESB:   true,
+}
+
+// stores definition of existing resource as new revision
+func (s *server) addRevision(c *gin.Context, resource string, key string, snapshot database.Snapshot, code string) error {
+	state, err := database.NewRevisionState(snapshot)
+	if err != nil {
+		return err
+	}
+
+	// code of dynamic handler is kept as revision code
+	var scripts map[string]string
+	if resource == database.AUDIT_RESOURCE_ROUTE {
+		var route database.Route
+		if err := snapshot.Decode(&route); err != nil {
+			return err
+		}
+		if route.Type != database.DYNAMIC_ENDPOINT_TYPE {
+			if scripts, err = s.readRouteScripts(&route); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err = database.AddRevision(c, database.Revision{
+		Resource:  resource,
+		Key:       key,
+		Timestamp: time.Now(),
+		Actor:     c.GetString(AUTH_IDENTITY_KEY),
+		State:     state,
+		Code:      code,
+		Scripts:   scripts,
+	})
+	return err
+}
+
+// writes code of revision back to script file, then replaces stored definition
+func (s *server) rollbackRevision(c *gin.Context, revision *database.Revision) error {
+	switch revision.Resource {
+	case database.AUDIT_RESOURCE_ROUTE:
+		var route database.Route
+		if err := revision.DecodeState(&route); err != nil {
+			return err
+		}
+		scripts := revision.Scripts
+		if route.Type == database.DYNAMIC_ENDPOINT_TYPE {
+			scripts = map[string]string{route.ScriptName: string(util.WrapCodeForDynHandle(revision.Code))}
+		}
+		return s.putBackRoute(c, revision.Key, &route, scripts, func() error {
+			return database.RollbackRoute(c, revision.Key, revision.State)
+		})
+	default:
+		var record database.ESBRecord
+		if err := revision.DecodeState(&record); err != nil {
+			return err
+		}
+		if record.MapperScriptName != brokers.EMPTY_MAPPER {
+			if err := s.fs.Write(FS_ESB_DIR, record.MapperScriptName, util.WrapCodeForEsb(revision.Code)); err != nil {
+				return err
+			}
+		}
+		return database.RollbackESBRecord(c, revision.Key, revision.State)
+	}
+}
+
+func toProtocolRevision(revision *database.Revision) (protocol.Revision, error) {
+	state, err := database.AuditStateJSON(revision.State)
+	if err != nil {
+		return protocol.Revision{}, err
+	}
+
+	return protocol.Revision{
+		Revision:  revision.Number,
+		Timestamp: revision.Timestamp,
+		Actor:     revision.Actor,
+		State:     state,
+		Code:      revision.Code,
+	}, nil
+}
+
+// top level fields of definitions which differ, sorted by name
+func diffRevisionStates(from *database.Revision, to *database.Revision) ([]protocol.FieldChange, error) {
+	var fieldsFrom, fieldsTo map[string]json.RawMessage
+	for _, it := range []struct {
+		revision *database.Revision
+		fields   *map[string]json.RawMessage
+	}{{from, &fieldsFrom}, {to, &fieldsTo}} {
+		state, err := database.AuditStateJSON(it.revision.State)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(state, it.fields); err != nil {
+			return nil, err
+		}
+	}
+
+	names := make([]string, 0, len(fieldsFrom)+len(fieldsTo))
+	for name := range fieldsFrom {
+		names = append(names, name)
+	}
+	for name := range fieldsTo {
+		if _, ok := fieldsFrom[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	changes := make([]protocol.FieldChange, 0)
+	for _, name := range names {
+		before, after := fieldsFrom[name], fieldsTo[name]
+		if !bytes.Equal(before, after) {
+			changes = append(changes, protocol.FieldChange{Field: name, Before: before, After: after})
+		}
+	}
+	return changes, nil
+}
+
+func revisionError(c *gin.Context, err error) {
+	switch err {
+	case database.ErrNoSuchRevision:
+		zlog.Error().Msg("No such revision")
+		apiError(c, http.StatusNotFound, err, "No such revision")
+	default:
+		zlog.Error().Err(err).Msg("Failed to get revision")
+		apiError(c, http.StatusInternalServerError, err, err.Error())
+	}
+}
+
+// numbered definitions of routes and esb records
+func (s *server) initRevisionsApi(admin *gin.RouterGroup) {
+	revisionsEndpoint := "/revisions"
+
+	admin.GET(revisionsEndpoint, func(c *gin.Context) {
+		var query protocol.RevisionQuery
+		if err := c.BindQuery(&query); err != nil {
+			zlog.Error().Err(err).Msg("Invalid revisions query")
+			apiError(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
+
+		zlog.Info().Str("resource", query.Resource).Str("key", query.Key).Msg("List revisions request")
+
+		page, err := database.FindRevisions(c, query.Resource, query.Key, toDatabaseListQuery(c, &query.ListQuery))
+		if err != nil {
+			listPageError(c, err)
+			return
+		}
+
+		revisions := make([]protocol.Revision, 0, len(page.Items))
+		for i := range page.Items {
+			revision, err := toProtocolRevision(&page.Items[i])
+			if err != nil {
+				zlog.Error().Err(err).Msg("Failed to convert revision")
+				apiError(c, http.StatusInternalServerError, err, err.Error())
+				return
+			}
+			revisions = append(revisions, revision)
+		}
+
+		c.JSON(http.StatusOK, pageResponse("revisions", revisions, page.NextCursor))
+	})
+
+	admin.GET(revisionsEndpoint+"/diff", func(c *gin.Context) {
+		var query protocol.RevisionDiffQuery
+		if err := c.BindQuery(&query); err != nil {
+			zlog.Error().Err(err).Msg("Invalid revisions diff query")
+			apiError(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
+
+		zlog.Info().
+			Str("resource", query.Resource).
+			Str("key", query.Key).
+			Int64("from", query.From).
+			Int64("to", query.To).
+			Msg("Diff revisions request")
+
+		from, err := database.GetRevision(c, query.Resource, query.Key, query.From)
+		if err != nil {
+			revisionError(c, err)
+			return
+		}
+		to, err := database.GetRevision(c, query.Resource, query.Key, query.To)
+		if err != nil {
+			revisionError(c, err)
+			return
+		}
+
+		fields, err := diffRevisionStates(&from, &to)
+		if err != nil {
+			zlog.Error().Err(err).Msg("Failed to diff revisions")
+			apiError(c, http.StatusInternalServerError, err, err.Error())
+			return
+		}
+
+		diff := protocol.RevisionDiff{From: from.Number, To: to.Number, Fields: fields}
+		if from.Code != to.Code {
+			diff.Code = util.DiffLines(from.Code, to.Code)
+		}
+		c.JSON(http.StatusOK, diff)
+	})
+
+	// restores definition and code of revision, rollback itself is recorded as new revision
+	admin.POST(revisionsEndpoint+"/rollback", func(c *gin.Context) {
+		var rollback protocol.RevisionRollback
+		if err := c.Bind(&rollback); err != nil {
+			zlog.Error().Err(err).Msg("Failed to bind request")
+			apiError(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
+
+		zlog.Info().
+			Str("resource", rollback.Resource).
+			Str("key", rollback.Key).
+			Int64("revision", rollback.Revision).
+			Msg("Received revision rollback request")
+
+		revision, err := database.GetRevision(c, rollback.Resource, rollback.Key, rollback.Revision)
+		if err != nil {
+			revisionError(c, err)
+			return
+		}
+
+		before, err := s.auditState(c, rollback.Resource, rollback.Key)
+		if err != nil {
+			zlog.Error().Err(err).Msg("Failed to get audited state")
+		}
+
+		if err := s.rollbackRevision(c, &revision); err != nil {
+			zlog.Error().Err(err).Msg("Failed to roll back revision")
+			apiError(c, http.StatusInternalServerError, err, err.Error())
+			return
+		}
+
+		s.recordChange(c, database.AuditRecord{
+			Operation: database.AUDIT_OP_ROLLBACK,
+			Resource:  rollback.Resource,
+			Key:       rollback.Key,
+			Status:    http.StatusNoContent,
+			Before:    before,
+		})
+
+		zlog.Info().Str("key", rollback.Key).Int64("revision", rollback.Revision).Msg("Revision rolled back")
+		c.JSON(http.StatusNoContent, "Revision successfully rolled back")
+	})
+}
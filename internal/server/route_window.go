@@ -0,0 +1,54 @@
+package server
+
+import (
+	"errors"
+	"mock-server/internal/database"
+	"mock-server/internal/server/protocol"
+	"time"
+)
+
+func validateRouteWindow(window *protocol.RouteWindow, now time.Time) error {
+	if window.TTLSec != 0 && window.ExpireAt != nil {
+		return errors.New("ttl_sec and expire_at are mutually exclusive")
+	}
+	if window.ExpireAt != nil && !window.ExpireAt.After(now) {
+		return errors.New("expire_at must be in the future")
+	}
+	if window.ActiveUntil != nil && !window.ActiveUntil.After(now) {
+		return errors.New("active_until must be in the future")
+	}
+	if window.ActiveFrom != nil && window.ActiveUntil != nil && !window.ActiveFrom.Before(*window.ActiveUntil) {
+		return errors.New("active_from must be before active_until")
+	}
+	return nil
+}
+
+// route is removed after the end of activity window as well
+func toDatabaseActivityWindow(window *protocol.RouteWindow, now time.Time) database.ActivityWindow {
+	expireAt := window.ExpireAt
+	if window.TTLSec != 0 {
+		ttlExpireAt := now.Add(time.Duration(window.TTLSec) * time.Second)
+		expireAt = &ttlExpireAt
+	}
+	if window.ActiveUntil != nil && (expireAt == nil || window.ActiveUntil.Before(*expireAt)) {
+		expireAt = window.ActiveUntil
+	}
+
+	return database.ActivityWindow{
+		ActiveFrom:  window.ActiveFrom,
+		ActiveUntil: window.ActiveUntil,
+		ExpireAt:    expireAt,
+	}
+}
+
+func toProtocolRouteWindow(window *database.ActivityWindow) protocol.RouteWindow {
+	return protocol.RouteWindow{
+		ExpireAt:    window.ExpireAt,
+		ActiveFrom:  window.ActiveFrom,
+		ActiveUntil: window.ActiveUntil,
+	}
+}
+
+func routeWindowOption(window *protocol.RouteWindow) database.RouteOption {
+	return database.WithActivityWindow(toDatabaseActivityWindow(window, time.Now()))
+}
@@ -0,0 +1,70 @@
+package server
+
+import (
+	"mock-server/internal/database"
+	"mock-server/internal/server/protocol"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	zlog "github.com/rs/zerolog/log"
+)
+
+// http methods served by route type
+var routeTypeMethods = map[string][]string{
+	database.GRAPHQL_ENDPOINT_TYPE:   {http.MethodGet, http.MethodPost},
+	database.WEBSOCKET_ENDPOINT_TYPE: {http.MethodGet},
+}
+
+func toProtocolRouteInfo(route *database.Route, now time.Time) protocol.RouteInfo {
+	methods, ok := routeTypeMethods[route.Type]
+	if !ok {
+		methods = []string{protocol.ROUTE_METHOD_ANY}
+	}
+
+	matcher := protocol.ROUTE_MATCHER_URI
+	if queryAgnosticRouteTypes[route.Type] {
+		matcher = protocol.ROUTE_MATCHER_PATH
+	}
+
+	return protocol.RouteInfo{
+		Path:       route.Path,
+		Type:       route.Type,
+		Methods:    methods,
+		Matcher:    matcher,
+		Response:   route.Response,
+		ProxyURL:   route.ProxyURL,
+		ScriptName: route.ScriptName,
+		CreatedAt:  route.CreatedAt,
+		UpdatedAt:  route.UpdatedAt,
+		Hits:       route.Hits,
+		Active:     route.IsActive(now),
+	}
+}
+
+// all mock routes with details in one request
+func (s *server) initRoutesApiList(routes *gin.RouterGroup) {
+	routes.GET("", func(c *gin.Context) {
+		zlog.Info().Msg("List all routes request")
+
+		query, ok := bindListQuery(c)
+		if !ok {
+			return
+		}
+
+		page, err := database.ListRoutes(c, query)
+		if err != nil {
+			zlog.Error().Err(err).Msg("Failed to list routes")
+			listPageError(c, err)
+			return
+		}
+
+		now := time.Now()
+		respRoutes := make([]protocol.RouteInfo, 0, len(page.Items))
+		for i := range page.Items {
+			respRoutes = append(respRoutes, toProtocolRouteInfo(&page.Items[i], now))
+		}
+
+		c.JSON(http.StatusOK, pageResponse("routes", respRoutes, page.NextCursor))
+	})
+}
@@ -0,0 +1,157 @@
+package server
+
+import (
+	"context"
+	"mock-server/internal/database"
+	"time"
+
+	zlog "github.com/rs/zerolog/log"
+)
+
+const (
+	SCRIPTS_SWEEP_INTERVAL = 5 * time.Minute
+	// younger scripts may belong to route which is being created
+	SCRIPTS_SWEEP_MIN_AGE = time.Minute
+)
+
+// scripts run by route: dynamic or websocket handler and graphql operations
+func routeScripts(route *database.Route) []string {
+	var scripts []string
+	if route.ScriptName != "" {
+		scripts = append(scripts, route.ScriptName)
+	}
+	for _, op := range route.Operations {
+		if op.ScriptName != "" {
+			scripts = append(scripts, op.ScriptName)
+		}
+	}
+	return scripts
+}
+
+// files of route scripts by name, see writeScripts
+func (s *server) readRouteScripts(route *database.Route) (map[string]string, error) {
+	names := routeScripts(route)
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	scripts := make(map[string]string, len(names))
+	for _, name := range names {
+		script, err := s.fs.Read(FS_DYN_HANDLE_DIR, name)
+		if err != nil {
+			return nil, err
+		}
+		scripts[name] = script
+	}
+	return scripts, nil
+}
+
+// puts back scripts of route definition which is restored
+func (s *server) writeScripts(scripts map[string]string) error {
+	for name, script := range scripts {
+		if err := s.fs.Write(FS_DYN_HANDLE_DIR, name, []byte(script)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removes scripts which are not run by any route, failure is only logged,
+// empty names of routes without handler are skipped
+func (s *server) removeScripts(names ...string) {
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		if err := s.fs.Remove(FS_DYN_HANDLE_DIR, name); err != nil {
+			zlog.Error().Err(err).Str("script name", name).Msg("Failed to remove script")
+		}
+	}
+}
+
+// removes scripts of replaced route definition which current one does not run,
+// current is nil if route was removed
+func (s *server) removeReplacedScripts(replaced *database.Route, current *database.Route) {
+	kept := make(map[string]bool)
+	if current != nil {
+		for _, name := range routeScripts(current) {
+			kept[name] = true
+		}
+	}
+
+	for _, name := range routeScripts(replaced) {
+		if !kept[name] {
+			s.removeScripts(name)
+		}
+	}
+}
+
+// writes scripts of route definition which is put back, then replaces stored
+// definition with put; restored is nil if route is removed by put, scripts
+// of replaced definition are removed if restored one does not run them
+func (s *server) putBackRoute(ctx context.Context, path string, restored *database.Route, scripts map[string]string, put func() error) error {
+	current, err := database.GetRoute(ctx, path)
+	switch err {
+	case nil, database.ErrNoSuchPath:
+	default:
+		return err
+	}
+
+	if err := s.writeScripts(scripts); err != nil {
+		return err
+	}
+	if err := put(); err != nil {
+		return err
+	}
+
+	s.removeReplacedScripts(&current, restored)
+	return nil
+}
+
+// removes scripts which no stored route runs, like scripts of routes removed
+// by ttl index; revisions keep their own copy of scripts
+func (s *server) sweepScripts(ctx context.Context) (int, error) {
+	referenced, err := database.ListRouteScripts(ctx)
+	if err != nil {
+		return 0, err
+	}
+	kept := make(map[string]bool, len(referenced))
+	for _, name := range referenced {
+		kept[name] = true
+	}
+
+	files, err := s.fs.List(FS_DYN_HANDLE_DIR)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, file := range files {
+		if kept[file.Name()] || time.Since(file.ModTime()) < SCRIPTS_SWEEP_MIN_AGE {
+			continue
+		}
+		s.removeScripts(file.Name())
+		removed++
+	}
+	return removed, nil
+}
+
+// sweeps scripts until server is stopped
+func (s *server) runScriptsSweep(ctx context.Context) {
+	ticker := time.NewTicker(SCRIPTS_SWEEP_INTERVAL)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			removed, err := s.sweepScripts(ctx)
+			if err != nil {
+				zlog.Error().Err(err).Msg("Failed to sweep scripts")
+			} else if removed != 0 {
+				zlog.Info().Int("removed", removed).Msg("Swept scripts of removed routes")
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
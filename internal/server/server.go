@@ -2,101 +2,176 @@ package server
 
 import (
 	"context"
+	"encoding/json"
 	"mock-server/internal/configs"
+	"mock-server/internal/database"
 	"mock-server/internal/logger"
+	"mock-server/internal/metrics"
 	"mock-server/internal/util"
+	"net"
 	"net/http"
 	"time"
 
-	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	reuseport "github.com/kavu/go_reuseport"
 	zlog "github.com/rs/zerolog/log"
 )
 
+// server of process, state is kept in storage of process
 var Server = &server{}
 
 const FS_ROOT_DIR = "coderun"
 const FS_DYN_HANDLE_DIR = "dyn_handle"
 const FS_ESB_DIR = "mapper"
+const FS_FILES_DIR = "files"
 
 type server struct {
 	server_instance *http.Server
 	router          *gin.Engine
 	fs              *util.FileStorage
+	wsHub           *wsHub
+	adminPaths      map[string]bool // registered admin api paths
+	rateLimiter     *rateLimiter
+	corsHandlers    *corsHandlers
+	addr            string // actual listen address, differs from config for port 0
+	openapiDoc      []byte // admin api description, built after all routes are registered
+
+	// storage and file storage root of server, storage of process
+	// and its file storage are used if not set
+	storage database.Storage
+	fsRoot  string
+	// components checked by health endpoints, taken from config of process if not set
+	components *configs.ComponentsConfig
+
+	// parent of all request contexts, cancelled on stop to interrupt long-lived streams
+	baseCtx    context.Context
+	cancelBase context.CancelFunc
+}
+
+// server with state of its own, so servers embedded in one process do not share routes
+func New(storage database.Storage, fsRoot string, components configs.ComponentsConfig) *server {
+	return &server{storage: storage, fsRoot: fsRoot, components: &components}
 }
 
 func (s *server) Init(cfg *configs.ServerConfig) {
 	{
-		fs, err := util.NewFileStorageDriver(FS_ROOT_DIR)
+		var fs *util.FileStorage
+		var err error
+		if s.fsRoot != "" {
+			fs, err = util.NewFileStorageDriverAt(s.fsRoot, FS_ROOT_DIR)
+		} else {
+			fs, err = util.NewFileStorageDriver(FS_ROOT_DIR)
+		}
 		if err != nil {
 			panic(err)
 		}
 		s.fs = fs
 	}
 
+	s.wsHub = newWsHub()
+	s.rateLimiter = newRateLimiter()
+	s.corsHandlers = newCorsHandlers()
+	s.baseCtx, s.cancelBase = context.WithCancel(context.Background())
+	if s.storage != nil {
+		s.baseCtx = database.WithStorage(s.baseCtx, s.storage)
+	}
+
+	mode := gin.DebugMode
 	if cfg.DeployProduction {
-		gin.SetMode(gin.ReleaseMode)
-	} else {
-		gin.SetMode(gin.DebugMode)
+		mode = gin.ReleaseMode
+	}
+	// mode is process-wide, it is not set again by servers embedded in one process
+	if gin.Mode() != mode {
+		gin.SetMode(mode)
 	}
 
 	s.router = gin.New()
+	// storage of server and database session of batch operation are passed
+	// in request context to handlers which use gin context as context
+	s.router.ContextWithFallback = true
 
 	s.router.Use(logger.GinLogger()) // use custom logger (zerolog)
 	s.router.Use(gin.Recovery())     // recovery from all panics
-	s.router.Use(cors.Default())     // needs when routing development-mode frontend app
+	s.router.Use(s.adminCorsMiddleware(cfg.AdminCors))
+
+	s.initMainRoutes(cfg)
 
-	s.initMainRoutes()
+	s.adminPaths = make(map[string]bool)
+	for _, route := range s.router.Routes() {
+		s.adminPaths[route.Path] = true
+	}
+
+	openapiDoc, err := json.Marshal(buildOpenApiDocument(s.router.Routes()))
+	if err != nil {
+		panic(err)
+	}
+	s.openapiDoc = openapiDoc
 
 	s.server_instance = &http.Server{
 		Addr:         cfg.Addr,
 		Handler:      s.router,
 		ReadTimeout:  cfg.AcceptTimeout,
 		WriteTimeout: cfg.ResponseTimeout,
+		BaseContext:  func(net.Listener) context.Context { return s.baseCtx },
+		ConnContext:  withConn,
 	}
 }
 
 func (s *server) Start() {
-	ch := make(chan interface{})
+	zlog.Info().Msg("starting server")
 
-	go func() {
-		zlog.Info().Msg("starting server")
-
-		ch <- struct{}{}
-
-		ln, err := reuseport.Listen("tcp", s.server_instance.Addr)
-		if err != nil {
-			zlog.Error().Err(err).Msg("Failed to create listener")
-			panic(err)
-		}
+	// listen before return, so server accepts connections right after start
+	ln, err := reuseport.Listen("tcp", s.server_instance.Addr)
+	if err != nil {
+		zlog.Error().Err(err).Msg("Failed to create listener")
+		panic(err)
+	}
+	s.addr = ln.Addr().String()
 
-		zlog.Info().
-			Str("addr", ln.Addr().String()).
-			Msg("Server listens")
+	zlog.Info().
+		Str("addr", s.addr).
+		Msg("Server listens")
 
+	go func() {
 		if err := s.server_instance.Serve(ln); err != nil && err != http.ErrServerClosed {
 			zlog.Error().Err(err).Msg("failure while server working")
 			panic(err)
 		}
 	}()
+	// scripts of routes removed by ttl index are not removed by route api
+	go s.runScriptsSweep(s.baseCtx)
+}
 
-	<-ch
-
-	// wait until server start listening
-	time.Sleep(1 * time.Second)
+// address server listens on, available after start
+func (s *server) Addr() string {
+	return s.addr
 }
 
 func (s *server) Stop() {
 	zlog.Info().Msg("stopping server with timeout 5 seconds")
+
+	// hijacked connections are not tracked by http server
+	s.wsHub.closeAll()
+	s.cancelBase()
+
 	timeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	if err := s.server_instance.Shutdown(timeout); err != nil {
 		zlog.Fatal().Err(err).Msg("Server forced to shutdown")
 	}
+
+	// server of next test may listen on the same address, requests of
+	// its clients must not be sent over connections closed by shutdown
+	http.DefaultClient.CloseIdleConnections()
 }
 
-func (s *server) initMainRoutes() {
+func (s *server) initMainRoutes(cfg *configs.ServerConfig) {
+	// prometheus scrape endpoint, not protected like ping
+	s.router.GET("/metrics", gin.WrapH(metrics.Handler()))
+
+	// liveness and readiness probes
+	s.initHealthRoutes()
+
 	api := s.router.Group("api")
 
 	// just ping
@@ -106,20 +181,53 @@ func (s *server) initMainRoutes() {
 		})
 	}
 
-	// init routes (static, proxy, dynamic)
-	routesApi := api.Group("routes")
+	// openapi description of admin api
+	s.initOpenApi(api)
 
-	s.initRoutesApiStatic(routesApi)
-	s.initRoutesApiDynamic(routesApi)
-	s.initRoutesApiProxy(routesApi)
+	// everything except ping and api description requires authentication if configured,
+	// v2 serves the same handlers with unified response envelope
+	auth := newAuthMiddleware(cfg.Auth)
+	s.initAdminApi(api.Group("", auth))
+	s.initAdminApi(api.Group("v2", apiV2Middleware(), auth))
 
 	// route all query to handle dynamically
 	// created user mock endpoints
 	s.initNoRoute()
+}
+
+func (s *server) initAdminApi(admin *gin.RouterGroup) {
+	// changes of routes, pools, esb records and namespaces are recorded
+	admin.Use(s.auditMiddleware(admin.BasePath()))
+
+	// init routes (static, proxy, dynamic, graphql, websocket, stream, file)
+	routesApi := admin.Group("routes")
+
+	s.initRoutesApiList(routesApi)
+	s.initRoutesApiStatic(routesApi)
+	s.initRoutesApiDynamic(routesApi)
+	s.initRoutesApiProxy(routesApi)
+	s.initRoutesApiGraphQL(routesApi)
+	s.initRoutesApiWebSocket(routesApi)
+	s.initRoutesApiStream(routesApi)
+	s.initRoutesApiFile(routesApi)
+	s.initRoutesApiPolicy(routesApi)
+
+	// init namespaces (policies shared by mock routes)
+	s.initNamespacesApi(admin)
 
 	// init brokers (message pools, task scheduling and ESB)
-	brokersApi := api.Group("brokers")
+	brokersApi := admin.Group("brokers")
 
 	s.initBrokersApiPool(brokersApi)
 	s.initBrokersApiEsb(brokersApi)
+
+	// init batch (several operations above applied all or nothing)
+	s.initBatchApi(admin)
+
+	// init chaos profiles (faults injected into mock requests and broker writes)
+	s.initChaosApi(admin)
+
+	// init audit log of changes
+	s.initAuditApi(admin)
+	s.initRevisionsApi(admin)
 }
@@ -1,36 +1,80 @@
 package server
 
 import (
+	"errors"
+	"fmt"
+	"mime"
 	"mock-server/internal/database"
 	"mock-server/internal/server/protocol"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	zlog "github.com/rs/zerolog/log"
 )
 
-// static routes with predefined response
-func (s *server) initRoutesApiStatic(routes *gin.RouterGroup) {
-	staticRoutesEndpoint := "/static"
-
-	routes.GET(staticRoutesEndpoint, func(c *gin.Context) {
-		zlog.Info().Msg("Get all routes static request")
-		endpoints, err := database.ListAllStaticEndpointPaths(c)
+func validateStaticEndpoint(endpoint *protocol.StaticEndpoint) error {
+	if err := validateRouteWindow(&endpoint.RouteWindow, time.Now()); err != nil {
+		return err
+	}
 
+	seen := make(map[string]bool)
+	for _, representation := range endpoint.Representations {
+		mediaType, _, err := mime.ParseMediaType(representation.ContentType)
 		if err != nil {
-			zlog.Error().Err(err).Msg("Failed to list all static endpoints paths")
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
+			return fmt.Errorf("invalid content type %s: %w", representation.ContentType, err)
+		}
+		if strings.Contains(mediaType, "*") {
+			return errors.New("content type of representation can't contain wildcards")
 		}
+		if seen[mediaType] {
+			return fmt.Errorf("duplicate representation of %s", mediaType)
+		}
+		seen[mediaType] = true
+	}
+	return nil
+}
 
-		c.JSON(http.StatusOK, gin.H{"endpoints": endpoints})
-	})
+func toDatabaseRepresentations(representations []protocol.Representation) []database.Representation {
+	if len(representations) == 0 {
+		return nil
+	}
+	converted := make([]database.Representation, len(representations))
+	for i, representation := range representations {
+		converted[i] = database.Representation{
+			ContentType: representation.ContentType,
+			Body:        representation.Body,
+		}
+	}
+	return converted
+}
+
+func toProtocolRepresentations(representations []database.Representation) []protocol.Representation {
+	if len(representations) == 0 {
+		return nil
+	}
+	converted := make([]protocol.Representation, len(representations))
+	for i, representation := range representations {
+		converted[i] = protocol.Representation{
+			ContentType: representation.ContentType,
+			Body:        representation.Body,
+		}
+	}
+	return converted
+}
+
+// static routes with predefined response
+func (s *server) initRoutesApiStatic(routes *gin.RouterGroup) {
+	staticRoutesEndpoint := "/static"
+
+	routes.GET(staticRoutesEndpoint, listRoutePathsHandler(database.STATIC_ENDPOINT_TYPE))
 
 	routes.GET(staticRoutesEndpoint+"/expected_response", func(c *gin.Context) {
 		path := c.Query("path")
 		if path == "" {
 			zlog.Error().Msg("Path param not specified")
-			c.JSON(http.StatusBadRequest, gin.H{"error": "specify path param"})
+			apiError(c, http.StatusBadRequest, nil, "specify path param")
 			return
 		}
 
@@ -42,27 +86,71 @@ func (s *server) initRoutesApiStatic(routes *gin.RouterGroup) {
 			zlog.Info().Str("expected response ", expectedResponse).Msg("Got url")
 		case database.ErrNoSuchPath, database.ErrBadRouteType:
 			zlog.Error().Msg("Request for unexisting static route")
-			c.JSON(http.StatusNotFound, gin.H{"error": "Received path was not created before"})
+			apiError(c, http.StatusNotFound, err, "Received path was not created before")
 			return
 		default:
 			zlog.Error().Err(err).Msg("Failed to query expected response")
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			apiError(c, http.StatusInternalServerError, err, err.Error())
 			return
 		}
 
 		c.JSON(http.StatusOK, expectedResponse)
 	})
 
+	routes.GET(staticRoutesEndpoint+"/config", func(c *gin.Context) {
+		path := c.Query("path")
+		if path == "" {
+			zlog.Error().Msg("Path param not specified")
+			apiError(c, http.StatusBadRequest, nil, "specify path param")
+			return
+		}
+
+		zlog.Info().Str("path", path).Msg("Received get config static request")
+
+		route, err := database.GetStaticEndpoint(c, path)
+		switch err {
+		case nil:
+			zlog.Info().Str("path", path).Msg("Got static route")
+		case database.ErrNoSuchPath, database.ErrBadRouteType:
+			zlog.Error().Msg("Request for unexisting static route")
+			apiError(c, http.StatusNotFound, err, "Received path was not created before")
+			return
+		default:
+			zlog.Error().Err(err).Msg("Failed to query static route")
+			apiError(c, http.StatusInternalServerError, err, err.Error())
+			return
+		}
+
+		c.JSON(http.StatusOK, protocol.StaticEndpoint{
+			Path:             route.Path,
+			ExpectedResponse: route.Response,
+			Representations:  toProtocolRepresentations(route.Representations),
+			RouteWindow:      toProtocolRouteWindow(&route.ActivityWindow),
+		})
+	})
+
 	routes.POST(staticRoutesEndpoint, func(c *gin.Context) {
 		var staticEndpoint protocol.StaticEndpoint
 		if err := c.Bind(&staticEndpoint); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			apiError(c, http.StatusBadRequest, err, err.Error())
 			return
 		}
 
 		zlog.Info().Str("path", staticEndpoint.Path).Msg("Received create static request")
 
-		err := database.AddStaticEndpoint(c, staticEndpoint.Path, staticEndpoint.ExpectedResponse)
+		if err := validateStaticEndpoint(&staticEndpoint); err != nil {
+			zlog.Error().Err(err).Msg("Invalid static endpoint")
+			apiError(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
+
+		err := database.AddStaticEndpoint(
+			c,
+			staticEndpoint.Path,
+			staticEndpoint.ExpectedResponse,
+			routeWindowOption(&staticEndpoint.RouteWindow),
+			database.WithRepresentations(toDatabaseRepresentations(staticEndpoint.Representations)),
+		)
 
 		switch err {
 		case nil:
@@ -70,33 +158,45 @@ func (s *server) initRoutesApiStatic(routes *gin.RouterGroup) {
 			c.JSON(http.StatusOK, "Static endpoint successfully added!")
 		case database.ErrDuplicateKey:
 			zlog.Error().Str("path", staticEndpoint.Path).Msg("Endpoint with this path already exists")
-			c.JSON(http.StatusConflict, gin.H{"error": "The same endpoint already exists"})
+			apiError(c, http.StatusConflict, err, "The same endpoint already exists")
 		default:
 			zlog.Error().Err(err).Msg("Failed to add static endpoint")
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			apiError(c, http.StatusInternalServerError, err, err.Error())
 		}
 	})
 
 	routes.PUT(staticRoutesEndpoint, func(c *gin.Context) {
 		var staticEndpoint protocol.StaticEndpoint
 		if err := c.Bind(&staticEndpoint); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			apiError(c, http.StatusBadRequest, err, err.Error())
 			return
 		}
 
 		zlog.Info().Str("path", staticEndpoint.Path).Msg("Received update static request")
 
-		err := database.UpdateStaticEndpoint(c, staticEndpoint.Path, staticEndpoint.ExpectedResponse)
+		if err := validateStaticEndpoint(&staticEndpoint); err != nil {
+			zlog.Error().Err(err).Msg("Invalid static endpoint")
+			apiError(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
+
+		err := database.UpdateStaticEndpoint(
+			c,
+			staticEndpoint.Path,
+			staticEndpoint.ExpectedResponse,
+			routeWindowOption(&staticEndpoint.RouteWindow),
+			database.WithRepresentations(toDatabaseRepresentations(staticEndpoint.Representations)),
+		)
 		switch err {
 		case nil:
 			zlog.Info().Str("path", staticEndpoint.Path).Msg("Static endpoint updated")
 			c.JSON(http.StatusNoContent, "Static endpoint successfully updated!")
 		case database.ErrNoSuchPath:
 			zlog.Error().Msg("Update on unexisting path")
-			c.JSON(http.StatusNotFound, gin.H{"error": "Received path was not created before"})
+			apiError(c, http.StatusNotFound, err, "Received path was not created before")
 		default:
 			zlog.Error().Err(err).Msg("Failed to add static endpoint")
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			apiError(c, http.StatusInternalServerError, err, err.Error())
 		}
 	})
 
@@ -104,7 +204,7 @@ func (s *server) initRoutesApiStatic(routes *gin.RouterGroup) {
 		path := c.Query("path")
 		if path == "" {
 			zlog.Error().Msg("Path param not specified")
-			c.JSON(http.StatusBadRequest, gin.H{"error": "specify path param"})
+			apiError(c, http.StatusBadRequest, nil, "specify path param")
 			return
 		}
 
@@ -118,10 +218,10 @@ func (s *server) initRoutesApiStatic(routes *gin.RouterGroup) {
 			c.JSON(http.StatusNoContent, "Static endpoint successfully removed!")
 		case database.ErrNoSuchPath:
 			zlog.Error().Msg("Delete on unexisting path")
-			c.JSON(http.StatusNotFound, gin.H{"error": "Received path was not created before"})
+			apiError(c, http.StatusNotFound, err, "Received path was not created before")
 		default:
 			zlog.Error().Err(err).Msg("Failed to remove static endpoint")
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			apiError(c, http.StatusInternalServerError, err, err.Error())
 		}
 	})
 }
@@ -0,0 +1,131 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mock-server/internal/database"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	zlog "github.com/rs/zerolog/log"
+)
+
+const defaultChunkedContentType = "application/octet-stream"
+
+func writeSSEEvent(w io.Writer, event *database.StreamEvent) error {
+	var sb strings.Builder
+	if event.Id != "" {
+		fmt.Fprintf(&sb, "id: %s\n", event.Id)
+	}
+	if event.Event != "" {
+		fmt.Fprintf(&sb, "event: %s\n", event.Event)
+	}
+	// multiline data is split into several data fields
+	for _, line := range strings.Split(event.Data, "\n") {
+		fmt.Fprintf(&sb, "data: %s\n", line)
+	}
+	sb.WriteString("\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func writeStreamEvent(w io.Writer, mode string, event *database.StreamEvent) error {
+	switch mode {
+	case database.STREAM_MODE_SSE:
+		return writeSSEEvent(w, event)
+	case database.STREAM_MODE_CHUNKED:
+		_, err := io.WriteString(w, event.Data)
+		return err
+	default:
+		return fmt.Errorf("unknown stream mode: %s", mode)
+	}
+}
+
+var errStreamClientGone = errors.New("client disconnected")
+
+type connContextKey struct{}
+
+// keeps connection in request context, so handlers can adjust its deadlines
+func withConn(ctx context.Context, conn net.Conn) context.Context {
+	return context.WithValue(ctx, connContextKey{}, conn)
+}
+
+// lifts write timeout of the server for response streamed until client leaves,
+// deadline is set again by the server when the next request is read
+func clearWriteDeadline(c *gin.Context) {
+	conn, ok := c.Request.Context().Value(connContextKey{}).(net.Conn)
+	if !ok {
+		return
+	}
+	if err := conn.SetWriteDeadline(time.Time{}); err != nil {
+		zlog.Warn().Err(err).Msg("Failed to clear write deadline")
+	}
+}
+
+func (s *server) handleStreamRouteRequest(c *gin.Context, route *database.Route) {
+	switch route.StreamMode {
+	case database.STREAM_MODE_SSE:
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+	case database.STREAM_MODE_CHUNKED:
+		contentType := route.ContentType
+		if contentType == "" {
+			contentType = defaultChunkedContentType
+		}
+		c.Header("Content-Type", contentType)
+	}
+	// disables response buffering on reverse proxies (nginx)
+	c.Header("X-Accel-Buffering", "no")
+
+	// looped streams and long delays outlive response timeout
+	clearWriteDeadline(c)
+
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	ctx := c.Request.Context()
+
+	sendEvent := func(event *database.StreamEvent) error {
+		if event.DelayMs > 0 {
+			timer := time.NewTimer(time.Duration(event.DelayMs) * time.Millisecond)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return errStreamClientGone
+			case <-timer.C:
+			}
+		} else if ctx.Err() != nil {
+			return errStreamClientGone
+		}
+
+		if err := writeStreamEvent(c.Writer, route.StreamMode, event); err != nil {
+			return err
+		}
+		c.Writer.Flush()
+		return nil
+	}
+
+	zlog.Info().Str("path", route.Path).Str("mode", route.StreamMode).Msg("Stream started")
+
+	for {
+		for i := range route.Events {
+			if err := sendEvent(&route.Events[i]); err != nil {
+				zlog.Info().Err(err).Str("path", route.Path).Msg("Stream interrupted")
+				return
+			}
+		}
+
+		if !route.Loop {
+			break
+		}
+	}
+
+	zlog.Info().Str("path", route.Path).Msg("Stream finished")
+}
@@ -0,0 +1,199 @@
+package server
+
+import (
+	"errors"
+	"mock-server/internal/database"
+	"mock-server/internal/server/protocol"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	zlog "github.com/rs/zerolog/log"
+)
+
+func validateStreamEndpoint(endpoint *protocol.StreamEndpoint) error {
+	if err := validateRouteWindow(&endpoint.RouteWindow, time.Now()); err != nil {
+		return err
+	}
+
+	if endpoint.ContentType != "" && endpoint.Mode != database.STREAM_MODE_CHUNKED {
+		return errors.New("content type can be specified only for chunked streams")
+	}
+
+	if endpoint.Loop {
+		// looped stream without delays would flood the client
+		var totalDelay int64
+		for _, event := range endpoint.Events {
+			totalDelay += event.DelayMs
+		}
+		if totalDelay == 0 {
+			return errors.New("looped stream must have at least one event with delay")
+		}
+	}
+
+	return nil
+}
+
+func toDatabaseStreamEvents(events []protocol.StreamEvent) []database.StreamEvent {
+	converted := make([]database.StreamEvent, len(events))
+	for i, event := range events {
+		converted[i] = database.StreamEvent{
+			Event:   event.Event,
+			Data:    event.Data,
+			Id:      event.Id,
+			DelayMs: event.DelayMs,
+		}
+	}
+	return converted
+}
+
+func toProtocolStreamEvents(events []database.StreamEvent) []protocol.StreamEvent {
+	converted := make([]protocol.StreamEvent, len(events))
+	for i, event := range events {
+		converted[i] = protocol.StreamEvent{
+			Event:   event.Event,
+			Data:    event.Data,
+			Id:      event.Id,
+			DelayMs: event.DelayMs,
+		}
+	}
+	return converted
+}
+
+// stream routes (server-sent events and chunked responses)
+func (s *server) initRoutesApiStream(routes *gin.RouterGroup) {
+	streamRoutesEndpoint := "/stream"
+
+	routes.GET(streamRoutesEndpoint, listRoutePathsHandler(database.STREAM_ENDPOINT_TYPE))
+
+	routes.GET(streamRoutesEndpoint+"/config", func(c *gin.Context) {
+		path := c.Query("path")
+		if path == "" {
+			zlog.Error().Msg("Path param not specified")
+			apiError(c, http.StatusBadRequest, nil, "specify path param")
+			return
+		}
+
+		zlog.Info().Str("path", path).Msg("Received get config stream request")
+
+		route, err := database.GetStreamEndpoint(c, path)
+		switch err {
+		case nil:
+			zlog.Info().Str("path", path).Msg("Got stream route")
+		case database.ErrNoSuchPath, database.ErrBadRouteType:
+			zlog.Error().Msg("Request for unexisting stream route")
+			apiError(c, http.StatusNotFound, err, "Received path was not created before")
+			return
+		default:
+			zlog.Error().Err(err).Msg("Failed to query stream route")
+			apiError(c, http.StatusInternalServerError, err, err.Error())
+			return
+		}
+
+		c.JSON(http.StatusOK, protocol.StreamEndpoint{
+			Path:        route.Path,
+			Mode:        route.StreamMode,
+			Events:      toProtocolStreamEvents(route.Events),
+			Loop:        route.Loop,
+			ContentType: route.ContentType,
+			RouteWindow: toProtocolRouteWindow(&route.ActivityWindow),
+		})
+	})
+
+	routes.POST(streamRoutesEndpoint, func(c *gin.Context) {
+		var streamEndpoint protocol.StreamEndpoint
+		if err := c.Bind(&streamEndpoint); err != nil {
+			zlog.Error().Err(err).Msg("Failed to bind request")
+			apiError(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
+
+		zlog.Info().Str("path", streamEndpoint.Path).Msg("Received create stream request")
+
+		if err := validateStreamEndpoint(&streamEndpoint); err != nil {
+			zlog.Error().Err(err).Msg("Invalid stream endpoint")
+			apiError(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
+
+		err := database.AddStreamEndpoint(
+			c,
+			streamEndpoint.Path,
+			streamEndpoint.Mode,
+			toDatabaseStreamEvents(streamEndpoint.Events),
+			streamEndpoint.Loop,
+			streamEndpoint.ContentType,
+			routeWindowOption(&streamEndpoint.RouteWindow),
+		)
+
+		switch err {
+		case nil:
+			zlog.Info().Str("path", streamEndpoint.Path).Msg("Stream endpoint created")
+			c.JSON(http.StatusOK, "Stream endpoint successfully added!")
+		case database.ErrDuplicateKey:
+			zlog.Error().Str("path", streamEndpoint.Path).Msg("Endpoint with this path already exists")
+			apiError(c, http.StatusConflict, err, "The same endpoint already exists")
+		default:
+			zlog.Error().Err(err).Msg("Failed to add stream endpoint")
+			apiError(c, http.StatusInternalServerError, err, err.Error())
+		}
+	})
+
+	routes.PUT(streamRoutesEndpoint, func(c *gin.Context) {
+		var streamEndpoint protocol.StreamEndpoint
+		if err := c.Bind(&streamEndpoint); err != nil {
+			zlog.Error().Err(err).Msg("Failed to bind request")
+			apiError(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
+
+		zlog.Info().Str("path", streamEndpoint.Path).Msg("Received update stream request")
+
+		if err := validateStreamEndpoint(&streamEndpoint); err != nil {
+			zlog.Error().Err(err).Msg("Invalid stream endpoint")
+			apiError(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
+
+		err := database.UpdateStreamEndpoint(
+			c,
+			streamEndpoint.Path,
+			streamEndpoint.Mode,
+			toDatabaseStreamEvents(streamEndpoint.Events),
+			streamEndpoint.Loop,
+			streamEndpoint.ContentType,
+			routeWindowOption(&streamEndpoint.RouteWindow),
+		)
+		switch err {
+		case nil:
+			zlog.Info().Str("path", streamEndpoint.Path).Msg("Stream endpoint updated")
+			c.JSON(http.StatusNoContent, "Stream endpoint successfully updated!")
+		case database.ErrNoSuchPath:
+			zlog.Error().Msg("Update on unexisting path")
+			apiError(c, http.StatusNotFound, err, "Received path was not created before")
+		default:
+			zlog.Error().Err(err).Msg("Failed to update stream endpoint")
+			apiError(c, http.StatusInternalServerError, err, err.Error())
+		}
+	})
+
+	routes.DELETE(streamRoutesEndpoint, func(c *gin.Context) {
+		path := c.Query("path")
+		if path == "" {
+			zlog.Error().Msg("Path param not specified")
+			apiError(c, http.StatusBadRequest, nil, "specify path param")
+			return
+		}
+
+		zlog.Info().Str("path", path).Msg("Received delete stream request")
+
+		if err := database.RemoveStreamEndpoint(c, path); err != nil {
+			zlog.Error().Err(err).Msg("Failed to remove stream endpoint")
+			apiError(c, http.StatusInternalServerError, err, err.Error())
+			return
+		}
+
+		zlog.Info().Str("path", path).Msg("Stream endpoint removed")
+		c.JSON(http.StatusNoContent, "Stream endpoint successfully removed!")
+	})
+}
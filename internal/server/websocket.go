@@ -0,0 +1,223 @@
+package server
+
+import (
+	"encoding/json"
+	"mock-server/internal/coderun"
+	"mock-server/internal/database"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	zlog "github.com/rs/zerolog/log"
+)
+
+var wsUpgrader = websocket.Upgrader{
+	// mocks are requested from arbitrary origins
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+const wsCloseTimeout = time.Second
+
+type wsClient struct {
+	conn *websocket.Conn
+	mtx  sync.Mutex // connection supports only one concurrent writer
+}
+
+func (cl *wsClient) send(message string) error {
+	cl.mtx.Lock()
+	defer cl.mtx.Unlock()
+	return cl.conn.WriteMessage(websocket.TextMessage, []byte(message))
+}
+
+func (cl *wsClient) close(code int, reason string) {
+	cl.conn.WriteControl( // nolint:errcheck
+		websocket.CloseMessage,
+		websocket.FormatCloseMessage(code, reason),
+		time.Now().Add(wsCloseTimeout),
+	)
+	cl.conn.Close()
+}
+
+// connected clients grouped by mock path
+type wsHub struct {
+	clients map[string]map[*wsClient]struct{}
+	mtx     sync.Mutex
+}
+
+func newWsHub() *wsHub {
+	return &wsHub{
+		clients: make(map[string]map[*wsClient]struct{}),
+	}
+}
+
+func (h *wsHub) register(path string, cl *wsClient) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	if _, ok := h.clients[path]; !ok {
+		h.clients[path] = make(map[*wsClient]struct{})
+	}
+	h.clients[path][cl] = struct{}{}
+}
+
+func (h *wsHub) unregister(path string, cl *wsClient) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	delete(h.clients[path], cl)
+	if len(h.clients[path]) == 0 {
+		delete(h.clients, path)
+	}
+}
+
+func (h *wsHub) pathClients(path string) []*wsClient {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	clients := make([]*wsClient, 0, len(h.clients[path]))
+	for cl := range h.clients[path] {
+		clients = append(clients, cl)
+	}
+	return clients
+}
+
+// returns number of clients received the message
+func (h *wsHub) broadcast(path string, message string) int {
+	delivered := 0
+	for _, cl := range h.pathClients(path) {
+		if err := cl.send(message); err != nil {
+			zlog.Warn().Err(err).Str("path", path).Msg("Failed to deliver broadcast message")
+			continue
+		}
+		delivered += 1
+	}
+	return delivered
+}
+
+func (h *wsHub) closeAll() {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	for _, clients := range h.clients {
+		for cl := range clients {
+			cl.close(websocket.CloseGoingAway, "server is shutting down")
+		}
+	}
+}
+
+func matchWebSocketReply(replies []database.WebSocketReply, frame []byte) *database.WebSocketReply {
+	for i := range replies {
+		reply := &replies[i]
+		if !reply.Regexp {
+			if reply.Match == string(frame) {
+				return reply
+			}
+			continue
+		}
+
+		re, err := regexp.Compile(reply.Match)
+		if err != nil {
+			zlog.Error().Err(err).Str("match", reply.Match).Msg("Failed to compile stored regexp")
+			continue
+		}
+		if re.Match(frame) {
+			return reply
+		}
+	}
+	return nil
+}
+
+func (s *server) handleWebSocketFrame(c *gin.Context, cl *wsClient, route *database.Route, frame []byte) error {
+	if reply := matchWebSocketReply(route.Replies, frame); reply != nil {
+		return cl.send(reply.Response)
+	}
+
+	if route.ScriptName == "" {
+		zlog.Debug().Str("path", route.Path).Msg("No reply for frame")
+		return nil
+	}
+
+	// non json frames are passed to handler as a string
+	body := frame
+	if !json.Valid(frame) {
+		var err error
+		if body, err = json.Marshal(string(frame)); err != nil {
+			return err
+		}
+	}
+
+	output, err := runDynHandleScript(c.Request.Context(), route.ScriptName, c.Request.Header, body)
+	switch err {
+	case nil:
+		return cl.send(string(output))
+	case coderun.ErrCodeRunFailed:
+		zlog.Warn().Str("output", string(output)).Msg("Failed to run script")
+	default:
+		zlog.Error().Err(err).Msg("Worker failed")
+		output = []byte(err.Error())
+	}
+
+	errMessage, err := json.Marshal(gin.H{"error": string(output)})
+	if err != nil {
+		return err
+	}
+	return cl.send(string(errMessage))
+}
+
+func (s *server) handleWebSocketRouteRequest(c *gin.Context, route *database.Route) {
+	if !websocket.IsWebSocketUpgrade(c.Request) {
+		zlog.Info().Str("path", route.Path).Msg("Plain http request on websocket route")
+		c.JSON(http.StatusUpgradeRequired, gin.H{"error": "websocket upgrade required"})
+		return
+	}
+
+	conn, err := wsUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// upgrader has already replied with http error
+		zlog.Error().Err(err).Msg("Failed to upgrade connection")
+		return
+	}
+
+	cl := &wsClient{conn: conn}
+	s.wsHub.register(route.Path, cl)
+	defer func() {
+		s.wsHub.unregister(route.Path, cl)
+		conn.Close()
+	}()
+
+	zlog.Info().Str("path", route.Path).Msg("WebSocket client connected")
+
+	for _, message := range route.OnConnect {
+		if err := cl.send(message); err != nil {
+			zlog.Warn().Err(err).Msg("Failed to send on connect message")
+			return
+		}
+	}
+
+	for {
+		_, frame, err := conn.ReadMessage()
+		if err != nil {
+			zlog.Info().Err(err).Str("path", route.Path).Msg("WebSocket client disconnected")
+			return
+		}
+
+		// route is requested on every frame so updates apply to connected clients
+		current, err := database.GetWebSocketEndpoint(c, route.Path)
+		if err == nil && !current.IsActive(time.Now()) {
+			err = database.ErrNoSuchPath
+		}
+		if err != nil {
+			zlog.Info().Err(err).Str("path", route.Path).Msg("WebSocket route is no longer available")
+			cl.close(websocket.CloseGoingAway, "route removed")
+			return
+		}
+
+		if err := s.handleWebSocketFrame(c, cl, &current, frame); err != nil {
+			zlog.Warn().Err(err).Str("path", route.Path).Msg("Failed to reply on frame")
+			return
+		}
+	}
+}
@@ -0,0 +1,296 @@
+package server
+
+import (
+	"fmt"
+	"mock-server/internal/database"
+	"mock-server/internal/server/protocol"
+	"mock-server/internal/util"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	zlog "github.com/rs/zerolog/log"
+)
+
+func validateWebSocketEndpoint(endpoint *protocol.WebSocketEndpoint) error {
+	if err := validateRouteWindow(&endpoint.RouteWindow, time.Now()); err != nil {
+		return err
+	}
+
+	for _, reply := range endpoint.Replies {
+		if !reply.Regexp {
+			continue
+		}
+		if _, err := regexp.Compile(reply.Match); err != nil {
+			return fmt.Errorf("invalid reply regexp %s: %s", reply.Match, err.Error())
+		}
+	}
+	return nil
+}
+
+func toDatabaseWebSocketReplies(replies []protocol.WebSocketReply) []database.WebSocketReply {
+	converted := make([]database.WebSocketReply, len(replies))
+	for i, reply := range replies {
+		converted[i] = database.WebSocketReply{
+			Match:    reply.Match,
+			Regexp:   reply.Regexp,
+			Response: reply.Response,
+		}
+	}
+	return converted
+}
+
+func toProtocolWebSocketReplies(replies []database.WebSocketReply) []protocol.WebSocketReply {
+	converted := make([]protocol.WebSocketReply, len(replies))
+	for i, reply := range replies {
+		converted[i] = protocol.WebSocketReply{
+			Match:    reply.Match,
+			Regexp:   reply.Regexp,
+			Response: reply.Response,
+		}
+	}
+	return converted
+}
+
+// stores per frame handler if it is specified, returns script name
+func (s *server) storeWebSocketHandler(code string) (string, error) {
+	if code == "" {
+		return "", nil
+	}
+
+	scriptName := util.GenUniqueFilename("py")
+	zlog.Info().Str("filename", scriptName).Msg("Generated script name")
+
+	if err := s.fs.Write(FS_DYN_HANDLE_DIR, scriptName, util.WrapCodeForDynHandle(code)); err != nil {
+		return "", err
+	}
+	return scriptName, nil
+}
+
+// websocket routes (scripted messages, canned and dynamic replies on frames)
+func (s *server) initRoutesApiWebSocket(routes *gin.RouterGroup) {
+	websocketRoutesEndpoint := "/websocket"
+
+	routes.GET(websocketRoutesEndpoint, listRoutePathsHandler(database.WEBSOCKET_ENDPOINT_TYPE))
+
+	routes.GET(websocketRoutesEndpoint+"/config", func(c *gin.Context) {
+		path := c.Query("path")
+		if path == "" {
+			zlog.Error().Msg("Path param not specified")
+			apiError(c, http.StatusBadRequest, nil, "specify path param")
+			return
+		}
+
+		zlog.Info().Str("path", path).Msg("Received get config websocket request")
+
+		route, err := database.GetWebSocketEndpoint(c, path)
+		switch err {
+		case nil:
+			zlog.Info().Str("path", path).Msg("Got websocket route")
+		case database.ErrNoSuchPath, database.ErrBadRouteType:
+			zlog.Error().Msg("Request for unexisting websocket route")
+			apiError(c, http.StatusNotFound, err, "Received path was not created before")
+			return
+		default:
+			zlog.Error().Err(err).Msg("Failed to query websocket route")
+			apiError(c, http.StatusInternalServerError, err, err.Error())
+			return
+		}
+
+		endpoint := protocol.WebSocketEndpoint{
+			Path:        route.Path,
+			OnConnect:   route.OnConnect,
+			Replies:     toProtocolWebSocketReplies(route.Replies),
+			RouteWindow: toProtocolRouteWindow(&route.ActivityWindow),
+		}
+
+		if route.ScriptName != "" {
+			code, err := s.fs.Read(FS_DYN_HANDLE_DIR, route.ScriptName)
+			if err != nil {
+				zlog.Error().Err(err).Str("script name", route.ScriptName).Msg("Failed to read script code")
+				apiError(c, http.StatusInternalServerError, err, err.Error())
+				return
+			}
+			endpoint.Code = util.UnwrapCodeForDynHandle(code)
+		}
+
+		c.JSON(http.StatusOK, endpoint)
+	})
+
+	routes.POST(websocketRoutesEndpoint, func(c *gin.Context) {
+		var websocketEndpoint protocol.WebSocketEndpoint
+		if err := c.Bind(&websocketEndpoint); err != nil {
+			zlog.Error().Err(err).Msg("Failed to bind request")
+			apiError(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
+
+		zlog.Info().Str("path", websocketEndpoint.Path).Msg("Received create websocket request")
+
+		if err := validateWebSocketEndpoint(&websocketEndpoint); err != nil {
+			zlog.Error().Err(err).Msg("Invalid websocket endpoint")
+			apiError(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
+
+		scriptName, err := s.storeWebSocketHandler(websocketEndpoint.Code)
+		if err != nil {
+			zlog.Error().Err(err).Msg("Failed to write code to file")
+			apiError(c, http.StatusInternalServerError, err, err.Error())
+			return
+		}
+
+		err = database.AddWebSocketEndpoint(
+			c,
+			websocketEndpoint.Path,
+			websocketEndpoint.OnConnect,
+			toDatabaseWebSocketReplies(websocketEndpoint.Replies),
+			scriptName,
+			routeWindowOption(&websocketEndpoint.RouteWindow),
+		)
+
+		if err != nil {
+			s.removeScripts(scriptName)
+		}
+
+		switch err {
+		case nil:
+			zlog.Info().Str("path", websocketEndpoint.Path).Msg("WebSocket endpoint created")
+			c.JSON(http.StatusOK, "WebSocket endpoint successfully added!")
+		case database.ErrDuplicateKey:
+			zlog.Error().Str("path", websocketEndpoint.Path).Msg("Endpoint with this path already exists")
+			apiError(c, http.StatusConflict, err, "The same endpoint already exists")
+		default:
+			zlog.Error().Err(err).Msg("Failed to add websocket endpoint")
+			apiError(c, http.StatusInternalServerError, err, err.Error())
+		}
+	})
+
+	routes.PUT(websocketRoutesEndpoint, func(c *gin.Context) {
+		var websocketEndpoint protocol.WebSocketEndpoint
+		if err := c.Bind(&websocketEndpoint); err != nil {
+			zlog.Error().Err(err).Msg("Failed to bind request")
+			apiError(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
+
+		zlog.Info().Str("path", websocketEndpoint.Path).Msg("Received update websocket request")
+
+		if err := validateWebSocketEndpoint(&websocketEndpoint); err != nil {
+			zlog.Error().Err(err).Msg("Invalid websocket endpoint")
+			apiError(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
+
+		replaced, err := database.GetWebSocketEndpoint(c, websocketEndpoint.Path)
+		switch err {
+		case nil:
+		case database.ErrNoSuchPath, database.ErrBadRouteType:
+			zlog.Error().Msg("Update on unexisting path")
+			apiError(c, http.StatusNotFound, err, "Received path was not created before")
+			return
+		default:
+			zlog.Error().Err(err).Msg("Failed to query websocket route")
+			apiError(c, http.StatusInternalServerError, err, err.Error())
+			return
+		}
+
+		scriptName, err := s.storeWebSocketHandler(websocketEndpoint.Code)
+		if err != nil {
+			zlog.Error().Err(err).Msg("Failed to write code to file")
+			apiError(c, http.StatusInternalServerError, err, err.Error())
+			return
+		}
+
+		err = database.UpdateWebSocketEndpoint(
+			c,
+			websocketEndpoint.Path,
+			websocketEndpoint.OnConnect,
+			toDatabaseWebSocketReplies(websocketEndpoint.Replies),
+			scriptName,
+			routeWindowOption(&websocketEndpoint.RouteWindow),
+		)
+		if err != nil {
+			s.removeScripts(scriptName)
+		} else {
+			s.removeScripts(replaced.ScriptName)
+		}
+
+		switch err {
+		case nil:
+			zlog.Info().Str("path", websocketEndpoint.Path).Msg("WebSocket endpoint updated")
+			c.JSON(http.StatusNoContent, "WebSocket endpoint successfully updated!")
+		case database.ErrNoSuchPath:
+			zlog.Error().Msg("Update on unexisting path")
+			apiError(c, http.StatusNotFound, err, "Received path was not created before")
+		default:
+			zlog.Error().Err(err).Msg("Failed to update websocket endpoint")
+			apiError(c, http.StatusInternalServerError, err, err.Error())
+		}
+	})
+
+	routes.DELETE(websocketRoutesEndpoint, func(c *gin.Context) {
+		path := c.Query("path")
+		if path == "" {
+			zlog.Error().Msg("Path param not specified")
+			apiError(c, http.StatusBadRequest, nil, "specify path param")
+			return
+		}
+
+		zlog.Info().Str("path", path).Msg("Received delete websocket request")
+
+		route, err := database.GetWebSocketEndpoint(c, path)
+		switch err {
+		case nil:
+		case database.ErrNoSuchPath, database.ErrBadRouteType:
+			zlog.Error().Msg("Delete on unexisting path")
+			apiError(c, http.StatusNotFound, err, "Received path was not created before")
+			return
+		default:
+			zlog.Error().Err(err).Msg("Failed to query websocket route")
+			apiError(c, http.StatusInternalServerError, err, err.Error())
+			return
+		}
+
+		if err := database.RemoveWebSocketEndpoint(c, path); err != nil {
+			zlog.Error().Err(err).Msg("Failed to remove websocket endpoint")
+			apiError(c, http.StatusInternalServerError, err, err.Error())
+			return
+		}
+		s.removeScripts(route.ScriptName)
+
+		zlog.Info().Str("path", path).Msg("WebSocket endpoint removed")
+		c.JSON(http.StatusNoContent, "WebSocket endpoint successfully removed!")
+	})
+
+	// push message to all clients connected to the mock path
+	routes.POST(websocketRoutesEndpoint+"/broadcast", func(c *gin.Context) {
+		var broadcast protocol.WebSocketBroadcast
+		if err := c.Bind(&broadcast); err != nil {
+			zlog.Error().Err(err).Msg("Failed to bind request")
+			apiError(c, http.StatusBadRequest, err, err.Error())
+			return
+		}
+
+		zlog.Info().Str("path", broadcast.Path).Msg("Received websocket broadcast request")
+
+		_, err := database.GetWebSocketEndpoint(c, broadcast.Path)
+		switch err {
+		case nil:
+		case database.ErrNoSuchPath, database.ErrBadRouteType:
+			zlog.Error().Msg("Broadcast on unexisting websocket route")
+			apiError(c, http.StatusNotFound, err, "Received path was not created before")
+			return
+		default:
+			zlog.Error().Err(err).Msg("Failed to query websocket route")
+			apiError(c, http.StatusInternalServerError, err, err.Error())
+			return
+		}
+
+		clients := s.wsHub.broadcast(broadcast.Path, broadcast.Message)
+
+		zlog.Info().Str("path", broadcast.Path).Int("clients", clients).Msg("Message broadcasted")
+		c.JSON(http.StatusOK, gin.H{"clients": clients})
+	})
+}
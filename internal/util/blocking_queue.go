@@ -65,6 +65,12 @@ func (q *BlockingQueue[T]) Close(clear bool) {
 	q.empty.Broadcast()
 }
 
+func (q *BlockingQueue[T]) Len() int {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+	return q.elems.Len()
+}
+
 func (q *BlockingQueue[T]) IsClosed() bool {
 	q.mtx.Lock()
 	defer q.mtx.Unlock()
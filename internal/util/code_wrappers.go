@@ -17,8 +17,10 @@ print(func(**args))`
 const INVOKE_ESB = `
 print(func(args["msgs"]))`
 
+// unwrapped code always ends with newline, trailing newline of wrapped code is dropped
+// so code read back and saved again does not grow
 func WrapCodeForDynHandle(code string) []byte {
-	return []byte(fmt.Sprintf("%s\n%s\n%s", LOAD_ARGS, code, INVOKE_DYN_HANDLE))
+	return []byte(fmt.Sprintf("%s\n%s\n%s", LOAD_ARGS, strings.TrimSuffix(code, "\n"), INVOKE_DYN_HANDLE))
 }
 
 func UnwrapCodeForDynHandle(code string) string {
@@ -28,7 +30,7 @@ func UnwrapCodeForDynHandle(code string) string {
 }
 
 func WrapCodeForEsb(code string) []byte {
-	return []byte(fmt.Sprintf("%s\n%s\n%s", LOAD_ARGS, code, INVOKE_ESB))
+	return []byte(fmt.Sprintf("%s\n%s\n%s", LOAD_ARGS, strings.TrimSuffix(code, "\n"), INVOKE_ESB))
 }
 
 func UnwrapCodeForEsb(code string) string {
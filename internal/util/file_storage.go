@@ -3,8 +3,10 @@ package util
 import (
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sync/atomic"
 	"time"
 )
 
@@ -16,6 +18,14 @@ const (
 	file_storage_dir_name = ".storage"
 )
 
+// overrides storage location inside project root if set,
+// coderun reads scripts from storage of process
+var file_storage_root_override string
+
+func SetFileStorageRoot(root string) {
+	file_storage_root_override = root
+}
+
 func createIfNotExists(path string) error {
 	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
 		if err := os.MkdirAll(path, os.ModePerm); err != nil {
@@ -25,24 +35,16 @@ func createIfNotExists(path string) error {
 	return nil
 }
 
-func createFileStorage() (*FileStorage, error) {
-	file_storage_root, err := FileStorageRoot()
-	if err != nil {
-		return nil, err
-	}
-
-	return &FileStorage{
-		prefix: file_storage_root,
-	}, nil
-}
-
 func FileStorageRoot() (string, error) {
-	root, err := GetProjectRoot()
-	if err != nil {
-		return "", err
+	storage_root := file_storage_root_override
+	if storage_root == "" {
+		root, err := GetProjectRoot()
+		if err != nil {
+			return "", err
+		}
+		storage_root = filepath.Join(root, file_storage_dir_name)
 	}
 
-	storage_root := filepath.Join(root, file_storage_dir_name)
 	if err := createIfNotExists(storage_root); err != nil {
 		return "", err
 	}
@@ -55,18 +57,24 @@ func FileStorageRoot() (string, error) {
 }
 
 func NewFileStorageDriver(prefix string) (*FileStorage, error) {
-	driver, err := createFileStorage()
+	file_storage_root, err := FileStorageRoot()
 	if err != nil {
 		return nil, err
 	}
 
-	root := filepath.Join(driver.prefix, prefix)
+	return NewFileStorageDriverAt(file_storage_root, prefix)
+}
+
+// driver of storage in given directory instead of storage of process
+func NewFileStorageDriverAt(storage_root string, prefix string) (*FileStorage, error) {
+	root := filepath.Join(storage_root, prefix)
 	if err := createIfNotExists(root); err != nil {
 		return nil, err
 	}
 
-	driver.prefix = root
-	return driver, nil
+	return &FileStorage{
+		prefix: root,
+	}, nil
 }
 
 func (fs *FileStorage) Read(prefix string, filename string) (string, error) {
@@ -106,6 +114,73 @@ func (fs *FileStorage) Write(prefix string, filename string, data []byte) error
 	return nil
 }
 
+// streams data into file, which appears under filename only when fully written
+func (fs *FileStorage) WriteFrom(prefix string, filename string, data io.Reader) (int64, error) {
+	folder := filepath.Join(fs.prefix, prefix)
+	if err := createIfNotExists(folder); err != nil {
+		return 0, err
+	}
+
+	tmp, err := os.CreateTemp(folder, filename+".*.tmp")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	written, err := io.Copy(tmp, data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return written, err
+	}
+
+	return written, os.Rename(tmp.Name(), filepath.Join(folder, filename))
+}
+
+// caller closes the file
+func (fs *FileStorage) Open(prefix string, filename string) (*os.File, error) {
+	return os.Open(filepath.Join(fs.prefix, prefix, filename))
+}
+
+// files under prefix, none if prefix was not written yet
+func (fs *FileStorage) List(prefix string) ([]os.FileInfo, error) {
+	entries, err := os.ReadDir(filepath.Join(fs.prefix, prefix))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]os.FileInfo, 0, len(entries))
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, info)
+		}
+	}
+	return files, nil
+}
+
+// removing missing file is not an error
+func (fs *FileStorage) Remove(prefix string, filename string) error {
+	err := os.Remove(filepath.Join(fs.prefix, prefix, filename))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+var filenameCounter uint64
+
+// counter keeps names unique when several scripts are created within one request
 func GenUniqueFilename(ext string) string {
-	return fmt.Sprintf("script_%s.%s", time.Now().Format("20060102150405"), ext)
+	return fmt.Sprintf("script_%s_%d.%s", time.Now().Format("20060102150405"), atomic.AddUint64(&filenameCounter, 1), ext)
 }
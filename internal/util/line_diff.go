@@ -0,0 +1,57 @@
+package util
+
+import "strings"
+
+// line by line diff of a to b by longest common subsequence,
+// kept lines are prefixed with "  ", removed with "- ", added with "+ "
+func DiffLines(a string, b string) []string {
+	var linesA, linesB []string
+	if a != "" {
+		linesA = strings.Split(a, "\n")
+	}
+	if b != "" {
+		linesB = strings.Split(b, "\n")
+	}
+
+	// lcs[i][j] is length of common subsequence of linesA[i:] and linesB[j:]
+	lcs := make([][]int, len(linesA)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(linesB)+1)
+	}
+	for i := len(linesA) - 1; i >= 0; i-- {
+		for j := len(linesB) - 1; j >= 0; j-- {
+			switch {
+			case linesA[i] == linesB[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	diff := make([]string, 0, len(linesA)+len(linesB))
+	i, j := 0, 0
+	for i < len(linesA) && j < len(linesB) {
+		switch {
+		case linesA[i] == linesB[j]:
+			diff = append(diff, "  "+linesA[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diff = append(diff, "- "+linesA[i])
+			i++
+		default:
+			diff = append(diff, "+ "+linesB[j])
+			j++
+		}
+	}
+	for ; i < len(linesA); i++ {
+		diff = append(diff, "- "+linesA[i])
+	}
+	for ; j < len(linesB); j++ {
+		diff = append(diff, "+ "+linesB[j])
+	}
+	return diff
+}
@@ -0,0 +1,60 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"time"
+)
+
+const AUDIT_ENDPOINT = "/api/audit"
+
+type AuditRecord struct {
+	Id        string          `json:"id"`
+	Timestamp time.Time       `json:"timestamp"`
+	Actor     string          `json:"actor,omitempty"`
+	IP        string          `json:"ip"`
+	Method    string          `json:"method"`
+	Path      string          `json:"path"`
+	Operation string          `json:"operation"`
+	Resource  string          `json:"resource"`
+	Key       string          `json:"key"`
+	Status    int             `json:"status,omitempty"`
+	Before    json.RawMessage `json:"before,omitempty"`
+	After     json.RawMessage `json:"after,omitempty"`
+}
+
+// zero fields do not filter
+type AuditFilter struct {
+	// route, pool, esb or namespace
+	Resource string
+	Key      string
+	Actor    string
+	Since    time.Time
+	Until    time.Time
+}
+
+func (f *AuditFilter) query() url.Values {
+	query := url.Values{}
+	if f.Resource != "" {
+		query.Set("resource", f.Resource)
+	}
+	if f.Key != "" {
+		query.Set("key", f.Key)
+	}
+	if f.Actor != "" {
+		query.Set("actor", f.Actor)
+	}
+	if !f.Since.IsZero() {
+		query.Set("since", f.Since.Format(time.RFC3339))
+	}
+	if !f.Until.IsZero() {
+		query.Set("until", f.Until.Format(time.RFC3339))
+	}
+	return query
+}
+
+// records in the order changes were made
+func (c *Client) ListAuditRecords(ctx context.Context, filter AuditFilter) ([]AuditRecord, error) {
+	return listAll[AuditRecord](ctx, c, AUDIT_ENDPOINT, filter.query(), "records")
+}
@@ -0,0 +1,89 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+const BATCH_ENDPOINT = "/api/batch"
+
+const (
+	BATCH_OP_CREATE = "create"
+	BATCH_OP_UPDATE = "update"
+	BATCH_OP_DELETE = "delete"
+
+	BATCH_KIND_STATIC    = "static"
+	BATCH_KIND_PROXY     = "proxy"
+	BATCH_KIND_DYNAMIC   = "dynamic"
+	BATCH_KIND_GRAPHQL   = "graphql"
+	BATCH_KIND_WEBSOCKET = "websocket"
+	BATCH_KIND_STREAM    = "stream"
+	BATCH_KIND_POOL      = "pool"
+	BATCH_KIND_ESB       = "esb"
+
+	BATCH_STATE_APPLIED     = "applied"
+	BATCH_STATE_ROLLED_BACK = "rolled_back"
+	BATCH_STATE_FAILED      = "failed"
+	BATCH_STATE_SKIPPED     = "skipped"
+)
+
+type BatchOperation struct {
+	Op   string `json:"op"`
+	Kind string `json:"kind"`
+	// path, pool name or esb in-pool name of deleted object
+	Key string `json:"key,omitempty"`
+	// create or update request of the kind, e.g. StaticEndpoint
+	Body interface{} `json:"body,omitempty"`
+}
+
+type BatchResult struct {
+	Index  int    `json:"index"`
+	Op     string `json:"op"`
+	Kind   string `json:"kind"`
+	Key    string `json:"key,omitempty"`
+	Status int    `json:"status,omitempty"`
+	State  string `json:"state"`
+	Error  string `json:"error,omitempty"`
+}
+
+type BatchResponse struct {
+	Applied     bool          `json:"applied"`
+	Transaction bool          `json:"transaction"`
+	Error       string        `json:"error,omitempty"`
+	Results     []BatchResult `json:"results"`
+}
+
+// failed batch, nothing of it is applied
+type BatchError struct {
+	*APIError
+	Results []BatchResult
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.APIError
+}
+
+// applies operations in order, all or nothing; error of failed batch
+// is *BatchError with result of every operation
+func (c *Client) Batch(ctx context.Context, operations []BatchOperation) (*BatchResponse, error) {
+	request := struct {
+		Operations []BatchOperation `json:"operations"`
+	}{operations}
+
+	var response BatchResponse
+	err := c.do(ctx, http.MethodPost, BATCH_ENDPOINT, nil, request, &response)
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		var failed BatchResponse
+		if json.Unmarshal(apiErr.body, &failed) == nil && failed.Results != nil {
+			return nil, &BatchError{APIError: apiErr, Results: failed.Results}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
@@ -0,0 +1,105 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+)
+
+const (
+	POOL_ENDPOINT = "/api/brokers/pool"
+	ESB_ENDPOINT  = "/api/brokers/esb"
+)
+
+// message pools
+
+func poolQuery(pool string) url.Values {
+	return url.Values{"pool": {pool}}
+}
+
+func (c *Client) ListPools(ctx context.Context) ([]MessagePool, error) {
+	return listAll[MessagePool](ctx, c, POOL_ENDPOINT, nil, "pools")
+}
+
+// config layout depends on the broker of pool
+func (c *Client) GetPoolConfig(ctx context.Context, pool string) (json.RawMessage, error) {
+	var config json.RawMessage
+	if err := c.do(ctx, http.MethodGet, POOL_ENDPOINT+"/config", poolQuery(pool), nil, &config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func (c *Client) CreatePool(ctx context.Context, pool MessagePool) error {
+	return c.do(ctx, http.MethodPost, POOL_ENDPOINT, nil, pool, nil)
+}
+
+func (c *Client) DeletePool(ctx context.Context, pool string) error {
+	return c.do(ctx, http.MethodDelete, POOL_ENDPOINT, poolQuery(pool), nil, nil)
+}
+
+// schedules write of messages into pool
+func (c *Client) PublishMessages(ctx context.Context, pool string, messages []string) error {
+	task := BrokerTask{PoolName: pool, Messages: messages}
+	return c.do(ctx, http.MethodPost, POOL_ENDPOINT+"/write", nil, task, nil)
+}
+
+// schedules read of messages from pool, they are available later with ReadMessages
+func (c *Client) ScheduleRead(ctx context.Context, pool string) error {
+	return c.do(ctx, http.MethodPost, POOL_ENDPOINT+"/read", poolQuery(pool), nil, nil)
+}
+
+func (c *Client) poolMessages(ctx context.Context, endpoint string, pool string) ([]string, error) {
+	return listAll[string](ctx, c, endpoint, poolQuery(pool), "messages")
+}
+
+// messages read from pool so far
+func (c *Client) ReadMessages(ctx context.Context, pool string) ([]string, error) {
+	return c.poolMessages(ctx, POOL_ENDPOINT+"/read", pool)
+}
+
+// messages written to pool so far
+func (c *Client) WrittenMessages(ctx context.Context, pool string) ([]string, error) {
+	return c.poolMessages(ctx, POOL_ENDPOINT+"/write", pool)
+}
+
+// esb records
+
+func poolInQuery(poolIn string) url.Values {
+	return url.Values{"pool_in": {poolIn}}
+}
+
+func (c *Client) ListEsbRecords(ctx context.Context) ([]EsbRecord, error) {
+	return listAll[EsbRecord](ctx, c, ESB_ENDPOINT, nil, "records")
+}
+
+// returns empty code for records without mapper
+func (c *Client) GetEsbRecordCode(ctx context.Context, poolIn string) (string, error) {
+	var code string
+	err := c.do(ctx, http.MethodGet, ESB_ENDPOINT+"/code", poolInQuery(poolIn), nil, &code)
+	if errors.Is(err, ErrBadRequest) {
+		return "", nil
+	}
+	return code, err
+}
+
+func (c *Client) CreateEsbRecord(ctx context.Context, record EsbRecord) error {
+	return c.do(ctx, http.MethodPost, ESB_ENDPOINT, nil, record, nil)
+}
+
+// empty code removes mapper of the record
+func (c *Client) UpdateEsbRecord(ctx context.Context, record EsbRecord) error {
+	return c.do(ctx, http.MethodPut, ESB_ENDPOINT, nil, record, nil)
+}
+
+func (c *Client) DeleteEsbRecord(ctx context.Context, poolIn string) error {
+	return c.do(ctx, http.MethodDelete, ESB_ENDPOINT, poolInQuery(poolIn), nil, nil)
+}
+
+// writes messages into in-pool of esb pair and schedules read from it
+func (c *Client) SubmitEsbTask(ctx context.Context, poolIn string, messages []string) error {
+	task := BrokerTask{PoolName: poolIn, Messages: messages}
+	return c.do(ctx, http.MethodPost, ESB_ENDPOINT+"/task", nil, task, nil)
+}
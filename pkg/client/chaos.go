@@ -0,0 +1,53 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+const CHAOS_ENDPOINT = "/api/chaos"
+
+// percentages are of affected requests or write tasks, 0..100
+type ChaosProfile struct {
+	// namespace prefix, whole server if empty
+	Prefix        string  `json:"prefix,omitempty"`
+	ErrorPercent  float64 `json:"error_percent"`
+	ErrorStatuses []int   `json:"error_statuses,omitempty"`
+	DelayPercent  float64 `json:"delay_percent"`
+	DelayMinMs    int64   `json:"delay_min_ms"`
+	DelayMaxMs    int64   `json:"delay_max_ms"`
+	ResetPercent  float64 `json:"reset_percent"`
+	// whole server profile only
+	DropWritePercent float64 `json:"drop_write_percent"`
+	// random if not set
+	Seed int64 `json:"seed,omitempty"`
+}
+
+func (c *Client) ListChaosProfiles(ctx context.Context) ([]ChaosProfile, error) {
+	var resp struct {
+		Profiles []ChaosProfile `json:"profiles"`
+	}
+	if err := c.do(ctx, http.MethodGet, CHAOS_ENDPOINT, nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Profiles, nil
+}
+
+// returns enabled profile with the seed in use
+func (c *Client) SetChaosProfile(ctx context.Context, profile ChaosProfile) (*ChaosProfile, error) {
+	var enabled ChaosProfile
+	if err := c.do(ctx, http.MethodPut, CHAOS_ENDPOINT, nil, profile, &enabled); err != nil {
+		return nil, err
+	}
+	return &enabled, nil
+}
+
+// empty prefix disables whole server profile
+func (c *Client) DisableChaosProfile(ctx context.Context, prefix string) error {
+	var query url.Values
+	if prefix != "" {
+		query = url.Values{"prefix": {prefix}}
+	}
+	return c.do(ctx, http.MethodDelete, CHAOS_ENDPOINT, query, nil, nil)
+}
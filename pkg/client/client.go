@@ -0,0 +1,155 @@
+// Package client is a typed client of the mock-server admin API.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const DEFAULT_ADDR = "http://127.0.0.1:1337"
+
+type Client struct {
+	addr     string
+	token    string
+	username string
+	password string
+	http     *http.Client
+}
+
+type Option func(*Client)
+
+// authenticate with bearer token, takes precedence over basic auth
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+func WithBasicAuth(username string, password string) Option {
+	return func(c *Client) {
+		c.username = username
+		c.password = password
+	}
+}
+
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.http = httpClient
+	}
+}
+
+// addr is a base url of mock server, e.g. http://127.0.0.1:1337
+func New(addr string, opts ...Option) *Client {
+	c := &Client{
+		addr: strings.TrimSuffix(addr, "/"),
+		http: &http.Client{Timeout: 30 * time.Second},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+func (c *Client) Addr() string {
+	return c.addr
+}
+
+// sends request with json body (if not nil) and decodes json response into out (if not nil)
+func (c *Client) do(ctx context.Context, method string, path string, query url.Values, body interface{}, out interface{}) error {
+	if body == nil {
+		return c.send(ctx, method, path, query, "", nil, out)
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	return c.send(ctx, method, path, query, "application/json", bytes.NewReader(data), out)
+}
+
+// sends request with body of content type (if body is not nil) and decodes json response into out (if not nil)
+func (c *Client) send(ctx context.Context, method string, path string, query url.Values, contentType string, body io.Reader, out interface{}) error {
+	target := c.addr + path
+	if len(query) != 0 {
+		target += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	} else if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode >= 300 {
+		return newAPIError(resp.StatusCode, respBody)
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return json.Unmarshal(respBody, out)
+}
+
+func pathQuery(path string) url.Values {
+	return url.Values{"path": {path}}
+}
+
+// fetches items under key of all pages of list endpoint
+func listAll[T any](ctx context.Context, c *Client, endpoint string, query url.Values, key string) ([]T, error) {
+	items := make([]T, 0)
+	cursor := ""
+	for {
+		pageQuery := url.Values{}
+		for name, values := range query {
+			pageQuery[name] = values
+		}
+		if cursor != "" {
+			pageQuery.Set("cursor", cursor)
+		}
+
+		var page map[string]json.RawMessage
+		if err := c.do(ctx, http.MethodGet, endpoint, pageQuery, nil, &page); err != nil {
+			return nil, err
+		}
+
+		var pageItems []T
+		if err := json.Unmarshal(page[key], &pageItems); err != nil {
+			return nil, err
+		}
+		items = append(items, pageItems...)
+
+		cursor = ""
+		if next, ok := page["next_cursor"]; ok {
+			if err := json.Unmarshal(next, &cursor); err != nil {
+				return nil, err
+			}
+		}
+		if cursor == "" {
+			return items, nil
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// match with errors.Is against any error returned by Client
+var (
+	ErrBadRequest   = errors.New("bad request")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+)
+
+// non-2xx response of admin api
+type APIError struct {
+	StatusCode int
+	Message    string
+
+	body []byte
+}
+
+func newAPIError(status int, body []byte) *APIError {
+	return &APIError{StatusCode: status, Message: errorMessage(body), body: body}
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("mock server responded %d: %s", e.StatusCode, e.Message)
+}
+
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	default:
+		return false
+	}
+}
+
+// server replies with {"error": "..."} or with plain json string
+func errorMessage(body []byte) string {
+	var withError struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &withError); err == nil && withError.Error != "" {
+		return withError.Error
+	}
+	var message string
+	if err := json.Unmarshal(body, &message); err == nil {
+		return message
+	}
+	return strings.TrimSpace(string(body))
+}
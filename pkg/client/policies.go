@@ -0,0 +1,85 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+const (
+	CORS_POLICY_ENDPOINT       = "/api/routes/policy/cors"
+	RATE_LIMIT_POLICY_ENDPOINT = "/api/routes/policy/rate_limit"
+	NAMESPACES_ENDPOINT        = "/api/namespaces"
+)
+
+// route policies
+
+func (c *Client) GetRouteCorsPolicy(ctx context.Context, path string) (*CorsPolicy, error) {
+	var policy RouteCorsPolicy
+	if err := c.getByPath(ctx, CORS_POLICY_ENDPOINT, path, &policy); err != nil {
+		return nil, err
+	}
+	return policy.Cors, nil
+}
+
+func (c *Client) SetRouteCorsPolicy(ctx context.Context, path string, policy CorsPolicy) error {
+	body := RouteCorsPolicy{Path: path, Cors: &policy}
+	return c.do(ctx, http.MethodPut, CORS_POLICY_ENDPOINT, nil, body, nil)
+}
+
+func (c *Client) DeleteRouteCorsPolicy(ctx context.Context, path string) error {
+	return c.deleteByPath(ctx, CORS_POLICY_ENDPOINT, path)
+}
+
+func (c *Client) GetRouteRateLimitPolicy(ctx context.Context, path string) (*RateLimitPolicy, error) {
+	var policy RouteRateLimitPolicy
+	if err := c.getByPath(ctx, RATE_LIMIT_POLICY_ENDPOINT, path, &policy); err != nil {
+		return nil, err
+	}
+	return policy.RateLimit, nil
+}
+
+func (c *Client) SetRouteRateLimitPolicy(ctx context.Context, path string, policy RateLimitPolicy) error {
+	body := RouteRateLimitPolicy{Path: path, RateLimit: &policy}
+	return c.do(ctx, http.MethodPut, RATE_LIMIT_POLICY_ENDPOINT, nil, body, nil)
+}
+
+func (c *Client) DeleteRouteRateLimitPolicy(ctx context.Context, path string) error {
+	return c.deleteByPath(ctx, RATE_LIMIT_POLICY_ENDPOINT, path)
+}
+
+// namespaces
+
+func prefixQuery(prefix string) url.Values {
+	return url.Values{"prefix": {prefix}}
+}
+
+func (c *Client) ListNamespaces(ctx context.Context) ([]Namespace, error) {
+	var resp struct {
+		Namespaces []Namespace `json:"namespaces"`
+	}
+	if err := c.do(ctx, http.MethodGet, NAMESPACES_ENDPOINT, nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Namespaces, nil
+}
+
+func (c *Client) GetNamespace(ctx context.Context, prefix string) (*Namespace, error) {
+	var namespace Namespace
+	if err := c.do(ctx, http.MethodGet, NAMESPACES_ENDPOINT+"/config", prefixQuery(prefix), nil, &namespace); err != nil {
+		return nil, err
+	}
+	return &namespace, nil
+}
+
+func (c *Client) CreateNamespace(ctx context.Context, namespace Namespace) error {
+	return c.do(ctx, http.MethodPost, NAMESPACES_ENDPOINT, nil, namespace, nil)
+}
+
+func (c *Client) UpdateNamespace(ctx context.Context, namespace Namespace) error {
+	return c.do(ctx, http.MethodPut, NAMESPACES_ENDPOINT, nil, namespace, nil)
+}
+
+func (c *Client) DeleteNamespace(ctx context.Context, prefix string) error {
+	return c.do(ctx, http.MethodDelete, NAMESPACES_ENDPOINT, prefixQuery(prefix), nil, nil)
+}
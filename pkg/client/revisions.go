@@ -0,0 +1,64 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const REVISIONS_ENDPOINT = "/api/revisions"
+
+const (
+	REVISION_RESOURCE_ROUTE = "route"
+	REVISION_RESOURCE_ESB   = "esb"
+)
+
+type Revision struct {
+	Revision  int64           `json:"revision"`
+	Timestamp time.Time       `json:"timestamp"`
+	Actor     string          `json:"actor,omitempty"`
+	State     json.RawMessage `json:"state"`
+	Code      string          `json:"code,omitempty"`
+}
+
+type FieldChange struct {
+	Field  string          `json:"field"`
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
+
+type RevisionDiff struct {
+	From   int64         `json:"from"`
+	To     int64         `json:"to"`
+	Fields []FieldChange `json:"fields"`
+	Code   []string      `json:"code,omitempty"`
+}
+
+func revisionQuery(resource string, key string) url.Values {
+	return url.Values{"resource": {resource}, "key": {key}}
+}
+
+// revisions of route path or esb in-pool from the first one
+func (c *Client) ListRevisions(ctx context.Context, resource string, key string) ([]Revision, error) {
+	return listAll[Revision](ctx, c, REVISIONS_ENDPOINT, revisionQuery(resource, key), "revisions")
+}
+
+func (c *Client) DiffRevisions(ctx context.Context, resource string, key string, from int64, to int64) (*RevisionDiff, error) {
+	query := revisionQuery(resource, key)
+	query.Set("from", strconv.FormatInt(from, 10))
+	query.Set("to", strconv.FormatInt(to, 10))
+
+	var diff RevisionDiff
+	if err := c.do(ctx, http.MethodGet, REVISIONS_ENDPOINT+"/diff", query, nil, &diff); err != nil {
+		return nil, err
+	}
+	return &diff, nil
+}
+
+func (c *Client) RollbackRevision(ctx context.Context, resource string, key string, revision int64) error {
+	body := map[string]interface{}{"resource": resource, "key": key, "revision": revision}
+	return c.do(ctx, http.MethodPost, REVISIONS_ENDPOINT+"/rollback", nil, body, nil)
+}
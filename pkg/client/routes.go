@@ -0,0 +1,289 @@
+package client
+
+import (
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	ROUTES_ENDPOINT           = "/api/routes"
+	STATIC_ROUTES_ENDPOINT    = "/api/routes/static"
+	PROXY_ROUTES_ENDPOINT     = "/api/routes/proxy"
+	DYNAMIC_ROUTES_ENDPOINT   = "/api/routes/dynamic"
+	GRAPHQL_ROUTES_ENDPOINT   = "/api/routes/graphql"
+	WEBSOCKET_ROUTES_ENDPOINT = "/api/routes/websocket"
+	STREAM_ROUTES_ENDPOINT    = "/api/routes/stream"
+	FILE_ROUTES_ENDPOINT      = "/api/routes/file"
+)
+
+func (c *Client) listPaths(ctx context.Context, endpoint string) ([]string, error) {
+	return listAll[string](ctx, c, endpoint, nil, "endpoints")
+}
+
+func (c *Client) getByPath(ctx context.Context, endpoint string, path string, out interface{}) error {
+	return c.do(ctx, http.MethodGet, endpoint, pathQuery(path), nil, out)
+}
+
+func (c *Client) deleteByPath(ctx context.Context, endpoint string, path string) error {
+	return c.do(ctx, http.MethodDelete, endpoint, pathQuery(path), nil, nil)
+}
+
+// routes of all types with details
+func (c *Client) ListRoutes(ctx context.Context) ([]RouteInfo, error) {
+	return listAll[RouteInfo](ctx, c, ROUTES_ENDPOINT, nil, "routes")
+}
+
+// static routes
+
+func (c *Client) ListStaticRoutes(ctx context.Context) ([]string, error) {
+	return c.listPaths(ctx, STATIC_ROUTES_ENDPOINT)
+}
+
+func (c *Client) GetStaticRouteResponse(ctx context.Context, path string) (string, error) {
+	var response string
+	err := c.getByPath(ctx, STATIC_ROUTES_ENDPOINT+"/expected_response", path, &response)
+	return response, err
+}
+
+func (c *Client) GetStaticRoute(ctx context.Context, path string) (*StaticEndpoint, error) {
+	var endpoint StaticEndpoint
+	if err := c.getByPath(ctx, STATIC_ROUTES_ENDPOINT+"/config", path, &endpoint); err != nil {
+		return nil, err
+	}
+	return &endpoint, nil
+}
+
+func (c *Client) CreateStaticRoute(ctx context.Context, endpoint StaticEndpoint) error {
+	return c.do(ctx, http.MethodPost, STATIC_ROUTES_ENDPOINT, nil, endpoint, nil)
+}
+
+func (c *Client) UpdateStaticRoute(ctx context.Context, endpoint StaticEndpoint) error {
+	return c.do(ctx, http.MethodPut, STATIC_ROUTES_ENDPOINT, nil, endpoint, nil)
+}
+
+func (c *Client) DeleteStaticRoute(ctx context.Context, path string) error {
+	return c.deleteByPath(ctx, STATIC_ROUTES_ENDPOINT, path)
+}
+
+// proxy routes
+
+func (c *Client) ListProxyRoutes(ctx context.Context) ([]string, error) {
+	return c.listPaths(ctx, PROXY_ROUTES_ENDPOINT)
+}
+
+func (c *Client) GetProxyRouteUrl(ctx context.Context, path string) (string, error) {
+	var proxyUrl string
+	err := c.getByPath(ctx, PROXY_ROUTES_ENDPOINT+"/proxy_url", path, &proxyUrl)
+	return proxyUrl, err
+}
+
+func (c *Client) GetProxyRoute(ctx context.Context, path string) (*ProxyEndpoint, error) {
+	var endpoint ProxyEndpoint
+	if err := c.getByPath(ctx, PROXY_ROUTES_ENDPOINT+"/config", path, &endpoint); err != nil {
+		return nil, err
+	}
+	return &endpoint, nil
+}
+
+func (c *Client) CreateProxyRoute(ctx context.Context, endpoint ProxyEndpoint) error {
+	return c.do(ctx, http.MethodPost, PROXY_ROUTES_ENDPOINT, nil, endpoint, nil)
+}
+
+func (c *Client) UpdateProxyRoute(ctx context.Context, endpoint ProxyEndpoint) error {
+	return c.do(ctx, http.MethodPut, PROXY_ROUTES_ENDPOINT, nil, endpoint, nil)
+}
+
+func (c *Client) DeleteProxyRoute(ctx context.Context, path string) error {
+	return c.deleteByPath(ctx, PROXY_ROUTES_ENDPOINT, path)
+}
+
+// dynamic routes
+
+func (c *Client) ListDynamicRoutes(ctx context.Context) ([]string, error) {
+	return c.listPaths(ctx, DYNAMIC_ROUTES_ENDPOINT)
+}
+
+func (c *Client) GetDynamicRouteCode(ctx context.Context, path string) (string, error) {
+	var code string
+	err := c.getByPath(ctx, DYNAMIC_ROUTES_ENDPOINT+"/code", path, &code)
+	return code, err
+}
+
+func (c *Client) GetDynamicRoute(ctx context.Context, path string) (*DynamicEndpoint, error) {
+	var endpoint DynamicEndpoint
+	if err := c.getByPath(ctx, DYNAMIC_ROUTES_ENDPOINT+"/config", path, &endpoint); err != nil {
+		return nil, err
+	}
+	return &endpoint, nil
+}
+
+func (c *Client) CreateDynamicRoute(ctx context.Context, endpoint DynamicEndpoint) error {
+	return c.do(ctx, http.MethodPost, DYNAMIC_ROUTES_ENDPOINT, nil, endpoint, nil)
+}
+
+func (c *Client) UpdateDynamicRoute(ctx context.Context, endpoint DynamicEndpoint) error {
+	return c.do(ctx, http.MethodPut, DYNAMIC_ROUTES_ENDPOINT, nil, endpoint, nil)
+}
+
+func (c *Client) DeleteDynamicRoute(ctx context.Context, path string) error {
+	return c.deleteByPath(ctx, DYNAMIC_ROUTES_ENDPOINT, path)
+}
+
+// graphql routes
+
+func (c *Client) ListGraphQLRoutes(ctx context.Context) ([]string, error) {
+	return c.listPaths(ctx, GRAPHQL_ROUTES_ENDPOINT)
+}
+
+func (c *Client) GetGraphQLRoute(ctx context.Context, path string) (*GraphQLEndpoint, error) {
+	var endpoint GraphQLEndpoint
+	if err := c.getByPath(ctx, GRAPHQL_ROUTES_ENDPOINT+"/config", path, &endpoint); err != nil {
+		return nil, err
+	}
+	return &endpoint, nil
+}
+
+func (c *Client) CreateGraphQLRoute(ctx context.Context, endpoint GraphQLEndpoint) error {
+	return c.do(ctx, http.MethodPost, GRAPHQL_ROUTES_ENDPOINT, nil, endpoint, nil)
+}
+
+func (c *Client) UpdateGraphQLRoute(ctx context.Context, endpoint GraphQLEndpoint) error {
+	return c.do(ctx, http.MethodPut, GRAPHQL_ROUTES_ENDPOINT, nil, endpoint, nil)
+}
+
+func (c *Client) DeleteGraphQLRoute(ctx context.Context, path string) error {
+	return c.deleteByPath(ctx, GRAPHQL_ROUTES_ENDPOINT, path)
+}
+
+// websocket routes
+
+func (c *Client) ListWebSocketRoutes(ctx context.Context) ([]string, error) {
+	return c.listPaths(ctx, WEBSOCKET_ROUTES_ENDPOINT)
+}
+
+func (c *Client) GetWebSocketRoute(ctx context.Context, path string) (*WebSocketEndpoint, error) {
+	var endpoint WebSocketEndpoint
+	if err := c.getByPath(ctx, WEBSOCKET_ROUTES_ENDPOINT+"/config", path, &endpoint); err != nil {
+		return nil, err
+	}
+	return &endpoint, nil
+}
+
+func (c *Client) CreateWebSocketRoute(ctx context.Context, endpoint WebSocketEndpoint) error {
+	return c.do(ctx, http.MethodPost, WEBSOCKET_ROUTES_ENDPOINT, nil, endpoint, nil)
+}
+
+func (c *Client) UpdateWebSocketRoute(ctx context.Context, endpoint WebSocketEndpoint) error {
+	return c.do(ctx, http.MethodPut, WEBSOCKET_ROUTES_ENDPOINT, nil, endpoint, nil)
+}
+
+func (c *Client) DeleteWebSocketRoute(ctx context.Context, path string) error {
+	return c.deleteByPath(ctx, WEBSOCKET_ROUTES_ENDPOINT, path)
+}
+
+// returns number of clients that received the message
+func (c *Client) BroadcastWebSocket(ctx context.Context, broadcast WebSocketBroadcast) (int, error) {
+	var resp struct {
+		Clients int `json:"clients"`
+	}
+	err := c.do(ctx, http.MethodPost, WEBSOCKET_ROUTES_ENDPOINT+"/broadcast", nil, broadcast, &resp)
+	return resp.Clients, err
+}
+
+// stream routes
+
+func (c *Client) ListStreamRoutes(ctx context.Context) ([]string, error) {
+	return c.listPaths(ctx, STREAM_ROUTES_ENDPOINT)
+}
+
+func (c *Client) GetStreamRoute(ctx context.Context, path string) (*StreamEndpoint, error) {
+	var endpoint StreamEndpoint
+	if err := c.getByPath(ctx, STREAM_ROUTES_ENDPOINT+"/config", path, &endpoint); err != nil {
+		return nil, err
+	}
+	return &endpoint, nil
+}
+
+func (c *Client) CreateStreamRoute(ctx context.Context, endpoint StreamEndpoint) error {
+	return c.do(ctx, http.MethodPost, STREAM_ROUTES_ENDPOINT, nil, endpoint, nil)
+}
+
+func (c *Client) UpdateStreamRoute(ctx context.Context, endpoint StreamEndpoint) error {
+	return c.do(ctx, http.MethodPut, STREAM_ROUTES_ENDPOINT, nil, endpoint, nil)
+}
+
+func (c *Client) DeleteStreamRoute(ctx context.Context, path string) error {
+	return c.deleteByPath(ctx, STREAM_ROUTES_ENDPOINT, path)
+}
+
+// file routes
+
+func (c *Client) ListFileRoutes(ctx context.Context) ([]string, error) {
+	return c.listPaths(ctx, FILE_ROUTES_ENDPOINT)
+}
+
+func (c *Client) GetFileRoute(ctx context.Context, path string) (*FileEndpointInfo, error) {
+	var endpoint FileEndpointInfo
+	if err := c.getByPath(ctx, FILE_ROUTES_ENDPOINT+"/config", path, &endpoint); err != nil {
+		return nil, err
+	}
+	return &endpoint, nil
+}
+
+func writeFileEndpointForm(w *multipart.Writer, endpoint *FileEndpoint) error {
+	fields := map[string]string{"path": endpoint.Path, "content_type": endpoint.ContentType}
+	if endpoint.TTLSec != 0 {
+		fields["ttl_sec"] = strconv.FormatInt(endpoint.TTLSec, 10)
+	}
+	for name, value := range map[string]*time.Time{
+		"expire_at":    endpoint.ExpireAt,
+		"active_from":  endpoint.ActiveFrom,
+		"active_until": endpoint.ActiveUntil,
+	} {
+		if value != nil {
+			fields[name] = value.Format(time.RFC3339)
+		}
+	}
+	for name, value := range fields {
+		if err := w.WriteField(name, value); err != nil {
+			return err
+		}
+	}
+
+	part, err := w.CreateFormFile("file", endpoint.FileName)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, endpoint.Content); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// content is streamed to server without buffering
+func (c *Client) uploadFileRoute(ctx context.Context, method string, endpoint FileEndpoint) error {
+	body, pipe := io.Pipe()
+	form := multipart.NewWriter(pipe)
+	go func() {
+		pipe.CloseWithError(writeFileEndpointForm(form, &endpoint))
+	}()
+	defer body.Close()
+
+	return c.send(ctx, method, FILE_ROUTES_ENDPOINT, nil, form.FormDataContentType(), body, nil)
+}
+
+func (c *Client) CreateFileRoute(ctx context.Context, endpoint FileEndpoint) error {
+	return c.uploadFileRoute(ctx, http.MethodPost, endpoint)
+}
+
+func (c *Client) UpdateFileRoute(ctx context.Context, endpoint FileEndpoint) error {
+	return c.uploadFileRoute(ctx, http.MethodPut, endpoint)
+}
+
+func (c *Client) DeleteFileRoute(ctx context.Context, path string) error {
+	return c.deleteByPath(ctx, FILE_ROUTES_ENDPOINT, path)
+}
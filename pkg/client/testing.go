@@ -0,0 +1,108 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// helpers for tests: create entity, fail the test on error and remove entity on t.Cleanup;
+// entities already removed by the test itself are ignored during cleanup
+
+func cleanup(t testing.TB, what string, remove func(ctx context.Context) error) {
+	t.Cleanup(func() {
+		if err := remove(context.Background()); err != nil && !errors.Is(err, ErrNotFound) {
+			t.Errorf("cleanup %s: %s", what, err)
+		}
+	})
+}
+
+func SetupStaticRoute(t testing.TB, c *Client, endpoint StaticEndpoint) {
+	t.Helper()
+	if err := c.CreateStaticRoute(context.Background(), endpoint); err != nil {
+		t.Fatalf("create static route %s: %s", endpoint.Path, err)
+	}
+	cleanup(t, "static route "+endpoint.Path, func(ctx context.Context) error {
+		return c.DeleteStaticRoute(ctx, endpoint.Path)
+	})
+}
+
+func SetupProxyRoute(t testing.TB, c *Client, endpoint ProxyEndpoint) {
+	t.Helper()
+	if err := c.CreateProxyRoute(context.Background(), endpoint); err != nil {
+		t.Fatalf("create proxy route %s: %s", endpoint.Path, err)
+	}
+	cleanup(t, "proxy route "+endpoint.Path, func(ctx context.Context) error {
+		return c.DeleteProxyRoute(ctx, endpoint.Path)
+	})
+}
+
+func SetupDynamicRoute(t testing.TB, c *Client, endpoint DynamicEndpoint) {
+	t.Helper()
+	if err := c.CreateDynamicRoute(context.Background(), endpoint); err != nil {
+		t.Fatalf("create dynamic route %s: %s", endpoint.Path, err)
+	}
+	cleanup(t, "dynamic route "+endpoint.Path, func(ctx context.Context) error {
+		return c.DeleteDynamicRoute(ctx, endpoint.Path)
+	})
+}
+
+func SetupGraphQLRoute(t testing.TB, c *Client, endpoint GraphQLEndpoint) {
+	t.Helper()
+	if err := c.CreateGraphQLRoute(context.Background(), endpoint); err != nil {
+		t.Fatalf("create graphql route %s: %s", endpoint.Path, err)
+	}
+	cleanup(t, "graphql route "+endpoint.Path, func(ctx context.Context) error {
+		return c.DeleteGraphQLRoute(ctx, endpoint.Path)
+	})
+}
+
+func SetupWebSocketRoute(t testing.TB, c *Client, endpoint WebSocketEndpoint) {
+	t.Helper()
+	if err := c.CreateWebSocketRoute(context.Background(), endpoint); err != nil {
+		t.Fatalf("create websocket route %s: %s", endpoint.Path, err)
+	}
+	cleanup(t, "websocket route "+endpoint.Path, func(ctx context.Context) error {
+		return c.DeleteWebSocketRoute(ctx, endpoint.Path)
+	})
+}
+
+func SetupStreamRoute(t testing.TB, c *Client, endpoint StreamEndpoint) {
+	t.Helper()
+	if err := c.CreateStreamRoute(context.Background(), endpoint); err != nil {
+		t.Fatalf("create stream route %s: %s", endpoint.Path, err)
+	}
+	cleanup(t, "stream route "+endpoint.Path, func(ctx context.Context) error {
+		return c.DeleteStreamRoute(ctx, endpoint.Path)
+	})
+}
+
+func SetupNamespace(t testing.TB, c *Client, namespace Namespace) {
+	t.Helper()
+	if err := c.CreateNamespace(context.Background(), namespace); err != nil {
+		t.Fatalf("create namespace %s: %s", namespace.Prefix, err)
+	}
+	cleanup(t, "namespace "+namespace.Prefix, func(ctx context.Context) error {
+		return c.DeleteNamespace(ctx, namespace.Prefix)
+	})
+}
+
+func SetupPool(t testing.TB, c *Client, pool MessagePool) {
+	t.Helper()
+	if err := c.CreatePool(context.Background(), pool); err != nil {
+		t.Fatalf("create pool %s: %s", pool.PoolName, err)
+	}
+	cleanup(t, "pool "+pool.PoolName, func(ctx context.Context) error {
+		return c.DeletePool(ctx, pool.PoolName)
+	})
+}
+
+func SetupEsbRecord(t testing.TB, c *Client, record EsbRecord) {
+	t.Helper()
+	if err := c.CreateEsbRecord(context.Background(), record); err != nil {
+		t.Fatalf("create esb record %s: %s", record.PoolNameIn, err)
+	}
+	cleanup(t, "esb record "+record.PoolNameIn, func(ctx context.Context) error {
+		return c.DeleteEsbRecord(ctx, record.PoolNameIn)
+	})
+}
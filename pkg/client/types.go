@@ -0,0 +1,196 @@
+package client
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+const (
+	BROKER_RABBITMQ = "rabbitmq"
+	BROKER_KAFKA    = "kafka"
+
+	STREAM_MODE_SSE     = "sse"
+	STREAM_MODE_CHUNKED = "chunked"
+
+	RATE_LIMIT_KEY_BY_IP     = "ip"
+	RATE_LIMIT_KEY_BY_HEADER = "header"
+)
+
+// types mirror internal/server/protocol, they are duplicated here
+// because internal packages can not be imported outside of the module
+
+type RouteWindow struct {
+	// ttl_sec and expire_at are mutually exclusive
+	TTLSec      int64      `json:"ttl_sec,omitempty"`
+	ExpireAt    *time.Time `json:"expire_at,omitempty"`
+	ActiveFrom  *time.Time `json:"active_from,omitempty"`
+	ActiveUntil *time.Time `json:"active_until,omitempty"`
+}
+
+// body of static route served for requests accepting its content type
+type Representation struct {
+	ContentType string `json:"content_type"`
+	Body        string `json:"body"`
+}
+
+// expected response is not used if representations are set
+type StaticEndpoint struct {
+	Path             string           `json:"path"`
+	ExpectedResponse string           `json:"expected_response,omitempty"`
+	Representations  []Representation `json:"representations,omitempty"`
+
+	RouteWindow
+}
+
+type ProxyEndpoint struct {
+	Path     string `json:"path"`
+	ProxyUrl string `json:"proxy_url"`
+
+	RouteWindow
+}
+
+type DynamicEndpoint struct {
+	Path string `json:"path"`
+	Code string `json:"code"`
+
+	RouteWindow
+}
+
+type GraphQLOperation struct {
+	OperationName string                 `json:"operation_name"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+	Response      json.RawMessage        `json:"response,omitempty"`
+	Code          string                 `json:"code,omitempty"`
+}
+
+type GraphQLEndpoint struct {
+	Path       string             `json:"path"`
+	Schema     string             `json:"schema,omitempty"`
+	Operations []GraphQLOperation `json:"operations"`
+
+	RouteWindow
+}
+
+type WebSocketReply struct {
+	Match    string `json:"match"`
+	Regexp   bool   `json:"regexp,omitempty"`
+	Response string `json:"response"`
+}
+
+type WebSocketEndpoint struct {
+	Path      string           `json:"path"`
+	OnConnect []string         `json:"on_connect,omitempty"`
+	Replies   []WebSocketReply `json:"replies,omitempty"`
+	Code      string           `json:"code,omitempty"`
+
+	RouteWindow
+}
+
+type WebSocketBroadcast struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+type StreamEvent struct {
+	Event   string `json:"event,omitempty"`
+	Data    string `json:"data"`
+	Id      string `json:"id,omitempty"`
+	DelayMs int64  `json:"delay_ms,omitempty"`
+}
+
+type StreamEndpoint struct {
+	Path        string        `json:"path"`
+	Mode        string        `json:"mode"`
+	Events      []StreamEvent `json:"events"`
+	Loop        bool          `json:"loop,omitempty"`
+	ContentType string        `json:"content_type,omitempty"`
+
+	RouteWindow
+}
+
+// content is streamed as multipart form file
+type FileEndpoint struct {
+	Path string
+	// detected by server from file name extension or content if empty
+	ContentType string
+	FileName    string
+	Content     io.Reader
+
+	RouteWindow
+}
+
+type FileEndpointInfo struct {
+	Path        string `json:"path"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	Digest      string `json:"digest"`
+
+	RouteWindow
+}
+
+// summary of mock route of any type returned by route listing
+type RouteInfo struct {
+	Path       string     `json:"path"`
+	Type       string     `json:"type"`
+	Methods    []string   `json:"methods"`
+	Matcher    string     `json:"matcher"`
+	Response   string     `json:"response,omitempty"`
+	ProxyURL   string     `json:"proxy_url,omitempty"`
+	ScriptName string     `json:"script_name,omitempty"`
+	CreatedAt  *time.Time `json:"created_at,omitempty"`
+	UpdatedAt  *time.Time `json:"updated_at,omitempty"`
+	Hits       int64      `json:"hits"`
+	Active     bool       `json:"active"`
+}
+
+type CorsPolicy struct {
+	AllowOrigins     []string `json:"allow_origins"`
+	AllowMethods     []string `json:"allow_methods,omitempty"`
+	AllowHeaders     []string `json:"allow_headers,omitempty"`
+	ExposeHeaders    []string `json:"expose_headers,omitempty"`
+	AllowCredentials bool     `json:"allow_credentials,omitempty"`
+	MaxAgeSec        int64    `json:"max_age_sec,omitempty"`
+}
+
+type RouteCorsPolicy struct {
+	Path string      `json:"path"`
+	Cors *CorsPolicy `json:"cors"`
+}
+
+type RateLimitPolicy struct {
+	Limit     int64  `json:"limit"`
+	PeriodSec int64  `json:"period_sec"`
+	Burst     int64  `json:"burst,omitempty"`
+	KeyBy     string `json:"key_by"`
+	Header    string `json:"header,omitempty"`
+}
+
+type RouteRateLimitPolicy struct {
+	Path      string           `json:"path"`
+	RateLimit *RateLimitPolicy `json:"rate_limit"`
+}
+
+type Namespace struct {
+	Prefix    string           `json:"prefix"`
+	Cors      *CorsPolicy      `json:"cors,omitempty"`
+	RateLimit *RateLimitPolicy `json:"rate_limit,omitempty"`
+}
+
+type MessagePool struct {
+	PoolName  string `json:"pool_name"`
+	QueueName string `json:"queue_name,omitempty"`
+	TopicName string `json:"topic_name,omitempty"`
+	Broker    string `json:"broker"`
+}
+
+type BrokerTask struct {
+	PoolName string   `json:"pool_name"`
+	Messages []string `json:"messages"`
+}
+
+type EsbRecord struct {
+	PoolNameIn  string `json:"pool_name_in"`
+	PoolNameOut string `json:"pool_name_out"`
+	Code        string `json:"code,omitempty"`
+}
@@ -0,0 +1,242 @@
+// Package mockserver runs mock-server inside the test process.
+//
+// The server listens on a random local port, keeps state in process memory
+// and stores scripts in a temporary directory, so no mongod download or
+// network access is needed. Every server has its own state, servers of
+// parallel tests run side by side. Coderun (docker) and brokers are disabled
+// unless requested with options; they are process-wide, so servers using
+// them run one at a time.
+package mockserver
+
+import (
+	"context"
+	"fmt"
+	"mock-server/internal/brokers"
+	"mock-server/internal/coderun"
+	"mock-server/internal/configs"
+	"mock-server/internal/database"
+	"mock-server/internal/logger"
+	"mock-server/internal/server"
+	"mock-server/internal/util"
+	"mock-server/pkg/client"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// logger and gin mode are process-wide, the first started server sets them up
+var setupProcess sync.Once
+
+// held while server using coderun or brokers is running
+var sharedComponents sync.Mutex
+
+type Server struct {
+	// base url of server, e.g. http://127.0.0.1:41337
+	URL string
+
+	token string
+	// stops started parts of server in reverse order
+	stops []func()
+}
+
+type Option func(*configs.ServiceConfig)
+
+// enables coderun component, required by dynamic routes and esb mappers, needs docker
+func WithCoderun() Option {
+	return func(cfg *configs.ServiceConfig) {
+		cfg.Components.Coderun = true
+		cfg.Coderun = &configs.CoderunConfig{
+			WorkerCnt: 1,
+			WorkerConfig: configs.WorkerConfig{
+				HandleTimeout: 10 * time.Second,
+				ContainerConfig: configs.ContainerConfig{
+					CPULimit:      0.5,
+					MemoryLimitMB: 200,
+				},
+			},
+		}
+	}
+}
+
+// enables brokers component, connects to local rabbitmq and kafka
+// (RABBITMQ_HOST, KAFKA_HOST and other env variables are respected)
+func WithBrokers() Option {
+	return func(cfg *configs.ServiceConfig) {
+		cfg.Components.Brokers = true
+		cfg.Brokers = &configs.BrokersConfig{
+			Scheduler: configs.MPTaskSchedulerConfig{
+				R_workers:     10,
+				W_workers:     10,
+				Read_timeout:  10 * time.Second,
+				Write_timeout: 10 * time.Second,
+			},
+			Rabbitmq: configs.RabbitMQConnectionConfig{
+				Host:     "localhost",
+				Port:     5672,
+				Username: "guest",
+				Password: "guest",
+			},
+			Kafka: configs.KafkaConnectionConfig{
+				Host:     "localhost",
+				Port:     9092,
+				ClientId: "client",
+				GroupId:  "group",
+			},
+		}
+	}
+}
+
+// protects admin api with admin token, client of server uses it
+func WithAdminToken(token string) Option {
+	return func(cfg *configs.ServiceConfig) {
+		cfg.Server.Auth = &configs.AuthConfig{
+			Tokens: []configs.AuthToken{{Name: "mockserver", Token: token, Role: configs.AUTH_ROLE_ADMIN}},
+		}
+	}
+}
+
+// writes server logs to stderr, zerolog level: 0 - debug, 1 - info, 2 - warn, 3 - error;
+// logger is process-wide, so the option of the first started server is used
+func WithLogs(level uint8) Option {
+	return func(cfg *configs.ServiceConfig) {
+		cfg.Logs.ConsoleLoggingEnabled = true
+		cfg.Logs.Level = level
+	}
+}
+
+// stores state in database of external deployment instead of process memory
+func WithDatabase(uri string, name string) Option {
+	return func(cfg *configs.ServiceConfig) {
+		cfg.Database.Storage = configs.DATABASE_STORAGE_MONGO
+		cfg.Database.InMemory = false
+		cfg.Database.URI = uri
+		cfg.Database.Name = name
+	}
+}
+
+func defaultConfig() configs.ServiceConfig {
+	return configs.ServiceConfig{
+		Components: configs.ComponentsConfig{
+			Server: true,
+		},
+		Server: &configs.ServerConfig{
+			Addr:             "127.0.0.1:0",
+			AcceptTimeout:    20 * time.Second,
+			ResponseTimeout:  20 * time.Second,
+			DeployProduction: true, // no gin debug output
+		},
+		Logs: configs.LogConfig{
+			Level: 3,
+		},
+		Database: configs.DatabaseConfig{
+			Storage: configs.DATABASE_STORAGE_MEMORY,
+		},
+	}
+}
+
+// starts server and stops it on t.Cleanup, test fails if server can not start
+func Start(t testing.TB, opts ...Option) *Server {
+	t.Helper()
+
+	cfg := defaultConfig()
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	// registered before cleanup of server, so removed after it is stopped
+	dir := t.TempDir()
+
+	s := &Server{}
+	if err := s.start(cfg, dir); err != nil {
+		s.stop()
+		t.Fatalf("start mock server: %s", err)
+	}
+	t.Cleanup(s.stop)
+
+	if cfg.Server.Auth != nil {
+		s.token = cfg.Server.Auth.Tokens[0].Token
+	}
+	return s
+}
+
+// components panic on start failure
+func (s *Server) start(cfg configs.ServiceConfig, dir string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	setupProcess.Do(func() {
+		logger.Init(&cfg.Logs)
+		gin.SetMode(gin.ReleaseMode)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.stops = append(s.stops, cancel)
+
+	storage, err := database.NewStorage(ctx, &cfg.Database)
+	if err != nil {
+		return err
+	}
+	s.stops = append(s.stops, func() {
+		storage.Disconnect(context.Background())
+	})
+
+	if cfg.Components.Brokers || cfg.Components.Coderun {
+		sharedComponents.Lock()
+		s.stops = append(s.stops, sharedComponents.Unlock)
+		if err := s.startSharedComponents(ctx, cfg, storage, dir); err != nil {
+			return err
+		}
+	}
+
+	srv := server.New(storage, dir, cfg.Components)
+	srv.Init(cfg.Server)
+	srv.Start()
+	s.stops = append(s.stops, srv.Stop)
+
+	s.URL = fmt.Sprintf("http://%s", srv.Addr())
+	return nil
+}
+
+// coderun and brokers use config, file storage and database storage of process
+func (s *Server) startSharedComponents(ctx context.Context, cfg configs.ServiceConfig, storage database.Storage, dir string) error {
+	configs.SetConfig(cfg)
+	util.SetFileStorageRoot(dir)
+	database.UseStorage(storage)
+	s.stops = append(s.stops, func() {
+		util.SetFileStorageRoot("")
+	})
+
+	if cfg.Components.Brokers {
+		brokers.MPTaskScheduler.Init(ctx, configs.GetMPTaskSchedulerConfig())
+		brokers.MPTaskScheduler.Start()
+		s.stops = append(s.stops, brokers.MPTaskScheduler.Stop)
+	}
+
+	if cfg.Components.Coderun {
+		if err := coderun.WorkerWatcher.Init(ctx, configs.GetCoderunConfig()); err != nil {
+			return fmt.Errorf("coderun expected to init but failed: %s", err.Error())
+		}
+		s.stops = append(s.stops, coderun.WorkerWatcher.Stop)
+	}
+	return nil
+}
+
+func (s *Server) stop() {
+	for i := len(s.stops) - 1; i >= 0; i-- {
+		s.stops[i]()
+	}
+	s.stops = nil
+}
+
+// client of admin api of the server
+func (s *Server) Client(opts ...client.Option) *client.Client {
+	if s.token != "" {
+		opts = append([]client.Option{client.WithToken(s.token)}, opts...)
+	}
+	return client.New(s.URL, opts...)
+}
@@ -22,7 +22,7 @@ func TestKafka(t *testing.T) {
 		}
 	}()
 
-	handler, err := brokers.AddMessagePool(brokers.NewKafkaMessagePool("test-pool", "test-topic"))
+	handler, err := brokers.AddMessagePool(context.TODO(), brokers.NewKafkaMessagePool("test-pool", "test-topic"))
 	if err != nil {
 		t.Error(err)
 	}
@@ -31,7 +31,7 @@ func TestKafka(t *testing.T) {
 
 	time.Sleep(1 * time.Second)
 
-	handler, err = brokers.GetMessagePool("test-pool")
+	handler, err = brokers.GetMessagePool(context.TODO(), "test-pool")
 	if err != nil {
 		t.Error(err)
 	}
@@ -0,0 +1,76 @@
+package chaos_test
+
+import (
+	"mock-server/internal/chaos"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func decisions(prefix string, n int) []chaos.Decision {
+	result := make([]chaos.Decision, n)
+	for i := range result {
+		result[i], _ = chaos.Decide(prefix)
+	}
+	return result
+}
+
+func TestChaosSeedReproducible(t *testing.T) {
+	profile := chaos.Profile{
+		ErrorPercent:  30,
+		ErrorStatuses: []int{502, 503},
+		DelayPercent:  50,
+		DelayMin:      time.Millisecond,
+		DelayMax:      10 * time.Millisecond,
+		ResetPercent:  10,
+		Seed:          42,
+	}
+	defer chaos.RemoveProfile(chaos.GLOBAL_PREFIX)
+
+	chaos.SetProfile(profile)
+	first := decisions("", 100)
+	chaos.SetProfile(profile)
+	second := decisions("", 100)
+
+	if !reflect.DeepEqual(first, second) {
+		t.Fatalf("decisions of the same seed differ")
+	}
+
+	for _, d := range first {
+		if d.Delay != 0 && (d.Delay < profile.DelayMin || d.Delay > profile.DelayMax) {
+			t.Errorf("delay %s out of range", d.Delay)
+		}
+		if d.Status != 0 && d.Status != 502 && d.Status != 503 {
+			t.Errorf("unexpected status %d", d.Status)
+		}
+		if d.Reset && d.Status != 0 {
+			t.Errorf("reset request is also failed")
+		}
+	}
+}
+
+func TestChaosNamespacePrecedence(t *testing.T) {
+	defer chaos.RemoveProfile(chaos.GLOBAL_PREFIX)
+	defer chaos.RemoveProfile("/ns")
+
+	if _, ok := chaos.Decide("/ns"); ok {
+		t.Fatalf("decision without profiles")
+	}
+
+	chaos.SetProfile(chaos.Profile{ErrorPercent: 100})
+	chaos.SetProfile(chaos.Profile{Prefix: "/ns", ErrorPercent: 100, ErrorStatuses: []int{504}})
+
+	if d, _ := chaos.Decide("/ns"); d.Status != 504 {
+		t.Errorf("namespace profile is not applied: %d", d.Status)
+	}
+	if d, _ := chaos.Decide("/other"); d.Status != chaos.DEFAULT_ERROR_STATUS {
+		t.Errorf("global profile is not applied: %d", d.Status)
+	}
+
+	if !chaos.RemoveProfile("/ns") || chaos.RemoveProfile("/ns") {
+		t.Errorf("unexpected remove result")
+	}
+	if chaos.DropWriteTask() {
+		t.Errorf("write task dropped with zero percent")
+	}
+}
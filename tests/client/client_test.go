@@ -0,0 +1,149 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mock-server/internal/configs"
+	"mock-server/internal/control"
+	"mock-server/pkg/client"
+	"testing"
+)
+
+func TestClientStaticRoutes(t *testing.T) {
+	t.Setenv("CONFIG_PATH", "/configs/test_server_config.yaml")
+
+	control.Components.Start()
+	defer control.Components.Stop()
+
+	cfg := configs.GetServerConfig()
+	cl := client.New(fmt.Sprintf("http://%s", cfg.Addr))
+	ctx := context.Background()
+
+	paths, err := cl.ListStaticRoutes(ctx)
+	if err != nil {
+		t.Fatalf("list static routes: %s", err)
+	}
+	if len(paths) != 0 {
+		t.Errorf("expected no static routes at the begining, got %v", paths)
+	}
+
+	endpoint := client.StaticEndpoint{Path: "/test_url", ExpectedResponse: "hello"}
+	if err := cl.CreateStaticRoute(ctx, endpoint); err != nil {
+		t.Fatalf("create static route: %s", err)
+	}
+
+	// same path again -> conflict
+	err = cl.CreateStaticRoute(ctx, endpoint)
+	if !errors.Is(err, client.ErrConflict) {
+		t.Errorf("expected conflict on duplicate create, got %v", err)
+	}
+	var apiErr *client.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 409 {
+		t.Errorf("expected APIError with 409 status, got %v", err)
+	}
+
+	endpoint.ExpectedResponse = "world"
+	if err := cl.UpdateStaticRoute(ctx, endpoint); err != nil {
+		t.Fatalf("update static route: %s", err)
+	}
+
+	response, err := cl.GetStaticRouteResponse(ctx, "/test_url")
+	if err != nil {
+		t.Fatalf("get static route response: %s", err)
+	}
+	if response != "world" {
+		t.Errorf("expected updated response: %s != world", response)
+	}
+
+	if err := cl.DeleteStaticRoute(ctx, "/test_url"); err != nil {
+		t.Fatalf("delete static route: %s", err)
+	}
+
+	_, err = cl.GetStaticRouteResponse(ctx, "/test_url")
+	if !errors.Is(err, client.ErrNotFound) {
+		t.Errorf("expected not found after delete, got %v", err)
+	}
+
+	err = cl.UpdateStaticRoute(ctx, endpoint)
+	if !errors.Is(err, client.ErrNotFound) {
+		t.Errorf("expected not found on update of removed route, got %v", err)
+	}
+}
+
+func TestClientSetupHelpers(t *testing.T) {
+	t.Setenv("CONFIG_PATH", "/configs/test_server_config.yaml")
+
+	control.Components.Start()
+	defer control.Components.Stop()
+
+	cfg := configs.GetServerConfig()
+	cl := client.New(fmt.Sprintf("http://%s", cfg.Addr))
+	ctx := context.Background()
+
+	t.Run("setup", func(t *testing.T) {
+		client.SetupStaticRoute(t, cl, client.StaticEndpoint{Path: "/static_url", ExpectedResponse: "hello"})
+		client.SetupProxyRoute(t, cl, client.ProxyEndpoint{Path: "/proxy_url", ProxyUrl: "https://ya.ru"})
+		client.SetupNamespace(t, cl, client.Namespace{
+			Prefix: "/ns",
+			RateLimit: &client.RateLimitPolicy{
+				Limit:     10,
+				PeriodSec: 1,
+				KeyBy:     client.RATE_LIMIT_KEY_BY_IP,
+			},
+		})
+
+		static, err := cl.ListStaticRoutes(ctx)
+		if err != nil || len(static) != 1 {
+			t.Errorf("expected one static route inside subtest: %v, %v", static, err)
+		}
+
+		// removed by the test itself, cleanup must not fail
+		if err := cl.DeleteProxyRoute(ctx, "/proxy_url"); err != nil {
+			t.Errorf("delete proxy route: %s", err)
+		}
+	})
+
+	static, err := cl.ListStaticRoutes(ctx)
+	if err != nil || len(static) != 0 {
+		t.Errorf("expected static route to be removed on cleanup: %v, %v", static, err)
+	}
+
+	namespaces, err := cl.ListNamespaces(ctx)
+	if err != nil || len(namespaces) != 0 {
+		t.Errorf("expected namespace to be removed on cleanup: %v, %v", namespaces, err)
+	}
+}
+
+func TestClientAuth(t *testing.T) {
+	t.Setenv("CONFIG_PATH", "/configs/test_server_auth_config.yaml")
+
+	control.Components.Start()
+	defer control.Components.Stop()
+
+	cfg := configs.GetServerConfig()
+	addr := fmt.Sprintf("http://%s", cfg.Addr)
+	ctx := context.Background()
+
+	_, err := client.New(addr).ListStaticRoutes(ctx)
+	if !errors.Is(err, client.ErrUnauthorized) {
+		t.Errorf("expected unauthorized without credentials, got %v", err)
+	}
+
+	viewer := client.New(addr, client.WithToken("viewer-token"))
+	if _, err := viewer.ListStaticRoutes(ctx); err != nil {
+		t.Errorf("read only token must be able to list routes: %s", err)
+	}
+	err = viewer.CreateStaticRoute(ctx, client.StaticEndpoint{Path: "/test_url", ExpectedResponse: "hello"})
+	if !errors.Is(err, client.ErrForbidden) {
+		t.Errorf("expected forbidden for read only token, got %v", err)
+	}
+
+	operator := client.New(addr, client.WithBasicAuth("operator", "secret"))
+	if err := operator.CreateStaticRoute(ctx, client.StaticEndpoint{Path: "/test_url", ExpectedResponse: "hello"}); err != nil {
+		t.Errorf("admin must be able to create route: %s", err)
+	}
+	if err := operator.DeleteStaticRoute(ctx, "/test_url"); err != nil {
+		t.Errorf("admin must be able to delete route: %s", err)
+	}
+}
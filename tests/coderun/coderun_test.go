@@ -2,10 +2,12 @@ package coderun_test
 
 import (
 	"bytes"
+	"context"
 	"mock-server/internal/coderun"
 	"mock-server/internal/control"
 	"mock-server/internal/util"
 	"testing"
+	"time"
 )
 
 var TEST_SCRIPT_DYN_HANDLE = util.WrapCodeForDynHandle(`
@@ -51,12 +53,12 @@ func TestCoderunForDynHandle(t *testing.T) {
 	}
 
 	for i := 0; i < 10; i += 1 {
-		worker, err := coderun.WorkerWatcher.BorrowWorker()
+		worker, err := coderun.WorkerWatcher.BorrowWorker(context.Background())
 		if err != nil {
 			t.Error(err)
 		}
 
-		out, err := worker.RunScript("dyn_handle", "test_dyn_handle.py", TEST_ARGS_DYN_HANDLE)
+		out, err := worker.RunScript(context.Background(), "dyn_handle", "test_dyn_handle.py", TEST_ARGS_DYN_HANDLE)
 		if err != nil {
 			t.Error(err)
 			return
@@ -93,12 +95,12 @@ func TestCoderunForEsb(t *testing.T) {
 
 	}
 	for i := 0; i < 10; i += 1 {
-		worker, err := coderun.WorkerWatcher.BorrowWorker()
+		worker, err := coderun.WorkerWatcher.BorrowWorker(context.Background())
 		if err != nil {
 			t.Error(err)
 		}
 
-		out, err := worker.RunScript("mapper", "test_esb.py", TEST_ARGS_ESB)
+		out, err := worker.RunScript(context.Background(), "mapper", "test_esb.py", TEST_ARGS_ESB)
 		if err != nil {
 			t.Error(err)
 			return
@@ -134,12 +136,12 @@ func TestCoderunBadScript(t *testing.T) {
 	}
 
 	for i := 0; i < 10; i += 1 {
-		worker, err := coderun.WorkerWatcher.BorrowWorker()
+		worker, err := coderun.WorkerWatcher.BorrowWorker(context.Background())
 		if err != nil {
 			t.Error(err)
 		}
 
-		out, err := worker.RunScript("dyn_handle", "test_dyn_handle.py", TEST_ARGS_BAD_SCRIPT)
+		out, err := worker.RunScript(context.Background(), "dyn_handle", "test_dyn_handle.py", TEST_ARGS_BAD_SCRIPT)
 		t.Logf("Run output: %s", out)
 		if err != coderun.ErrCodeRunFailed {
 			t.Errorf("Expected `ErrCodeRunFailed` got: %s", err.Error())
@@ -178,12 +180,12 @@ func TestCoderunBadHeaders(t *testing.T) {
 	}
 
 	for i := 0; i < 10; i += 1 {
-		worker, err := coderun.WorkerWatcher.BorrowWorker()
+		worker, err := coderun.WorkerWatcher.BorrowWorker(context.Background())
 		if err != nil {
 			t.Error(err)
 		}
 
-		out, err := worker.RunScript("dyn_handle", "test_dyn_handle.py", TEST_ARGS_BAD_HEADERS)
+		out, err := worker.RunScript(context.Background(), "dyn_handle", "test_dyn_handle.py", TEST_ARGS_BAD_HEADERS)
 		t.Logf("Run output: %s", out)
 		if err != coderun.ErrCodeRunFailed {
 			t.Errorf("Expected `ErrCodeRunFailed` got: %s", err.Error())
@@ -222,12 +224,12 @@ func TestCoderunBadBody(t *testing.T) {
 	}
 
 	for i := 0; i < 10; i += 1 {
-		worker, err := coderun.WorkerWatcher.BorrowWorker()
+		worker, err := coderun.WorkerWatcher.BorrowWorker(context.Background())
 		if err != nil {
 			t.Error(err)
 		}
 
-		out, err := worker.RunScript("dyn_handle", "test_dyn_handle.py", TEST_ARGS_BAD_BODY)
+		out, err := worker.RunScript(context.Background(), "dyn_handle", "test_dyn_handle.py", TEST_ARGS_BAD_BODY)
 		t.Logf("Run output: %s", out)
 		if err != coderun.ErrCodeRunFailed {
 			t.Errorf("Expected `ErrCodeRunFailed` got: %s", err.Error())
@@ -237,3 +239,29 @@ func TestCoderunBadBody(t *testing.T) {
 		worker.Return()
 	}
 }
+
+func TestCoderunBorrowCancelled(t *testing.T) {
+	t.Setenv("CONFIG_PATH", "/configs/test_coderun_config.yaml")
+
+	control.Components.Start()
+	defer control.Components.Stop()
+
+	// the only worker is busy
+	worker, err := coderun.WorkerWatcher.BorrowWorker(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer worker.Return()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := coderun.WorkerWatcher.BorrowWorker(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected `context.DeadlineExceeded` got: %v", err)
+	}
+	// handle timeout of config is 10s
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("borrow waited after caller gave up: %s", elapsed)
+	}
+}
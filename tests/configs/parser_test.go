@@ -0,0 +1,20 @@
+package configs_test
+
+import (
+	"mock-server/internal/configs"
+	"testing"
+)
+
+func TestLoadConfigTwice(t *testing.T) {
+	t.Setenv("CONFIG_PATH", "/configs/test_server_auth_config.yaml")
+	configs.LoadConfig()
+	if configs.GetServerConfig().Auth == nil {
+		t.Fatalf("expected auth section of server config")
+	}
+
+	t.Setenv("CONFIG_PATH", "/configs/test_server_config.yaml")
+	configs.LoadConfig()
+	if configs.GetServerConfig().Auth != nil {
+		t.Errorf("auth section of previously loaded config is kept")
+	}
+}
@@ -0,0 +1,36 @@
+package database_test
+
+import (
+	"context"
+	"mock-server/internal/configs"
+	"mock-server/internal/database"
+	"testing"
+	"time"
+)
+
+func TestExternalDatabaseUnreachable(t *testing.T) {
+	cfg := &configs.DatabaseConfig{
+		URI:            "mongodb://127.0.0.1:1",
+		ConnectTimeout: 500 * time.Millisecond,
+	}
+
+	start := time.Now()
+	if err := database.InitDB(context.Background(), cfg); err == nil {
+		t.Fatalf("connected to unreachable database")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("connect timeout is not respected: %s", elapsed)
+	}
+}
+
+func TestExternalDatabaseBadConfig(t *testing.T) {
+	for _, cfg := range []configs.DatabaseConfig{
+		{},
+		{URI: "not a uri"},
+		{URI: "mongodb://127.0.0.1:1", TLS: &configs.DatabaseTLSConfig{CAFile: "/nonexistent/ca.pem"}},
+	} {
+		if err := database.InitDB(context.Background(), &cfg); err == nil {
+			t.Errorf("database initialized with bad config %+v", cfg)
+		}
+	}
+}
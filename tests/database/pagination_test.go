@@ -0,0 +1,121 @@
+package database_test
+
+import (
+	"context"
+	"fmt"
+	"mock-server/internal/control"
+	"mock-server/internal/database"
+	"reflect"
+	"testing"
+)
+
+func TestTaskMessagesPages(t *testing.T) {
+	t.Setenv("CONFIG_PATH", "/configs/test_database_config.yaml")
+	control.Components.Start()
+	defer control.Components.Stop()
+
+	var expected []string
+	for i := 0; i < 7; i++ {
+		msg := fmt.Sprintf("message %d", i)
+		expected = append(expected, msg)
+		if err := database.AddTaskMessage(context.TODO(), database.TaskMessage{TaskId: "paged", Message: msg}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var actual []string
+	query := database.ListQuery{Limit: 3}
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatalf("too many pages")
+		}
+
+		page, err := database.FindTaskMessages(context.TODO(), "paged", query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		actual = append(actual, page.Items...)
+		if page.NextCursor == "" {
+			break
+		}
+		query.Cursor = page.NextCursor
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("res != expected: %+q != %+q", actual, expected)
+	}
+
+	// descending sort by message with search filter
+	page, err := database.FindTaskMessages(context.TODO(), "paged", database.ListQuery{
+		SortBy: database.MESSAGE_FIELD,
+		Desc:   true,
+		Search: "MESSAGE 1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(page.Items, []string{"message 1"}) || page.NextCursor != "" {
+		t.Errorf("unexpected search page: %+q", page.Items)
+	}
+
+	if _, err := database.FindTaskMessages(context.TODO(), "paged", database.ListQuery{SortBy: "task_id"}); err != database.ErrInvalidSort {
+		t.Errorf("expected invalid sort error, got %v", err)
+	}
+	if _, err := database.FindTaskMessages(context.TODO(), "paged", database.ListQuery{Cursor: "broken"}); err != database.ErrInvalidCursor {
+		t.Errorf("expected invalid cursor error, got %v", err)
+	}
+}
+
+func TestRoutePathsPages(t *testing.T) {
+	t.Setenv("CONFIG_PATH", "/configs/test_database_config.yaml")
+	control.Components.Start()
+	defer control.Components.Stop()
+
+	for _, path := range []string{"/b/2", "/a/1", "/b/1", "/a/2"} {
+		if err := database.AddStaticEndpoint(context.TODO(), path, "ok"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	query := database.ListQuery{Limit: 1, SortBy: database.ROUTE_PATH_FIELD, Prefix: "/b/"}
+	first, err := database.FindRoutePaths(context.TODO(), database.STATIC_ENDPOINT_TYPE, query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query.Cursor = first.NextCursor
+	second, err := database.FindRoutePaths(context.TODO(), database.STATIC_ENDPOINT_TYPE, query)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(first.Items, []string{"/b/1"}) || !reflect.DeepEqual(second.Items, []string{"/b/2"}) {
+		t.Errorf("unexpected pages: %+q, %+q", first.Items, second.Items)
+	}
+	if second.NextCursor != "" {
+		t.Errorf("last page has next cursor")
+	}
+}
+
+func TestRoutesWithoutLimit(t *testing.T) {
+	t.Setenv("CONFIG_PATH", "/configs/test_database_config.yaml")
+	control.Components.Start()
+	defer control.Components.Stop()
+
+	// more routes than default page size of paged api
+	count := database.DEFAULT_PAGE_LIMIT + 20
+	for i := 0; i < count; i++ {
+		if err := database.AddStaticEndpoint(context.TODO(), fmt.Sprintf("/all/%d", i), "ok"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	page, err := database.ListRoutes(context.TODO(), database.ListQuery{Prefix: "/all/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Items) != count {
+		t.Errorf("len(items) != expected: %d != %d", len(page.Items), count)
+	}
+	if page.NextCursor != "" {
+		t.Errorf("unpaged list has next cursor")
+	}
+}
@@ -0,0 +1,144 @@
+package mockctl_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mock-server/pkg/client"
+	"mock-server/pkg/mockserver"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// path of mockctl binary built for tests
+var mockctl string
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "mockctl")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "create build dir: %s\n", err)
+		os.Exit(1)
+	}
+
+	mockctl = filepath.Join(dir, "mockctl")
+	build := exec.Command("go", "build", "-o", mockctl, "mock-server/cmd/mockctl")
+	build.Stderr = os.Stderr
+	if err := build.Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "build mockctl: %s\n", err)
+		os.RemoveAll(dir)
+		os.Exit(1)
+	}
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func runMockctl(t *testing.T, srv *mockserver.Server, args ...string) {
+	t.Helper()
+
+	var stderr bytes.Buffer
+	cmd := exec.Command(mockctl, append([]string{"-addr", srv.URL}, args...)...)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("mockctl %v: %s: %s", args, err, stderr.String())
+	}
+}
+
+// routes created by TestConfigExportImport as json, so they can be compared and printed
+func getRoutes(t *testing.T, cl *client.Client) string {
+	t.Helper()
+	ctx := context.Background()
+
+	var routes []interface{}
+	for _, path := range []string{"/plain", "/negotiated", "/scheduled"} {
+		route, err := cl.GetStaticRoute(ctx, path)
+		if err != nil {
+			t.Fatalf("get static route %s: %s", path, err)
+		}
+		routes = append(routes, route)
+	}
+
+	proxy, err := cl.GetProxyRoute(ctx, "/proxy")
+	if err != nil {
+		t.Fatalf("get proxy route: %s", err)
+	}
+	dynamic, err := cl.GetDynamicRoute(ctx, "/dynamic")
+	if err != nil {
+		t.Fatalf("get dynamic route: %s", err)
+	}
+
+	data, err := json.Marshal(append(routes, proxy, dynamic))
+	if err != nil {
+		t.Fatalf("marshal routes: %s", err)
+	}
+	return string(data)
+}
+
+func TestConfigExportImport(t *testing.T) {
+	ctx := context.Background()
+	source := mockserver.Start(t)
+	cl := source.Client()
+
+	activeFrom := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	activeUntil := activeFrom.Add(time.Hour)
+	expireAt := activeFrom.Add(24 * time.Hour)
+
+	created := []error{
+		cl.CreateStaticRoute(ctx, client.StaticEndpoint{Path: "/plain", ExpectedResponse: "hello"}),
+		cl.CreateStaticRoute(ctx, client.StaticEndpoint{
+			Path: "/negotiated",
+			Representations: []client.Representation{
+				{ContentType: "application/json", Body: `{"msg": "hello"}`},
+				{ContentType: "application/xml", Body: "<msg>hello</msg>"},
+			},
+		}),
+		cl.CreateStaticRoute(ctx, client.StaticEndpoint{
+			Path:             "/scheduled",
+			ExpectedResponse: "later",
+			RouteWindow:      client.RouteWindow{ActiveFrom: &activeFrom, ActiveUntil: &activeUntil},
+		}),
+		cl.CreateProxyRoute(ctx, client.ProxyEndpoint{
+			Path:        "/proxy",
+			ProxyUrl:    "http://127.0.0.1:1/",
+			RouteWindow: client.RouteWindow{ExpireAt: &expireAt},
+		}),
+		cl.CreateDynamicRoute(ctx, client.DynamicEndpoint{
+			Path:        "/dynamic",
+			Code:        "def func(headers, body):\n    return body\n",
+			RouteWindow: client.RouteWindow{ActiveFrom: &activeFrom},
+		}),
+	}
+	for _, err := range created {
+		if err != nil {
+			t.Fatalf("create route: %s", err)
+		}
+	}
+
+	file := filepath.Join(t.TempDir(), "mocks.json")
+	runMockctl(t, source, "config", "export", "-file", file)
+
+	target := mockserver.Start(t)
+	runMockctl(t, target, "config", "import", file)
+
+	expected := getRoutes(t, cl)
+	imported := getRoutes(t, target.Client())
+	if imported != expected {
+		t.Errorf("imported routes differ from exported ones:\n%s\n%s", imported, expected)
+	}
+
+	// existing routes are updated back to exported config with overwrite
+	if err := target.Client().UpdateStaticRoute(ctx, client.StaticEndpoint{Path: "/negotiated", ExpectedResponse: "changed"}); err != nil {
+		t.Fatalf("update static route: %s", err)
+	}
+	runMockctl(t, target, "config", "import", "-overwrite", file)
+
+	imported = getRoutes(t, target.Client())
+	if imported != expected {
+		t.Errorf("overwritten routes differ from exported ones:\n%s\n%s", imported, expected)
+	}
+}
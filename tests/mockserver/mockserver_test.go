@@ -0,0 +1,82 @@
+package mockserver_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mock-server/pkg/client"
+	"mock-server/pkg/mockserver"
+	"net/http"
+	"testing"
+)
+
+func TestEmbeddedServer(t *testing.T) {
+	srv := mockserver.Start(t)
+	cl := srv.Client()
+
+	client.SetupStaticRoute(t, cl, client.StaticEndpoint{Path: "/hello", ExpectedResponse: "world"})
+
+	resp, err := http.Get(srv.URL + "/hello")
+	if err != nil {
+		t.Fatalf("request mock route: %s", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != 200 || string(body) != `"world"` {
+		t.Errorf("unexpected response of mock route: %d %s", resp.StatusCode, body)
+	}
+}
+
+func TestEmbeddedServerRestart(t *testing.T) {
+	// every test gets fresh server without routes of previous one
+	for i := 0; i < 2; i++ {
+		t.Run("run", func(t *testing.T) {
+			cl := mockserver.Start(t).Client()
+
+			paths, err := cl.ListStaticRoutes(context.Background())
+			if err != nil || len(paths) != 0 {
+				t.Fatalf("expected empty server: %v, %v", paths, err)
+			}
+
+			client.SetupStaticRoute(t, cl, client.StaticEndpoint{Path: "/test_url", ExpectedResponse: "hello"})
+		})
+	}
+}
+
+func TestEmbeddedServerAuth(t *testing.T) {
+	srv := mockserver.Start(t, mockserver.WithAdminToken("secret"))
+
+	if _, err := srv.Client().ListStaticRoutes(context.Background()); err != nil {
+		t.Errorf("client of server must be authenticated: %s", err)
+	}
+
+	_, err := client.New(srv.URL).ListStaticRoutes(context.Background())
+	if !errors.Is(err, client.ErrUnauthorized) {
+		t.Errorf("expected unauthorized without token, got %v", err)
+	}
+}
+
+func TestEmbeddedServerParallel(t *testing.T) {
+	// servers keep their own state, so route of one is not served by other
+	for _, response := range []string{"first", "second"} {
+		response := response
+		t.Run(response, func(t *testing.T) {
+			t.Parallel()
+
+			srv := mockserver.Start(t)
+			client.SetupStaticRoute(t, srv.Client(), client.StaticEndpoint{Path: "/shared", ExpectedResponse: response})
+
+			resp, err := http.Get(srv.URL + "/shared")
+			if err != nil {
+				t.Fatalf("request mock route: %s", err)
+			}
+			defer resp.Body.Close()
+
+			body, _ := io.ReadAll(resp.Body)
+			if string(body) != `"`+response+`"` {
+				t.Errorf("route of other server is served: %s", body)
+			}
+		})
+	}
+}
@@ -0,0 +1,305 @@
+package server_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"mock-server/internal/configs"
+	"mock-server/internal/control"
+	"mock-server/internal/server/protocol"
+	"net/http"
+	"testing"
+)
+
+func parseApiResponse(body []byte, t *testing.T) protocol.ApiResponse {
+	var resp protocol.ApiResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatalf("failed to parse envelope %s: %s", body, err)
+	}
+	return resp
+}
+
+// status and parsed body of request, body is empty if response has none
+func doApiV2Request(method string, url string, contentType string, content []byte, t *testing.T) (int, protocol.ApiResponse) {
+	req, err := http.NewRequest(method, url, bytes.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(body) == 0 {
+		return resp.StatusCode, protocol.ApiResponse{}
+	}
+	return resp.StatusCode, parseApiResponse(body, t)
+}
+
+func TestApiV2ErrorEnvelope(t *testing.T) {
+	t.Setenv("CONFIG_PATH", "/configs/test_server_config.yaml")
+
+	control.Components.Start()
+	defer control.Components.Stop()
+
+	cfg := configs.GetServerConfig()
+	endpoint := fmt.Sprintf("http://%s", cfg.Addr)
+
+	// validation error carries failed fields
+	code, body := DoPost(endpoint+"/api/v2/routes/static", []byte(`{"path": "no_slash"}`), t)
+	if code != 400 {
+		t.Fatalf("status code %d != 400: %s", code, body)
+	}
+	resp := parseApiResponse(body, t)
+	if resp.Error == nil || resp.Error.Code != protocol.ERR_CODE_VALIDATION_FAILED || resp.Error.Details == nil {
+		t.Errorf("unexpected validation error: %s", body)
+	}
+
+	code, body = DoPost(endpoint+"/api/v2/routes/static", []byte(`{"path": "/v2/test", "expected_response": "ok"}`), t)
+	if code != 200 {
+		t.Fatalf("status code %d != 200: %s", code, body)
+	}
+	if resp := parseApiResponse(body, t); resp.Error != nil || resp.Data == nil {
+		t.Errorf("unexpected create response: %s", body)
+	}
+
+	code, body = DoPost(endpoint+"/api/v2/routes/static", []byte(`{"path": "/v2/test", "expected_response": "ok"}`), t)
+	if code != 409 {
+		t.Errorf("status code %d != 409: %s", code, body)
+	}
+	if resp := parseApiResponse(body, t); resp.Error == nil || resp.Error.Code != protocol.ERR_CODE_ALREADY_EXISTS {
+		t.Errorf("unexpected duplicate error: %s", body)
+	}
+
+	code, body = DoGet(endpoint+"/api/v2/routes/static", t)
+	if code != 200 {
+		t.Fatalf("status code %d != 200: %s", code, body)
+	}
+	var listed struct {
+		Data struct {
+			Endpoints []string `json:"endpoints"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &listed); err != nil || len(listed.Data.Endpoints) != 1 {
+		t.Errorf("unexpected list response: %s", body)
+	}
+
+	if code := DoDelete(endpoint+"/api/v2/routes/static?path=/v2/test", t); code != 204 {
+		t.Errorf("status code %d != 204", code)
+	}
+
+	code, body = DoGet(endpoint+"/api/v2/routes/static/expected_response?path=/v2/test", t)
+	if code != 404 {
+		t.Errorf("status code %d != 404: %s", code, body)
+	}
+	if resp := parseApiResponse(body, t); resp.Error == nil || resp.Error.Code != protocol.ERR_CODE_ROUTE_NOT_FOUND {
+		t.Errorf("unexpected not found error: %s", body)
+	}
+
+	code, body = DoGet(endpoint+"/api/v2/brokers/pool/config?pool=missing", t)
+	if code != 404 {
+		t.Errorf("status code %d != 404: %s", code, body)
+	}
+	if resp := parseApiResponse(body, t); resp.Error == nil || resp.Error.Code != protocol.ERR_CODE_POOL_NOT_FOUND {
+		t.Errorf("unexpected pool error: %s", body)
+	}
+
+	// v1 responses are kept as is
+	code, body = DoGet(endpoint+"/api/routes/static/expected_response?path=/v2/test", t)
+	if code != 404 || string(body) != `{"error":"Received path was not created before"}` {
+		t.Errorf("v1 response changed %d: %s", code, body)
+	}
+}
+
+// forms of all paths have the same content type
+func multipartFileForm(path string, t *testing.T) (string, []byte) {
+	var content bytes.Buffer
+	form := multipart.NewWriter(&content)
+	if err := form.SetBoundary("mock-server-file-form"); err != nil {
+		t.Fatal(err)
+	}
+	form.WriteField("path", path)
+	part, err := form.CreateFormFile("file", "report.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write([]byte(`{"report": [1, 2, 3]}`))
+	form.Close()
+	return form.FormDataContentType(), content.Bytes()
+}
+
+func TestApiV2ErrorCodes(t *testing.T) {
+	t.Setenv("CONFIG_PATH", "/configs/test_server_pool_api_config.yaml")
+
+	control.Components.Start()
+	defer control.Components.Stop()
+	defer removeAllMessagePools(t)
+
+	cfg := configs.GetServerConfig()
+	endpoint := fmt.Sprintf("http://%s", cfg.Addr)
+
+	fileContentType, fileForm := multipartFileForm("/codes/file", t)
+	_, missingFileForm := multipartFileForm("/codes/missing", t)
+
+	// every resource answers duplicate create with 409 and request on missing key with 404
+	resources := []struct {
+		name         string
+		api          string
+		contentType  string
+		create       []byte
+		created      string
+		missing      string
+		update       []byte // of missing key, get with missing key if nil
+		notFoundCode string
+	}{
+		{
+			name:         "static",
+			api:          "/routes/static",
+			create:       []byte(`{"path": "/codes/static", "expected_response": "ok"}`),
+			created:      "path=/codes/static",
+			missing:      "path=/codes/missing",
+			update:       []byte(`{"path": "/codes/missing", "expected_response": "ok"}`),
+			notFoundCode: protocol.ERR_CODE_ROUTE_NOT_FOUND,
+		},
+		{
+			name:         "proxy",
+			api:          "/routes/proxy",
+			create:       []byte(`{"path": "/codes/proxy", "proxy_url": "http://localhost:1337/api/ping"}`),
+			created:      "path=/codes/proxy",
+			missing:      "path=/codes/missing",
+			update:       []byte(`{"path": "/codes/missing", "proxy_url": "http://localhost:1337/api/ping"}`),
+			notFoundCode: protocol.ERR_CODE_ROUTE_NOT_FOUND,
+		},
+		{
+			name:         "dynamic",
+			api:          "/routes/dynamic",
+			create:       []byte(`{"path": "/codes/dynamic", "code": "def func(headers, body):\n    return 'ok'"}`),
+			created:      "path=/codes/dynamic",
+			missing:      "path=/codes/missing",
+			update:       []byte(`{"path": "/codes/missing", "code": "def func(headers, body):\n    return 'ok'"}`),
+			notFoundCode: protocol.ERR_CODE_ROUTE_NOT_FOUND,
+		},
+		{
+			name:         "graphql",
+			api:          "/routes/graphql",
+			create:       []byte(`{"path": "/codes/graphql", "operations": [{"operation_name": "GetUser", "response": {"user": {"name": "anyone"}}}]}`),
+			created:      "path=/codes/graphql",
+			missing:      "path=/codes/missing",
+			update:       []byte(`{"path": "/codes/missing", "operations": [{"operation_name": "GetUser", "response": {"user": {"name": "anyone"}}}]}`),
+			notFoundCode: protocol.ERR_CODE_ROUTE_NOT_FOUND,
+		},
+		{
+			name:         "websocket",
+			api:          "/routes/websocket",
+			create:       []byte(`{"path": "/codes/websocket", "replies": [{"match": "ping", "response": "pong"}]}`),
+			created:      "path=/codes/websocket",
+			missing:      "path=/codes/missing",
+			update:       []byte(`{"path": "/codes/missing", "replies": [{"match": "ping", "response": "pong"}]}`),
+			notFoundCode: protocol.ERR_CODE_ROUTE_NOT_FOUND,
+		},
+		{
+			name:         "stream",
+			api:          "/routes/stream",
+			create:       []byte(`{"path": "/codes/stream", "mode": "sse", "events": [{"data": "hello"}]}`),
+			created:      "path=/codes/stream",
+			missing:      "path=/codes/missing",
+			update:       []byte(`{"path": "/codes/missing", "mode": "sse", "events": [{"data": "hello"}]}`),
+			notFoundCode: protocol.ERR_CODE_ROUTE_NOT_FOUND,
+		},
+		{
+			name:         "file",
+			api:          "/routes/file",
+			contentType:  fileContentType,
+			create:       fileForm,
+			created:      "path=/codes/file",
+			missing:      "path=/codes/missing",
+			update:       missingFileForm,
+			notFoundCode: protocol.ERR_CODE_ROUTE_NOT_FOUND,
+		},
+		{
+			name:         "pool",
+			api:          "/brokers/pool",
+			create:       []byte(`{"pool_name": "codes_pool", "queue_name": "codes_queue", "broker": "rabbitmq"}`),
+			created:      "pool=codes_pool",
+			missing:      "pool=missing",
+			notFoundCode: protocol.ERR_CODE_POOL_NOT_FOUND,
+		},
+		{
+			name:         "esb",
+			api:          "/brokers/esb",
+			create:       []byte(`{"pool_name_in": "codes_in", "pool_name_out": "codes_out"}`),
+			created:      "pool_in=codes_in",
+			missing:      "pool_in=missing",
+			update:       []byte(`{"pool_name_in": "missing", "pool_name_out": "codes_out"}`),
+			notFoundCode: protocol.ERR_CODE_RECORD_NOT_FOUND,
+		},
+		{
+			name:         "namespace",
+			api:          "/namespaces",
+			create:       []byte(`{"prefix": "/codes"}`),
+			created:      "prefix=/codes",
+			missing:      "prefix=/missing",
+			update:       []byte(`{"prefix": "/missing"}`),
+			notFoundCode: protocol.ERR_CODE_NAMESPACE_NOT_FOUND,
+		},
+	}
+
+	for _, resource := range resources {
+		t.Run(resource.name, func(t *testing.T) {
+			contentType := resource.contentType
+			if contentType == "" {
+				contentType = "application/json"
+			}
+			url := endpoint + "/api/v2" + resource.api
+
+			if code, resp := doApiV2Request(http.MethodPost, url, contentType, resource.create, t); code != 200 {
+				t.Fatalf("create status code %d != 200: %+v", code, resp.Error)
+			}
+
+			code, resp := doApiV2Request(http.MethodPost, url, contentType, resource.create, t)
+			if code != 409 {
+				t.Errorf("duplicate status code %d != 409", code)
+			}
+			if resp.Error == nil || resp.Error.Code != protocol.ERR_CODE_ALREADY_EXISTS {
+				t.Errorf("unexpected duplicate error: %+v", resp.Error)
+			}
+
+			if resource.update != nil {
+				code, resp = doApiV2Request(http.MethodPut, url, contentType, resource.update, t)
+			} else {
+				code, resp = doApiV2Request(http.MethodGet, url+"/config?"+resource.missing, "", nil, t)
+			}
+			if code != 404 {
+				t.Errorf("status code of missing %d != 404", code)
+			}
+			if resp.Error == nil || resp.Error.Code != resource.notFoundCode {
+				t.Errorf("unexpected not found error: %+v", resp.Error)
+			}
+
+			// status of delete of missing key is the one of v1
+			v1Code := DoDelete(endpoint+"/api"+resource.api+"?"+resource.missing, t)
+			code, resp = doApiV2Request(http.MethodDelete, url+"?"+resource.missing, "", nil, t)
+			if code != v1Code {
+				t.Errorf("delete of missing status code %d != %d of v1", code, v1Code)
+			}
+			if code == 404 && (resp.Error == nil || resp.Error.Code != resource.notFoundCode) {
+				t.Errorf("unexpected not found error of delete: %+v", resp.Error)
+			}
+
+			if code, resp := doApiV2Request(http.MethodDelete, url+"?"+resource.created, "", nil, t); code != 204 {
+				t.Errorf("delete status code %d != 204: %+v", code, resp.Error)
+			}
+		})
+	}
+}
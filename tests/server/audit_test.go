@@ -0,0 +1,84 @@
+package server_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"mock-server/internal/configs"
+	"mock-server/internal/control"
+	"mock-server/internal/server/protocol"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestAuditLog(t *testing.T) {
+	t.Setenv("CONFIG_PATH", "/configs/test_server_config.yaml")
+
+	control.Components.Start()
+	defer control.Components.Stop()
+
+	cfg := configs.GetServerConfig()
+	endpoint := fmt.Sprintf("http://%s", cfg.Addr)
+	since := time.Now().Add(-time.Second).UTC().Format(time.RFC3339)
+
+	if code, body := DoPost(endpoint+"/api/routes/static", []byte(`{"path": "/audited", "expected_response": "first"}`), t); code != 200 {
+		t.Fatalf("status code %d != 200: %s", code, body)
+	}
+	if code := DoPut(endpoint+"/api/routes/static", []byte(`{"path": "/audited", "expected_response": "second"}`), t); code != 204 {
+		t.Fatalf("status code %d != 204", code)
+	}
+	// failed change is not recorded
+	if code, _ := DoPost(endpoint+"/api/routes/static", []byte(`{"path": "/audited", "expected_response": "again"}`), t); code != 409 {
+		t.Fatalf("status code %d != 409", code)
+	}
+	if code := DoDelete(endpoint+"/api/routes/static?path=/audited", t); code != 204 {
+		t.Fatalf("status code %d != 204", code)
+	}
+	if code, body := DoPost(endpoint+"/api/routes/static", []byte(`{"path": "/not_audited", "expected_response": "other"}`), t); code != 200 {
+		t.Fatalf("status code %d != 200: %s", code, body)
+	}
+
+	query := url.Values{"resource": {"route"}, "key": {"/audited"}, "since": {since}}
+	code, body := DoGet(endpoint+"/api/audit?"+query.Encode(), t)
+	if code != 200 {
+		t.Fatalf("status code %d != 200: %s", code, body)
+	}
+
+	var resp struct {
+		Records []protocol.AuditRecord `json:"records"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Records) != 3 {
+		t.Fatalf("expected 3 records: %s", body)
+	}
+
+	create, update, remove := resp.Records[0], resp.Records[1], resp.Records[2]
+	if create.Operation != "create" || create.Before != nil || create.After == nil || create.IP == "" {
+		t.Errorf("unexpected create record: %+v", create)
+	}
+	if update.Operation != "update" || update.Status != 204 || update.Before == nil || update.After == nil {
+		t.Errorf("unexpected update record: %+v", update)
+	}
+	if remove.Operation != "delete" || remove.Before == nil || remove.After != nil {
+		t.Errorf("unexpected delete record: %+v", remove)
+	}
+
+	var before, after struct {
+		Response string `json:"response"`
+	}
+	if err := json.Unmarshal(update.Before, &before); err != nil || before.Response != "first" {
+		t.Errorf("unexpected state before update: %s", update.Before)
+	}
+	if err := json.Unmarshal(update.After, &after); err != nil || after.Response != "second" {
+		t.Errorf("unexpected state after update: %s", update.After)
+	}
+
+	// time range excludes all records
+	query.Set("until", since)
+	code, body = DoGet(endpoint+"/api/audit?"+query.Encode(), t)
+	if err := json.Unmarshal(body, &resp); err != nil || code != 200 || len(resp.Records) != 0 {
+		t.Errorf("unexpected response of empty range %d: %s", code, body)
+	}
+}
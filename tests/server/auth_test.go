@@ -0,0 +1,98 @@
+package server_test
+
+import (
+	"fmt"
+	"mock-server/internal/configs"
+	"mock-server/internal/control"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type authFunc func(req *http.Request)
+
+func bearerAuth(token string) authFunc {
+	return func(req *http.Request) {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+}
+
+func basicAuth(username string, password string) authFunc {
+	return func(req *http.Request) {
+		req.SetBasicAuth(username, password)
+	}
+}
+
+func DoWithAuth(method string, url string, body string, auth authFunc, t *testing.T) int {
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Error(err)
+		return 0
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if auth != nil {
+		auth(req)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Error(err)
+		return 0
+	}
+	defer resp.Body.Close()
+
+	return resp.StatusCode
+}
+
+func TestAdminApiAuth(t *testing.T) {
+	t.Setenv("CONFIG_PATH", "/configs/test_server_auth_config.yaml")
+
+	control.Components.Start()
+	defer control.Components.Stop()
+
+	cfg := configs.GetServerConfig()
+	endpoint := fmt.Sprintf("http://%s", cfg.Addr)
+	staticApiEndpoint := endpoint + "/api/routes/static"
+	routeBody := `{"path": "/test_url", "expected_response": "hello"}`
+
+	//////////////////////////////////////////////////////
+
+	// ping stays open
+	if code := DoWithAuth(http.MethodGet, endpoint+"/api/ping", "", nil, t); code != 200 {
+		t.Errorf("expected ping without auth: %d", code)
+	}
+
+	// no or wrong credentials -> 401
+	if code := DoWithAuth(http.MethodGet, staticApiEndpoint, "", nil, t); code != 401 {
+		t.Errorf("expected 401 without credentials: %d", code)
+	}
+	if code := DoWithAuth(http.MethodGet, staticApiEndpoint, "", bearerAuth("wrong"), t); code != 401 {
+		t.Errorf("expected 401 on wrong token: %d", code)
+	}
+	if code := DoWithAuth(http.MethodGet, staticApiEndpoint, "", basicAuth("operator", "wrong"), t); code != 401 {
+		t.Errorf("expected 401 on wrong password: %d", code)
+	}
+
+	// read only role can list, but not modify -> 403
+	if code := DoWithAuth(http.MethodGet, staticApiEndpoint, "", bearerAuth("viewer-token"), t); code != 200 {
+		t.Errorf("expected read only role to list routes: %d", code)
+	}
+	if code := DoWithAuth(http.MethodPost, staticApiEndpoint, routeBody, bearerAuth("viewer-token"), t); code != 403 {
+		t.Errorf("expected 403 on modification by read only role: %d", code)
+	}
+
+	// admin role by token and by basic auth
+	if code := DoWithAuth(http.MethodPost, staticApiEndpoint, routeBody, bearerAuth("admin-token"), t); code != 200 {
+		t.Errorf("expected admin token to create route: %d", code)
+	}
+
+	// mock traffic is not authenticated
+	code, _ := DoGet(endpoint+"/test_url", t)
+	if code != 200 {
+		t.Errorf("expected mock to be open: %d", code)
+	}
+
+	if code := DoWithAuth(http.MethodDelete, staticApiEndpoint+"?path=/test_url", "", basicAuth("operator", "secret"), t); code != 204 {
+		t.Errorf("expected admin user to delete route: %d", code)
+	}
+}
@@ -0,0 +1,134 @@
+package server_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"mock-server/internal/configs"
+	"mock-server/internal/control"
+	"mock-server/internal/server/protocol"
+	"testing"
+)
+
+func TestBatchApplied(t *testing.T) {
+	t.Setenv("CONFIG_PATH", "/configs/test_server_config.yaml")
+
+	control.Components.Start()
+	defer control.Components.Stop()
+
+	cfg := configs.GetServerConfig()
+	endpoint := fmt.Sprintf("http://%s", cfg.Addr)
+
+	if code, body := DoPost(endpoint+"/api/routes/static", []byte(`{"path": "/batch/old", "expected_response": "old"}`), t); code != 200 {
+		t.Fatalf("status code %d != 200: %s", code, body)
+	}
+
+	batch := []byte(`{"operations": [
+		{"op": "create", "kind": "static", "body": {"path": "/batch/new", "expected_response": "new"}},
+		{"op": "update", "kind": "static", "body": {"path": "/batch/new", "expected_response": "updated"}},
+		{"op": "delete", "kind": "static", "key": "/batch/old"}
+	]}`)
+	code, body := DoPost(endpoint+"/api/batch", batch, t)
+	if code != 200 {
+		t.Fatalf("status code %d != 200: %s", code, body)
+	}
+
+	var resp protocol.BatchResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Applied || len(resp.Results) != 3 {
+		t.Fatalf("unexpected response: %s", body)
+	}
+	for _, result := range resp.Results {
+		if result.State != protocol.BATCH_STATE_APPLIED {
+			t.Errorf("operation is not applied: %+v", result)
+		}
+	}
+
+	if code, body := DoGet(endpoint+"/batch/new", t); code != 200 || string(body) != `"updated"` {
+		t.Errorf("unexpected created route response %d: %s", code, body)
+	}
+	if code, _ := DoGet(endpoint+"/batch/old", t); code != 400 {
+		t.Errorf("deleted route status code %d != 400", code)
+	}
+}
+
+func TestBatchRolledBack(t *testing.T) {
+	t.Setenv("CONFIG_PATH", "/configs/test_server_config.yaml")
+
+	control.Components.Start()
+	defer control.Components.Stop()
+
+	cfg := configs.GetServerConfig()
+	endpoint := fmt.Sprintf("http://%s", cfg.Addr)
+
+	if code, body := DoPost(endpoint+"/api/routes/static", []byte(`{"path": "/batch/kept", "expected_response": "kept"}`), t); code != 200 {
+		t.Fatalf("status code %d != 200: %s", code, body)
+	}
+
+	// last operation conflicts with existing route
+	batch := []byte(`{"operations": [
+		{"op": "create", "kind": "static", "body": {"path": "/batch/created", "expected_response": "created"}},
+		{"op": "update", "kind": "static", "body": {"path": "/batch/kept", "expected_response": "changed"}},
+		{"op": "create", "kind": "static", "body": {"path": "/batch/kept", "expected_response": "again"}},
+		{"op": "delete", "kind": "static", "key": "/batch/kept"}
+	]}`)
+	code, body := DoPost(endpoint+"/api/batch", batch, t)
+	if code != 409 {
+		t.Fatalf("status code %d != 409: %s", code, body)
+	}
+
+	var resp protocol.BatchResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Applied || resp.Error == "" || len(resp.Results) != 4 {
+		t.Fatalf("unexpected response: %s", body)
+	}
+	expectedStates := []string{
+		protocol.BATCH_STATE_ROLLED_BACK,
+		protocol.BATCH_STATE_ROLLED_BACK,
+		protocol.BATCH_STATE_FAILED,
+		protocol.BATCH_STATE_SKIPPED,
+	}
+	for i, state := range expectedStates {
+		if resp.Results[i].State != state {
+			t.Errorf("operation %d state %s != %s", i, resp.Results[i].State, state)
+		}
+	}
+
+	if code, _ := DoGet(endpoint+"/batch/created", t); code != 400 {
+		t.Errorf("created route status code %d != 400", code)
+	}
+	if code, body := DoGet(endpoint+"/batch/kept", t); code != 200 || string(body) != `"kept"` {
+		t.Errorf("unexpected kept route response %d: %s", code, body)
+	}
+}
+
+func TestBatchInvalidOperation(t *testing.T) {
+	t.Setenv("CONFIG_PATH", "/configs/test_server_config.yaml")
+
+	control.Components.Start()
+	defer control.Components.Stop()
+
+	cfg := configs.GetServerConfig()
+	endpoint := fmt.Sprintf("http://%s", cfg.Addr)
+
+	batch := []byte(`{"operations": [
+		{"op": "create", "kind": "static", "body": {"path": "/batch/valid", "expected_response": "valid"}},
+		{"op": "update", "kind": "pool", "body": {"pool_name": "pool", "broker": "kafka"}}
+	]}`)
+	code, body := DoPost(endpoint+"/api/v2/batch", batch, t)
+	if code != 400 {
+		t.Fatalf("status code %d != 400: %s", code, body)
+	}
+
+	resp := parseApiResponse(body, t)
+	if resp.Error == nil || resp.Error.Code != protocol.ERR_CODE_BATCH_FAILED || resp.Error.Details == nil {
+		t.Errorf("unexpected error: %s", body)
+	}
+
+	if code, _ := DoGet(endpoint+"/batch/valid", t); code != 400 {
+		t.Errorf("route of invalid batch status code %d != 400", code)
+	}
+}
@@ -0,0 +1,123 @@
+package server_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"mock-server/internal/configs"
+	"mock-server/internal/control"
+	"mock-server/internal/server/protocol"
+	"testing"
+)
+
+func TestChaosProfile(t *testing.T) {
+	t.Setenv("CONFIG_PATH", "/configs/test_server_config.yaml")
+
+	control.Components.Start()
+	defer control.Components.Stop()
+
+	cfg := configs.GetServerConfig()
+	endpoint := fmt.Sprintf("http://%s", cfg.Addr)
+
+	if code, body := DoPost(endpoint+"/api/routes/static", []byte(`{"path": "/chaotic", "expected_response": "ok"}`), t); code != 200 {
+		t.Fatalf("status code %d != 200: %s", code, body)
+	}
+
+	if code := DoPut(endpoint+"/api/chaos", []byte(`{"prefix": "/unknown", "error_percent": 100}`), t); code != 404 {
+		t.Fatalf("status code %d != 404 for unexisting namespace", code)
+	}
+	// write tasks are not bound to namespaces
+	if code := DoPut(endpoint+"/api/chaos", []byte(`{"prefix": "/unknown", "drop_write_percent": 10}`), t); code != 400 {
+		t.Fatalf("status code %d != 400 for namespace drop of writes", code)
+	}
+	if code := DoPut(endpoint+"/api/chaos", []byte(`{"error_percent": 100, "error_statuses": [404]}`), t); code != 400 {
+		t.Fatalf("status code %d != 400 for non 5xx status", code)
+	}
+
+	if code := DoPut(endpoint+"/api/chaos", []byte(`{"error_percent": 100, "error_statuses": [503], "seed": 7}`), t); code != 200 {
+		t.Fatalf("status code %d != 200", code)
+	}
+
+	code, body := DoGet(endpoint+"/api/chaos", t)
+	if code != 200 {
+		t.Fatalf("status code %d != 200: %s", code, body)
+	}
+	var resp struct {
+		Profiles []protocol.ChaosProfile `json:"profiles"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil || len(resp.Profiles) != 1 || resp.Profiles[0].Seed != 7 {
+		t.Fatalf("unexpected profiles: %s", body)
+	}
+
+	if code, body := DoGet(endpoint+"/chaotic", t); code != 503 {
+		t.Fatalf("status code %d != 503: %s", code, body)
+	}
+	// admin api is not affected
+	if code, _ := DoGet(endpoint+"/api/chaos", t); code != 200 {
+		t.Fatalf("admin api failed by chaos profile: %d", code)
+	}
+
+	if code := DoDelete(endpoint+"/api/chaos", t); code != 204 {
+		t.Fatalf("status code %d != 204", code)
+	}
+	if code := DoDelete(endpoint+"/api/chaos", t); code != 404 {
+		t.Fatalf("status code %d != 404 for disabled profile", code)
+	}
+
+	if code, body := DoGet(endpoint+"/chaotic", t); code != 200 || string(body) != `"ok"` {
+		t.Fatalf("unexpected response after disabling chaos: %d %s", code, body)
+	}
+}
+
+func listChaosProfiles(endpoint string, t *testing.T) []protocol.ChaosProfile {
+	code, body := DoGet(endpoint+"/api/chaos", t)
+	if code != 200 {
+		t.Fatalf("status code %d != 200: %s", code, body)
+	}
+	var resp struct {
+		Profiles []protocol.ChaosProfile `json:"profiles"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatalf("unexpected profiles: %s", body)
+	}
+	return resp.Profiles
+}
+
+func TestChaosProfileCleared(t *testing.T) {
+	t.Setenv("CONFIG_PATH", "/configs/test_server_config.yaml")
+
+	control.Components.Start()
+
+	cfg := configs.GetServerConfig()
+	endpoint := fmt.Sprintf("http://%s", cfg.Addr)
+
+	if code, body := DoPost(endpoint+"/api/namespaces", []byte(`{"prefix": "/flaky"}`), t); code != 200 {
+		t.Fatalf("status code %d != 200: %s", code, body)
+	}
+	if code := DoPut(endpoint+"/api/chaos", []byte(`{"prefix": "/flaky", "error_percent": 100}`), t); code != 200 {
+		t.Fatalf("status code %d != 200", code)
+	}
+
+	// namespace created again with the same prefix has no profile
+	if code := DoDelete(endpoint+"/api/namespaces?prefix=/flaky", t); code != 204 {
+		t.Fatalf("status code %d != 204", code)
+	}
+	if code, body := DoPost(endpoint+"/api/namespaces", []byte(`{"prefix": "/flaky"}`), t); code != 200 {
+		t.Fatalf("status code %d != 200: %s", code, body)
+	}
+	if profiles := listChaosProfiles(endpoint, t); len(profiles) != 0 {
+		t.Errorf("profile of removed namespace is kept: %+v", profiles)
+	}
+
+	// profiles are not kept after restart
+	if code := DoPut(endpoint+"/api/chaos", []byte(`{"error_percent": 100}`), t); code != 200 {
+		t.Fatalf("status code %d != 200", code)
+	}
+	control.Components.Stop()
+
+	control.Components.Start()
+	defer control.Components.Stop()
+
+	if profiles := listChaosProfiles(endpoint, t); len(profiles) != 0 {
+		t.Errorf("profiles are kept after restart: %+v", profiles)
+	}
+}
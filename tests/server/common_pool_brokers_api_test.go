@@ -225,7 +225,7 @@ func removeAllMessagePools(t *testing.T) {
 	}
 
 	for _, pool := range pools {
-		if err := brokers.RemoveMessagePool(pool.Name); err != nil {
+		if err := brokers.RemoveMessagePool(context.TODO(), pool.Name); err != nil {
 			t.Errorf("failed to remove message pool %s: %s", pool.Name, err.Error())
 		}
 	}
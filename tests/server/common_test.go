@@ -6,7 +6,10 @@ import (
 	"io"
 	"mock-server/internal/configs"
 	"mock-server/internal/control"
+	"mock-server/internal/util"
 	"net/http"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -224,3 +227,16 @@ func DoDelete(url string, t *testing.T) int {
 
 	return resp.StatusCode
 }
+
+// scripts of dynamic handlers kept in file storage of the server
+func CountScripts(t *testing.T) int {
+	root, err := util.FileStorageRoot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := os.ReadDir(filepath.Join(root, "coderun", "dyn_handle"))
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	return len(entries)
+}
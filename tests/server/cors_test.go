@@ -0,0 +1,167 @@
+package server_test
+
+import (
+	"fmt"
+	"mock-server/internal/configs"
+	"mock-server/internal/control"
+	"net/http"
+	"testing"
+)
+
+func DoPreflight(url string, origin string, t *testing.T) *http.Response {
+	req, err := http.NewRequest(http.MethodOptions, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Origin", origin)
+	req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp
+}
+
+func TestCorsPolicies(t *testing.T) {
+	t.Setenv("CONFIG_PATH", "/configs/test_server_config.yaml")
+
+	control.Components.Start()
+	defer control.Components.Stop()
+
+	cfg := configs.GetServerConfig()
+	endpoint := fmt.Sprintf("http://%s", cfg.Addr)
+	staticApiEndpoint := endpoint + "/api/routes/static"
+	corsPolicyEndpoint := endpoint + "/api/routes/policy/cors"
+	namespacesEndpoint := endpoint + "/api/namespaces"
+
+	//////////////////////////////////////////////////////
+
+	for _, path := range []string{"/shop/items", "/shop/cart"} {
+		code, _ := DoPost(staticApiEndpoint, []byte(fmt.Sprintf(`{
+			"path": "%s",
+			"expected_response": "ok"
+		}`, path)), t)
+		if code != 200 {
+			t.Errorf("create route failed: %d", code)
+		}
+	}
+
+	// no policy -> no cors headers on mocks
+	resp := DoPreflight(endpoint+"/shop/items", "http://front.local", t)
+	if origin := resp.Header.Get("Access-Control-Allow-Origin"); origin != "" {
+		t.Errorf("expected no cors headers without policy: %s", origin)
+	}
+
+	// admin api keeps its own policy
+	resp = DoPreflight(staticApiEndpoint, "http://front.local", t)
+	if resp.StatusCode != 204 || resp.Header.Get("Access-Control-Allow-Origin") != "*" {
+		t.Errorf("admin api preflight failed: %d %v", resp.StatusCode, resp.Header)
+	}
+
+	// namespace policy covers all routes under prefix
+	code, _ := DoPost(namespacesEndpoint, []byte(`{
+		"prefix": "/shop",
+		"cors": {"allow_origins": ["http://front.local"], "allow_methods": ["GET", "POST"], "max_age_sec": 60}
+	}`), t)
+	if code != 200 {
+		t.Errorf("create namespace failed: %d", code)
+	}
+
+	resp = DoPreflight(endpoint+"/shop/cart", "http://front.local", t)
+	if resp.StatusCode != 204 {
+		t.Errorf("expected 204 on preflight: %d", resp.StatusCode)
+	}
+	if origin := resp.Header.Get("Access-Control-Allow-Origin"); origin != "http://front.local" {
+		t.Errorf("allowed origin mismatch: %s != http://front.local", origin)
+	}
+	if methods := resp.Header.Get("Access-Control-Allow-Methods"); methods != "GET,POST" {
+		t.Errorf("allowed methods mismatch: %s != GET,POST", methods)
+	}
+	if maxAge := resp.Header.Get("Access-Control-Max-Age"); maxAge != "60" {
+		t.Errorf("max age mismatch: %s != 60", maxAge)
+	}
+
+	resp = DoPreflight(endpoint+"/shop/cart", "http://evil.local", t)
+	if resp.StatusCode != 403 {
+		t.Errorf("expected 403 on forbidden origin: %d", resp.StatusCode)
+	}
+
+	// route policy takes precedence over namespace one
+	code = DoPut(corsPolicyEndpoint, []byte(`{
+		"path": "/shop/items",
+		"cors": {"allow_origins": ["http://evil.local"], "allow_credentials": true}
+	}`), t)
+	if code != 204 {
+		t.Errorf("set route policy failed: %d", code)
+	}
+
+	resp = DoPreflight(endpoint+"/shop/items", "http://evil.local", t)
+	if resp.StatusCode != 204 || resp.Header.Get("Access-Control-Allow-Credentials") != "true" {
+		t.Errorf("route policy was not applied: %d %v", resp.StatusCode, resp.Header)
+	}
+
+	resp = DoPreflight(endpoint+"/shop/cart", "http://evil.local", t)
+	if resp.StatusCode != 403 {
+		t.Errorf("route policy must not affect other routes: %d", resp.StatusCode)
+	}
+
+	// origin with several wildcards is rejected, previous policy is kept
+	code = DoPut(corsPolicyEndpoint, []byte(`{
+		"path": "/shop/items",
+		"cors": {"allow_origins": ["http://*.*.local"]}
+	}`), t)
+	if code != 400 {
+		t.Errorf("expected 400 on invalid policy: %d", code)
+	}
+
+	resp = DoPreflight(endpoint+"/shop/items", "http://evil.local", t)
+	if resp.StatusCode != 204 {
+		t.Errorf("previous route policy must be kept: %d", resp.StatusCode)
+	}
+
+	// updated policy is applied to next requests
+	code = DoPut(corsPolicyEndpoint, []byte(`{
+		"path": "/shop/items",
+		"cors": {"allow_origins": ["http://*.front.local"]}
+	}`), t)
+	if code != 204 {
+		t.Errorf("update route policy failed: %d", code)
+	}
+
+	resp = DoPreflight(endpoint+"/shop/items", "http://admin.front.local", t)
+	if resp.StatusCode != 204 || resp.Header.Get("Access-Control-Allow-Origin") != "http://admin.front.local" {
+		t.Errorf("updated route policy was not applied: %d %v", resp.StatusCode, resp.Header)
+	}
+
+	resp = DoPreflight(endpoint+"/shop/items", "http://evil.local", t)
+	if resp.StatusCode != 403 {
+		t.Errorf("expected 403 on origin of previous policy: %d", resp.StatusCode)
+	}
+
+	// policy on unexisting route -> 404
+	code = DoPut(corsPolicyEndpoint, []byte(`{
+		"path": "/unknown",
+		"cors": {"allow_origins": ["*"]}
+	}`), t)
+	if code != 404 {
+		t.Errorf("expected 404 on unexisting route: %d", code)
+	}
+
+	// route falls back to namespace policy after removal
+	code = DoDelete(corsPolicyEndpoint+"?path=/shop/items", t)
+	if code != 204 {
+		t.Errorf("remove route policy failed: %d", code)
+	}
+
+	resp = DoPreflight(endpoint+"/shop/items", "http://evil.local", t)
+	if resp.StatusCode != 403 {
+		t.Errorf("expected namespace policy after route policy removal: %d", resp.StatusCode)
+	}
+
+	code = DoDelete(namespacesEndpoint+"?prefix=/shop", t)
+	if code != 204 {
+		t.Errorf("remove namespace failed: %d", code)
+	}
+}
@@ -3,8 +3,12 @@ package server_test
 import (
 	"bytes"
 	"fmt"
+	"io"
+	"mime/multipart"
 	"mock-server/internal/configs"
 	"mock-server/internal/control"
+	"net/http"
+	"net/url"
 	"testing"
 )
 
@@ -318,3 +322,54 @@ func TestDynamicRoutesBadArgs(t *testing.T) {
 		t.Errorf("expected to failed: 400 != %d", code)
 	}
 }
+
+func TestDynamicRoutesFormArgs(t *testing.T) {
+	testBodyScript := []byte(`{
+		"path": "/test_url",
+		"code": "def func(headers, body):\n    import base64\n    files = [[f['field'], f['filename'], f['size'], base64.b64decode(f['content']).decode()] for f in body['files']]\n    return [body['fields'], files]"
+	}`)
+
+	t.Setenv("CONFIG_PATH", "/configs/test_server_config.yaml")
+
+	control.Components.Start()
+	defer control.Components.Stop()
+
+	cfg := configs.GetServerConfig()
+	endpoint := fmt.Sprintf("http://%s", cfg.Addr)
+	dynamicApiEndpoint := endpoint + "/api/routes/dynamic"
+	testUrl := endpoint + "/test_url"
+
+	code, _ := DoPost(dynamicApiEndpoint, testBodyScript, t)
+	if code != 200 {
+		t.Fatalf("failed to add new dynamic route")
+	}
+
+	// url encoded form
+	resp, err := http.PostForm(testUrl, url.Values{"name": {"a", "b"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != 200 || !bytes.Equal(body, []byte(`"[{\"name\": [\"a\", \"b\"]}, []]"`)) {
+		t.Errorf("unexpected response on url encoded form %d: %s", resp.StatusCode, body)
+	}
+
+	// multipart form with file
+	var formBody bytes.Buffer
+	form := multipart.NewWriter(&formBody)
+	form.WriteField("name", "a")
+	part, _ := form.CreateFormFile("upload", "hello.txt")
+	part.Write([]byte("hello"))
+	form.Close()
+
+	resp, err = http.Post(testUrl, form.FormDataContentType(), &formBody)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != 200 || !bytes.Equal(body, []byte(`"[{\"name\": [\"a\"]}, [[\"upload\", \"hello.txt\", 5, \"hello\"]]]"`)) {
+		t.Errorf("unexpected response on multipart form %d: %s", resp.StatusCode, body)
+	}
+}
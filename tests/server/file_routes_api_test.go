@@ -0,0 +1,132 @@
+package server_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"mock-server/internal/configs"
+	"mock-server/internal/control"
+	"mock-server/internal/server/protocol"
+	"net/http"
+	"testing"
+)
+
+func uploadFile(method string, url string, path string, fileName string, content []byte, t *testing.T) int {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	form.WriteField("path", path)
+	part, err := form.CreateFormFile("file", fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write(content)
+	form.Close()
+
+	req, err := http.NewRequest(method, url, &body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode
+}
+
+func doGetWithHeader(url string, name string, value string, t *testing.T) (*http.Response, []byte) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set(name, value)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp, body
+}
+
+func TestFileRoutes(t *testing.T) {
+	t.Setenv("CONFIG_PATH", "/configs/test_server_config.yaml")
+
+	control.Components.Start()
+	defer control.Components.Stop()
+
+	cfg := configs.GetServerConfig()
+	endpoint := fmt.Sprintf("http://%s", cfg.Addr)
+	fileApiEndpoint := endpoint + "/api/routes/file"
+	testUrl := endpoint + "/report.json"
+	content := []byte(`{"report": [1, 2, 3]}`)
+
+	if code := uploadFile(http.MethodPost, fileApiEndpoint, "/report.json", "report.json", content, t); code != 200 {
+		t.Fatalf("status code %d != 200", code)
+	}
+	if code := uploadFile(http.MethodPost, fileApiEndpoint, "/report.json", "report.json", content, t); code != 409 {
+		t.Errorf("expected conflict on duplicate path: %d", code)
+	}
+
+	resp, body := doGetWithHeader(testUrl, "Accept", "*/*", t)
+	if resp.StatusCode != 200 || !bytes.Equal(body, content) {
+		t.Fatalf("unexpected file response %d: %s", resp.StatusCode, body)
+	}
+	if contentType := resp.Header.Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("unexpected content type %s", contentType)
+	}
+	etag := resp.Header.Get("ETag")
+	if etag == "" || resp.Header.Get("Last-Modified") == "" {
+		t.Errorf("validators are missing: %v", resp.Header)
+	}
+
+	// range request
+	resp, body = doGetWithHeader(testUrl, "Range", "bytes=2-7", t)
+	if resp.StatusCode != 206 || string(body) != "report" {
+		t.Errorf("unexpected range response %d: %s", resp.StatusCode, body)
+	}
+	if contentRange := resp.Header.Get("Content-Range"); contentRange != fmt.Sprintf("bytes 2-7/%d", len(content)) {
+		t.Errorf("unexpected content range %s", contentRange)
+	}
+
+	// conditional request
+	resp, _ = doGetWithHeader(testUrl, "If-None-Match", etag, t)
+	if resp.StatusCode != 304 {
+		t.Errorf("expected not modified: %d", resp.StatusCode)
+	}
+
+	// content type is sniffed for unknown extension
+	updated := []byte("plain text")
+	if code := uploadFile(http.MethodPut, fileApiEndpoint, "/report.json", "report", updated, t); code != 204 {
+		t.Fatalf("status code %d != 204", code)
+	}
+	resp, body = doGetWithHeader(testUrl, "If-None-Match", etag, t)
+	if resp.StatusCode != 200 || !bytes.Equal(body, updated) {
+		t.Errorf("unexpected response after update %d: %s", resp.StatusCode, body)
+	}
+
+	code, body := DoGet(fileApiEndpoint+"/config?path=/report.json", t)
+	var info protocol.FileEndpointInfo
+	if err := json.Unmarshal(body, &info); err != nil || code != 200 {
+		t.Fatalf("unexpected config response %d: %s", code, body)
+	}
+	if info.Size != int64(len(updated)) || info.ContentType != "text/plain; charset=utf-8" || info.Digest == "" {
+		t.Errorf("unexpected file route config: %+v", info)
+	}
+
+	if code := DoDelete(fileApiEndpoint+"?path=/report.json", t); code != 204 {
+		t.Errorf("status code %d != 204", code)
+	}
+	if code, _ := DoGet(testUrl, t); code != 400 {
+		t.Errorf("removed route is still served: %d", code)
+	}
+}
@@ -0,0 +1,224 @@
+package server_test
+
+import (
+	"bytes"
+	"fmt"
+	"mock-server/internal/configs"
+	"mock-server/internal/control"
+	"testing"
+)
+
+func TestGraphQLRoutesSimple(t *testing.T) {
+	t.Setenv("CONFIG_PATH", "/configs/test_server_config.yaml")
+
+	control.Components.Start()
+	defer control.Components.Stop()
+
+	cfg := configs.GetServerConfig()
+	endpoint := fmt.Sprintf("http://%s", cfg.Addr)
+	graphqlApiEndpoint := endpoint + "/api/routes/graphql"
+
+	//////////////////////////////////////////////////////
+
+	testUrl := endpoint + "/graphql"
+
+	// expects []
+	code, body := DoGet(graphqlApiEndpoint, t)
+	if code != 200 {
+		t.Errorf("expected 200 code response on list all request")
+	}
+
+	if !bytes.Equal(body, []byte(`{"endpoints":[]}`)) {
+		t.Errorf(`list request must be empty at the begining: %s != {"endpoints":[]}`, body)
+	}
+
+	// operations without response and code are rejected
+	code, _ = DoPost(graphqlApiEndpoint, []byte(`{
+		"path": "/graphql",
+		"operations": [{"operation_name": "GetUser"}]
+	}`), t)
+	if code != 400 {
+		t.Errorf("expected 400 on operation without response: %d", code)
+	}
+
+	// create route /graphql with two mocks of the same operation
+	requestBody := []byte(`{
+		"path": "/graphql",
+		"operations": [
+			{"operation_name": "GetUser", "response": {"user": {"name": "anyone"}}},
+			{"operation_name": "GetUser", "variables": {"id": 7}, "response": {"user": {"name": "seven"}}}
+		]
+	}`)
+	code, _ = DoPost(graphqlApiEndpoint, requestBody, t)
+	if code != 200 {
+		t.Errorf("create route failed: %d", code)
+	}
+
+	// expects ["/graphql"]
+	code, body = DoGet(graphqlApiEndpoint, t)
+	if code != 200 {
+		t.Errorf("expected 200 code response on list all request")
+	}
+
+	if !bytes.Equal(body, []byte(`{"endpoints":["/graphql"]}`)) {
+		t.Errorf(`must be visible new route after creation: %s != {"endpoints":["/graphql"]}`, body)
+	}
+
+	// operation without variables matches the generic mock
+	code, body = DoPost(testUrl, []byte(`{
+		"query": "query GetUser($id: Int) { user(id: $id) { name } }"
+	}`), t)
+	if code != 200 {
+		t.Errorf("expected to be possible make request to new route: %d", code)
+	}
+
+	if !bytes.Equal(body, []byte(`{"data":{"user":{"name":"anyone"}}}`)) {
+		t.Errorf(`graphql data mismatch: %s != {"data":{"user":{"name":"anyone"}}}`, body)
+	}
+
+	// matching variables selects the more specific mock
+	code, body = DoPost(testUrl, []byte(`{
+		"query": "query GetUser($id: Int) { user(id: $id) { name } }",
+		"operationName": "GetUser",
+		"variables": {"id": 7}
+	}`), t)
+	if code != 200 {
+		t.Errorf("expected to be possible make request to new route: %d", code)
+	}
+
+	if !bytes.Equal(body, []byte(`{"data":{"user":{"name":"seven"}}}`)) {
+		t.Errorf(`graphql data mismatch: %s != {"data":{"user":{"name":"seven"}}}`, body)
+	}
+
+	// unknown operation without schema -> 404
+	code, _ = DoPost(testUrl, []byte(`{
+		"query": "query GetOrders { orders { id } }"
+	}`), t)
+	if code != 404 {
+		t.Errorf("expected 404 on unknown operation: %d", code)
+	}
+
+	// broken query -> 400
+	code, _ = DoPost(testUrl, []byte(`{
+		"query": "query GetUser { user { name "
+	}`), t)
+	if code != 400 {
+		t.Errorf("expected 400 on broken query: %d", code)
+	}
+
+	// detele /graphql
+	code = DoDelete(graphqlApiEndpoint+"?path=/graphql", t)
+	if code != 204 {
+		t.Errorf("it must be possible to delete route")
+	}
+}
+
+func TestGraphQLRoutesSchema(t *testing.T) {
+	t.Setenv("CONFIG_PATH", "/configs/test_server_config.yaml")
+
+	control.Components.Start()
+	defer control.Components.Stop()
+
+	cfg := configs.GetServerConfig()
+	endpoint := fmt.Sprintf("http://%s", cfg.Addr)
+	graphqlApiEndpoint := endpoint + "/api/routes/graphql"
+	testUrl := endpoint + "/graphql"
+
+	// invalid schema is rejected
+	code, _ := DoPost(graphqlApiEndpoint, []byte(`{
+		"path": "/graphql",
+		"schema": "type Query { user: UnknownType }"
+	}`), t)
+	if code != 400 {
+		t.Errorf("expected 400 on invalid schema: %d", code)
+	}
+
+	requestBody := []byte(`{
+		"path": "/graphql",
+		"schema": "enum Role { ADMIN USER }\ntype User { id: ID! name: String age: Int role: Role }\ntype Query { users: [User!]! }"
+	}`)
+	code, _ = DoPost(graphqlApiEndpoint, requestBody, t)
+	if code != 200 {
+		t.Errorf("create route failed: %d", code)
+	}
+
+	// no operations mocked -> placeholder data generated from schema
+	code, body := DoPost(testUrl, []byte(`{
+		"query": "{ users { id name age role } }"
+	}`), t)
+	if code != 200 {
+		t.Errorf("expected to be possible make request to new route: %d", code)
+	}
+
+	expected := []byte(`{"data":{"users":[{"age":0,"id":"1","name":"string","role":"ADMIN"}]}}`)
+	if !bytes.Equal(body, expected) {
+		t.Errorf(`graphql placeholder mismatch: %s != %s`, body, expected)
+	}
+
+	// query that does not match schema -> 400
+	code, _ = DoPost(testUrl, []byte(`{
+		"query": "{ users { email } }"
+	}`), t)
+	if code != 400 {
+		t.Errorf("expected 400 on invalid query: %d", code)
+	}
+
+	// GET requests are matched by path without query string
+	code, body = DoGet(testUrl+"?query=%7Busers%7Bid%7D%7D", t)
+	if code != 200 {
+		t.Errorf("expected to be possible make get request to new route: %d", code)
+	}
+
+	if !bytes.Equal(body, []byte(`{"data":{"users":[{"id":"1"}]}}`)) {
+		t.Errorf(`graphql placeholder mismatch: %s != {"data":{"users":[{"id":"1"}]}}`, body)
+	}
+}
+
+func TestGraphQLRoutesScripts(t *testing.T) {
+	t.Setenv("CONFIG_PATH", "/configs/test_server_config.yaml")
+
+	control.Components.Start()
+	defer control.Components.Stop()
+
+	cfg := configs.GetServerConfig()
+	endpoint := fmt.Sprintf("http://%s", cfg.Addr)
+	graphqlApiEndpoint := endpoint + "/api/routes/graphql"
+
+	scripts := CountScripts(t)
+	requestBody := []byte(`{
+		"path": "/graphql_scripts",
+		"operations": [{"operation_name": "GetUser", "code": "def func(headers, body):\n    return {'user': None}"}]
+	}`)
+
+	if code, body := DoPost(graphqlApiEndpoint, requestBody, t); code != 200 {
+		t.Fatalf("create route failed: %d %s", code, body)
+	}
+	if code, _ := DoPost(graphqlApiEndpoint, requestBody, t); code != 409 {
+		t.Errorf("expected 409 on duplicate route: %d", code)
+	}
+	if cnt := CountScripts(t); cnt != scripts+1 {
+		t.Errorf("expected one script of created route: %d != %d", cnt, scripts+1)
+	}
+
+	// scripts of replaced operations are removed
+	if code := DoPut(graphqlApiEndpoint, requestBody, t); code != 204 {
+		t.Errorf("update route failed: %d", code)
+	}
+	if cnt := CountScripts(t); cnt != scripts+1 {
+		t.Errorf("scripts of replaced operations are kept: %d != %d", cnt, scripts+1)
+	}
+
+	if code := DoPut(graphqlApiEndpoint, bytes.Replace(requestBody, []byte("/graphql_scripts"), []byte("/graphql_missing"), 1), t); code != 404 {
+		t.Errorf("expected 404 on update of unexisting route: %d", code)
+	}
+
+	if code := DoDelete(graphqlApiEndpoint+"?path=/graphql_scripts", t); code != 204 {
+		t.Errorf("it must be possible to delete route: %d", code)
+	}
+	if code := DoDelete(graphqlApiEndpoint+"?path=/graphql_scripts", t); code != 404 {
+		t.Errorf("expected 404 on delete of unexisting route: %d", code)
+	}
+	if cnt := CountScripts(t); cnt != scripts {
+		t.Errorf("scripts of removed route are kept: %d != %d", cnt, scripts)
+	}
+}
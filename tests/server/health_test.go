@@ -0,0 +1,48 @@
+package server_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"mock-server/internal/configs"
+	"mock-server/internal/control"
+	"mock-server/internal/server/protocol"
+	"testing"
+)
+
+func TestHealthEndpoints(t *testing.T) {
+	t.Setenv("CONFIG_PATH", "/configs/test_server_config.yaml")
+
+	control.Components.Start()
+	defer control.Components.Stop()
+
+	cfg := configs.GetServerConfig()
+	endpoint := fmt.Sprintf("http://%s", cfg.Addr)
+
+	for _, probe := range []string{"/healthz", "/readyz"} {
+		code, body := DoGet(endpoint+probe, t)
+		if code != 200 {
+			t.Errorf("%s status code %d != 200: %s", probe, code, body)
+		}
+
+		var report protocol.HealthReport
+		if err := json.Unmarshal(body, &report); err != nil {
+			t.Fatalf("%s: failed to parse report: %s", probe, err)
+		}
+		if report.Status != protocol.HEALTH_STATUS_UP {
+			t.Errorf("%s: expected status up: %s", probe, body)
+		}
+
+		// brokers are disabled in test config and are not reported
+		if _, ok := report.Components["rabbitmq"]; ok {
+			t.Errorf("%s: disabled component is reported: %s", probe, body)
+		}
+		if report.Components["database"].Status != protocol.HEALTH_STATUS_UP {
+			t.Errorf("%s: expected database to be up: %s", probe, body)
+		}
+
+		coderun := report.Components["coderun"]
+		if coderun.Status != protocol.HEALTH_STATUS_UP || coderun.RunningWorkers == nil || *coderun.RunningWorkers != 1 {
+			t.Errorf("%s: expected one running coderun worker: %s", probe, body)
+		}
+	}
+}
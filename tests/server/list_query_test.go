@@ -0,0 +1,135 @@
+package server_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"mock-server/internal/configs"
+	"mock-server/internal/control"
+	"mock-server/internal/database"
+	"mock-server/internal/server/protocol"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestListRoutesPages(t *testing.T) {
+	t.Setenv("CONFIG_PATH", "/configs/test_server_config.yaml")
+
+	control.Components.Start()
+	defer control.Components.Stop()
+
+	cfg := configs.GetServerConfig()
+	endpoint := fmt.Sprintf("http://%s", cfg.Addr)
+
+	for _, path := range []string{"/list/c", "/list/a", "/other", "/list/b"} {
+		body := fmt.Sprintf(`{"path": "%s", "expected_response": "ok"}`, path)
+		if code, resp := DoPost(endpoint+"/api/routes/static", []byte(body), t); code != 200 {
+			t.Fatalf("status code %d != 200: %s", code, resp)
+		}
+	}
+
+	var paths []string
+	cursor := ""
+	for pages := 0; ; pages++ {
+		if pages > 2 {
+			t.Fatalf("too many pages")
+		}
+
+		query := url.Values{"limit": {"2"}, "sort": {"path"}, "order": {"desc"}, "prefix": {"/list/"}}
+		if cursor != "" {
+			query.Set("cursor", cursor)
+		}
+		code, body := DoGet(endpoint+"/api/routes/static?"+query.Encode(), t)
+		if code != 200 {
+			t.Fatalf("status code %d != 200: %s", code, body)
+		}
+
+		var page struct {
+			Endpoints  []string `json:"endpoints"`
+			NextCursor string   `json:"next_cursor"`
+		}
+		if err := json.Unmarshal(body, &page); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, page.Endpoints...)
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	expected := []string{"/list/c", "/list/b", "/list/a"}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("res != expected: %+q != %+q", paths, expected)
+	}
+
+	// default page keeps creation order and has no cursor
+	code, body := DoGet(endpoint+"/api/routes/static?search=OTH", t)
+	if code != 200 || string(body) != `{"endpoints":["/other"]}` {
+		t.Errorf("unexpected search response %d: %s", code, body)
+	}
+
+	if code, body := DoGet(endpoint+"/api/routes/static?limit=5000", t); code != 400 {
+		t.Errorf("status code %d != 400: %s", code, body)
+	}
+
+	code, body = DoGet(endpoint+"/api/v2/routes/static?sort=response", t)
+	if code != 400 {
+		t.Fatalf("status code %d != 400: %s", code, body)
+	}
+	if resp := parseApiResponse(body, t); resp.Error == nil || resp.Error.Code != protocol.ERR_CODE_INVALID_SORT {
+		t.Errorf("unexpected sort error: %s", body)
+	}
+}
+
+func TestListDefaultLimit(t *testing.T) {
+	t.Setenv("CONFIG_PATH", "/configs/test_server_config.yaml")
+
+	control.Components.Start()
+	defer control.Components.Stop()
+
+	cfg := configs.GetServerConfig()
+	endpoint := fmt.Sprintf("http://%s", cfg.Addr)
+
+	total := database.DEFAULT_PAGE_LIMIT + 5
+	for i := 0; i < total; i++ {
+		body := fmt.Sprintf(`{"path": "/many/%d", "expected_response": "ok"}`, i)
+		if code, resp := DoPost(endpoint+"/api/routes/static", []byte(body), t); code != 200 {
+			t.Fatalf("status code %d != 200: %s", code, resp)
+		}
+	}
+
+	// v1 list without limit returns every item as before pagination
+	code, body := DoGet(endpoint+"/api/routes/static", t)
+	if code != 200 {
+		t.Fatalf("status code %d != 200: %s", code, body)
+	}
+	var all struct {
+		Endpoints  []string `json:"endpoints"`
+		NextCursor string   `json:"next_cursor"`
+	}
+	if err := json.Unmarshal(body, &all); err != nil {
+		t.Fatal(err)
+	}
+	if len(all.Endpoints) != total || all.NextCursor != "" {
+		t.Errorf("v1 list is paged: %d items, cursor %q", len(all.Endpoints), all.NextCursor)
+	}
+
+	// v2 list is paged by default
+	code, body = DoGet(endpoint+"/api/v2/routes/static", t)
+	if code != 200 {
+		t.Fatalf("status code %d != 200: %s", code, body)
+	}
+	var paged struct {
+		Data struct {
+			Endpoints  []string `json:"endpoints"`
+			NextCursor string   `json:"next_cursor"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &paged); err != nil {
+		t.Fatal(err)
+	}
+	if len(paged.Data.Endpoints) != database.DEFAULT_PAGE_LIMIT || paged.Data.NextCursor == "" {
+		t.Errorf("v2 list is not paged: %d items, cursor %q", len(paged.Data.Endpoints), paged.Data.NextCursor)
+	}
+}
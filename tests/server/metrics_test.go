@@ -0,0 +1,53 @@
+package server_test
+
+import (
+	"bytes"
+	"fmt"
+	"mock-server/internal/configs"
+	"mock-server/internal/control"
+	"testing"
+)
+
+func TestMetricsMockRequests(t *testing.T) {
+	t.Setenv("CONFIG_PATH", "/configs/test_server_config.yaml")
+
+	control.Components.Start()
+	defer control.Components.Stop()
+
+	cfg := configs.GetServerConfig()
+	endpoint := fmt.Sprintf("http://%s", cfg.Addr)
+	staticApiEndpoint := endpoint + "/api/routes/static"
+
+	code, _ := DoPost(staticApiEndpoint, []byte(`{
+		"path": "/metrics_url",
+		"expected_response": "hello"
+	}`), t)
+	if code != 200 {
+		t.Fatalf("create static route status code %d != 200", code)
+	}
+
+	if code, _ := DoGet(endpoint+"/metrics_url", t); code != 200 {
+		t.Errorf("mock route status code %d != 200", code)
+	}
+	if code, _ := DoGet(endpoint+"/metrics_unknown_url", t); code != 400 {
+		t.Errorf("unknown route status code %d != 400", code)
+	}
+
+	code, body := DoGet(endpoint+"/metrics", t)
+	if code != 200 {
+		t.Fatalf("metrics status code %d != 200", code)
+	}
+
+	expected := [][]byte{
+		[]byte(`mock_server_mock_requests_total{route="/metrics_url",status="200",type="static_endpoint"}`),
+		[]byte(`mock_server_mock_requests_total{route="unmatched",status="400",type="unmatched"}`),
+		[]byte(`mock_server_mock_request_duration_seconds_count{route="/metrics_url",type="static_endpoint"}`),
+	}
+	for _, series := range expected {
+		if !bytes.Contains(body, series) {
+			t.Errorf("metrics do not contain %s", series)
+		}
+	}
+
+	DoDelete(staticApiEndpoint+"?path=/metrics_url", t)
+}
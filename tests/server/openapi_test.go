@@ -0,0 +1,64 @@
+package server_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"mock-server/internal/configs"
+	"mock-server/internal/control"
+	"strings"
+	"testing"
+)
+
+func TestOpenApiDocument(t *testing.T) {
+	t.Setenv("CONFIG_PATH", "/configs/test_server_config.yaml")
+
+	control.Components.Start()
+	defer control.Components.Stop()
+
+	cfg := configs.GetServerConfig()
+	endpoint := fmt.Sprintf("http://%s", cfg.Addr)
+
+	code, body := DoGet(endpoint+"/api/openapi.json", t)
+	if code != 200 {
+		t.Fatalf("status code %d != 200: %s", code, body)
+	}
+
+	var document struct {
+		OpenAPI string `json:"openapi"`
+		Paths   map[string]map[string]struct {
+			RequestBody *json.RawMessage `json:"requestBody"`
+		} `json:"paths"`
+		Components struct {
+			Schemas map[string]struct {
+				Required []string `json:"required"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(body, &document); err != nil {
+		t.Fatalf("failed to parse document: %s", err)
+	}
+
+	if document.OpenAPI != "3.0.3" {
+		t.Errorf("unexpected openapi version %s", document.OpenAPI)
+	}
+	if op, ok := document.Paths["/api/routes/static"]["post"]; !ok || op.RequestBody == nil {
+		t.Errorf("create static route is not described: %s", body)
+	}
+	if _, ok := document.Paths["/metrics"]; ok {
+		t.Errorf("non admin route is published")
+	}
+
+	schema, ok := document.Components.Schemas["StaticEndpoint"]
+	if !ok {
+		t.Fatalf("StaticEndpoint schema is missing")
+	}
+	// expected response is not required if representations are given
+	if strings.Join(schema.Required, ",") != "path" {
+		t.Errorf("unexpected required fields of StaticEndpoint: %v", schema.Required)
+	}
+
+	code, body = DoGet(endpoint+"/api/docs", t)
+	if code != 200 || !strings.Contains(string(body), "openapi.json") {
+		t.Errorf("unexpected viewer response %d: %s", code, body)
+	}
+}
@@ -0,0 +1,123 @@
+package server_test
+
+import (
+	"fmt"
+	"mock-server/internal/configs"
+	"mock-server/internal/control"
+	"net/http"
+	"testing"
+)
+
+func DoGetWithHeader(url string, header string, value string, t *testing.T) *http.Response {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set(header, value)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp
+}
+
+func TestRateLimitPolicies(t *testing.T) {
+	t.Setenv("CONFIG_PATH", "/configs/test_server_config.yaml")
+
+	control.Components.Start()
+	defer control.Components.Stop()
+
+	cfg := configs.GetServerConfig()
+	endpoint := fmt.Sprintf("http://%s", cfg.Addr)
+	staticApiEndpoint := endpoint + "/api/routes/static"
+	rateLimitPolicyEndpoint := endpoint + "/api/routes/policy/rate_limit"
+	namespacesEndpoint := endpoint + "/api/namespaces"
+
+	//////////////////////////////////////////////////////
+
+	for _, path := range []string{"/limited", "/shared/a", "/shared/b"} {
+		code, _ := DoPost(staticApiEndpoint, []byte(fmt.Sprintf(`{
+			"path": "%s",
+			"expected_response": "ok"
+		}`, path)), t)
+		if code != 200 {
+			t.Errorf("create route failed: %d", code)
+		}
+	}
+
+	// header key requires header name
+	code := DoPut(rateLimitPolicyEndpoint, []byte(`{
+		"path": "/limited",
+		"rate_limit": {"limit": 2, "period_sec": 60, "key_by": "header"}
+	}`), t)
+	if code != 400 {
+		t.Errorf("expected 400 on header key without header name: %d", code)
+	}
+
+	code = DoPut(rateLimitPolicyEndpoint, []byte(`{
+		"path": "/limited",
+		"rate_limit": {"limit": 2, "period_sec": 60, "key_by": "header", "header": "X-Api-Key"}
+	}`), t)
+	if code != 204 {
+		t.Errorf("set route policy failed: %d", code)
+	}
+
+	for i := 0; i < 2; i++ {
+		resp := DoGetWithHeader(endpoint+"/limited", "X-Api-Key", "first", t)
+		if resp.StatusCode != 200 {
+			t.Errorf("expected request within limit to pass: %d", resp.StatusCode)
+		}
+		if limit := resp.Header.Get("X-RateLimit-Limit"); limit != "2" {
+			t.Errorf("limit header mismatch: %s != 2", limit)
+		}
+	}
+
+	resp := DoGetWithHeader(endpoint+"/limited", "X-Api-Key", "first", t)
+	if resp.StatusCode != 429 {
+		t.Errorf("expected 429 when limit exceeded: %d", resp.StatusCode)
+	}
+	if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "30" {
+		t.Errorf("retry after mismatch: %s != 30", retryAfter)
+	}
+	if remaining := resp.Header.Get("X-RateLimit-Remaining"); remaining != "0" {
+		t.Errorf("remaining mismatch: %s != 0", remaining)
+	}
+
+	// other client has own bucket
+	resp = DoGetWithHeader(endpoint+"/limited", "X-Api-Key", "second", t)
+	if resp.StatusCode != 200 {
+		t.Errorf("expected other client to pass: %d", resp.StatusCode)
+	}
+
+	// namespace limit is shared by all its routes
+	code, _ = DoPost(namespacesEndpoint, []byte(`{
+		"prefix": "/shared",
+		"rate_limit": {"limit": 1, "period_sec": 60, "key_by": "ip"}
+	}`), t)
+	if code != 200 {
+		t.Errorf("create namespace failed: %d", code)
+	}
+
+	code, _ = DoGet(endpoint+"/shared/a", t)
+	if code != 200 {
+		t.Errorf("expected first request to pass: %d", code)
+	}
+
+	code, _ = DoGet(endpoint+"/shared/b", t)
+	if code != 429 {
+		t.Errorf("expected namespace limit to be shared: %d", code)
+	}
+
+	// limit is lifted with policy removal
+	code = DoDelete(rateLimitPolicyEndpoint+"?path=/limited", t)
+	if code != 204 {
+		t.Errorf("remove route policy failed: %d", code)
+	}
+
+	resp = DoGetWithHeader(endpoint+"/limited", "X-Api-Key", "first", t)
+	if resp.StatusCode != 200 {
+		t.Errorf("expected request to pass after policy removal: %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,76 @@
+package server_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"mock-server/internal/configs"
+	"mock-server/internal/control"
+	"mock-server/internal/server/protocol"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestRevisionsRollback(t *testing.T) {
+	t.Setenv("CONFIG_PATH", "/configs/test_server_config.yaml")
+
+	control.Components.Start()
+	defer control.Components.Stop()
+
+	cfg := configs.GetServerConfig()
+	endpoint := fmt.Sprintf("http://%s", cfg.Addr)
+
+	if code, body := DoPost(endpoint+"/api/routes/static", []byte(`{"path": "/revised", "expected_response": "first"}`), t); code != 200 {
+		t.Fatalf("status code %d != 200: %s", code, body)
+	}
+	if code := DoPut(endpoint+"/api/routes/static", []byte(`{"path": "/revised", "expected_response": "second"}`), t); code != 204 {
+		t.Fatalf("status code %d != 204", code)
+	}
+
+	query := url.Values{"resource": {"route"}, "key": {"/revised"}}
+	code, body := DoGet(endpoint+"/api/revisions?"+query.Encode(), t)
+	if code != 200 {
+		t.Fatalf("status code %d != 200: %s", code, body)
+	}
+	var resp struct {
+		Revisions []protocol.Revision `json:"revisions"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil || len(resp.Revisions) != 2 {
+		t.Fatalf("expected 2 revisions: %s", body)
+	}
+
+	query.Set("from", "1")
+	query.Set("to", "2")
+	code, body = DoGet(endpoint+"/api/revisions/diff?"+query.Encode(), t)
+	var diff protocol.RevisionDiff
+	if err := json.Unmarshal(body, &diff); err != nil || code != 200 {
+		t.Fatalf("unexpected diff response %d: %s", code, body)
+	}
+	if len(diff.Fields) != 1 || diff.Fields[0].Field != "response" {
+		t.Errorf("unexpected diff: %s", body)
+	}
+
+	rollback := []byte(`{"resource": "route", "key": "/revised", "revision": 1}`)
+	if code, body := DoPost(endpoint+"/api/revisions/rollback", rollback, t); code != 204 {
+		t.Fatalf("status code %d != 204: %s", code, body)
+	}
+	if code, body := DoGet(endpoint+"/revised", t); code != 200 || string(body) != `"first"` {
+		t.Errorf("unexpected response after rollback %d: %s", code, body)
+	}
+
+	code, body = DoGet(endpoint+"/api/revisions?resource=route&key=/revised", t)
+	if err := json.Unmarshal(body, &resp); err != nil || code != 200 || len(resp.Revisions) != 3 {
+		t.Fatalf("expected 3 revisions: %s", body)
+	}
+	var first, rolledBack interface{}
+	json.Unmarshal(resp.Revisions[0].State, &first)
+	json.Unmarshal(resp.Revisions[2].State, &rolledBack)
+	if !reflect.DeepEqual(first, rolledBack) {
+		t.Errorf("rolled back state differs from revision 1: %s", body)
+	}
+
+	missing := []byte(`{"resource": "route", "key": "/revised", "revision": 10}`)
+	if code, body := DoPost(endpoint+"/api/revisions/rollback", missing, t); code != 404 {
+		t.Errorf("status code %d != 404: %s", code, body)
+	}
+}
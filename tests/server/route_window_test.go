@@ -0,0 +1,104 @@
+package server_test
+
+import (
+	"bytes"
+	"fmt"
+	"mock-server/internal/configs"
+	"mock-server/internal/control"
+	"testing"
+	"time"
+)
+
+func TestRouteActivityWindow(t *testing.T) {
+	t.Setenv("CONFIG_PATH", "/configs/test_server_config.yaml")
+
+	control.Components.Start()
+	defer control.Components.Stop()
+
+	cfg := configs.GetServerConfig()
+	endpoint := fmt.Sprintf("http://%s", cfg.Addr)
+	staticApiEndpoint := endpoint + "/api/routes/static"
+
+	//////////////////////////////////////////////////////
+
+	// window in the past is rejected
+	code, _ := DoPost(staticApiEndpoint, []byte(`{
+		"path": "/past",
+		"expected_response": "hello",
+		"active_until": "2000-01-01T00:00:00Z"
+	}`), t)
+	if code != 400 {
+		t.Errorf("expected 400 on window in the past: %d", code)
+	}
+
+	// ttl and expire_at are mutually exclusive
+	code, _ = DoPost(staticApiEndpoint, []byte(`{
+		"path": "/past",
+		"expected_response": "hello",
+		"ttl_sec": 10,
+		"expire_at": "2099-01-01T00:00:00Z"
+	}`), t)
+	if code != 400 {
+		t.Errorf("expected 400 on both ttl and expire_at: %d", code)
+	}
+
+	// route is not served before activation
+	code, _ = DoPost(staticApiEndpoint, []byte(`{
+		"path": "/scheduled",
+		"expected_response": "hello",
+		"active_from": "2099-01-01T00:00:00Z"
+	}`), t)
+	if code != 200 {
+		t.Errorf("create route failed: %d", code)
+	}
+
+	code, _ = DoGet(endpoint+"/scheduled", t)
+	if code != 400 {
+		t.Errorf("expected not active route to be skipped: %d", code)
+	}
+
+	// route with ttl disappears after expiry
+	code, _ = DoPost(staticApiEndpoint, []byte(`{
+		"path": "/temporary",
+		"expected_response": "hello",
+		"ttl_sec": 1
+	}`), t)
+	if code != 200 {
+		t.Errorf("create route failed: %d", code)
+	}
+
+	code, _ = DoGet(endpoint+"/temporary", t)
+	if code != 200 {
+		t.Errorf("expected route to be served before expiry: %d", code)
+	}
+
+	time.Sleep(1500 * time.Millisecond)
+
+	code, _ = DoGet(endpoint+"/temporary", t)
+	if code != 400 {
+		t.Errorf("expected expired route to be skipped: %d", code)
+	}
+
+	code, body := DoGet(staticApiEndpoint, t)
+	if code != 200 {
+		t.Errorf("expected 200 code response on list all request")
+	}
+
+	if !bytes.Equal(body, []byte(`{"endpoints":["/scheduled"]}`)) {
+		t.Errorf(`expired route must not be listed: %s != {"endpoints":["/scheduled"]}`, body)
+	}
+
+	// path of expired route can be reused before ttl monitor removes it
+	code, _ = DoPost(staticApiEndpoint, []byte(`{
+		"path": "/temporary",
+		"expected_response": "again"
+	}`), t)
+	if code != 200 {
+		t.Errorf("expected path of expired route to be reusable: %d", code)
+	}
+
+	code, _ = DoGet(endpoint+"/temporary", t)
+	if code != 200 {
+		t.Errorf("expected recreated route to be served: %d", code)
+	}
+}
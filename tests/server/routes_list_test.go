@@ -0,0 +1,64 @@
+package server_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"mock-server/internal/configs"
+	"mock-server/internal/control"
+	"mock-server/internal/server/protocol"
+	"testing"
+)
+
+func TestListAllRoutes(t *testing.T) {
+	t.Setenv("CONFIG_PATH", "/configs/test_server_config.yaml")
+
+	control.Components.Start()
+	defer control.Components.Stop()
+
+	cfg := configs.GetServerConfig()
+	endpoint := fmt.Sprintf("http://%s", cfg.Addr)
+
+	if code, body := DoPost(endpoint+"/api/routes/static", []byte(`{"path": "/listed/static", "expected_response": "hello"}`), t); code != 200 {
+		t.Fatalf("status code %d != 200: %s", code, body)
+	}
+	if code, body := DoPost(endpoint+"/api/routes/proxy", []byte(`{"path": "/listed/proxy", "proxy_url": "http://localhost:1/"}`), t); code != 200 {
+		t.Fatalf("status code %d != 200: %s", code, body)
+	}
+
+	for i := 0; i < 3; i++ {
+		if code, _ := DoGet(endpoint+"/listed/static", t); code != 200 {
+			t.Errorf("mock route status code %d != 200", code)
+		}
+	}
+
+	code, body := DoGet(endpoint+"/api/routes?sort=path", t)
+	if code != 200 {
+		t.Fatalf("status code %d != 200: %s", code, body)
+	}
+
+	var resp struct {
+		Routes []protocol.RouteInfo `json:"routes"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Routes) != 2 {
+		t.Fatalf("expected 2 routes: %s", body)
+	}
+
+	proxy, static := resp.Routes[0], resp.Routes[1]
+	if proxy.Type != "proxy_endpoint" || proxy.ProxyURL != "http://localhost:1/" || proxy.Hits != 0 {
+		t.Errorf("unexpected proxy route: %+v", proxy)
+	}
+	if static.Type != "static_endpoint" || static.Response != "hello" || static.Hits != 3 {
+		t.Errorf("unexpected static route: %+v", static)
+	}
+	if static.Matcher != protocol.ROUTE_MATCHER_URI || static.CreatedAt == nil || static.UpdatedAt == nil || !static.Active {
+		t.Errorf("unexpected static route details: %+v", static)
+	}
+
+	code, body = DoGet(endpoint+"/api/routes?type=proxy_endpoint", t)
+	if err := json.Unmarshal(body, &resp); err != nil || code != 200 || len(resp.Routes) != 1 {
+		t.Errorf("unexpected filtered response %d: %s", code, body)
+	}
+}
@@ -162,3 +162,64 @@ func TestStaticRoutesDoublePost(t *testing.T) {
 		t.Errorf("expected to be possible to update already created endpoint: expected 204 != %d", code)
 	}
 }
+
+func TestStaticRoutesContentNegotiation(t *testing.T) {
+	t.Setenv("CONFIG_PATH", "/configs/test_server_config.yaml")
+
+	control.Components.Start()
+	defer control.Components.Stop()
+
+	cfg := configs.GetServerConfig()
+	endpoint := fmt.Sprintf("http://%s", cfg.Addr)
+	staticApiEndpoint := endpoint + "/api/routes/static"
+	testUrl := endpoint + "/negotiated"
+
+	// wildcard content types are rejected
+	code, _ := DoPost(staticApiEndpoint, []byte(`{
+		"path": "/negotiated",
+		"representations": [{"content_type": "application/*", "body": "{}"}]
+	}`), t)
+	if code != 400 {
+		t.Errorf("expected 400 on wildcard representation: %d", code)
+	}
+
+	code, body := DoPost(staticApiEndpoint, []byte(`{
+		"path": "/negotiated",
+		"representations": [
+			{"content_type": "application/json", "body": "{\"a\": 1}"},
+			{"content_type": "application/xml", "body": "<a>1</a>"}
+		]
+	}`), t)
+	if code != 200 {
+		t.Fatalf("failed to create static route %d: %s", code, body)
+	}
+
+	for _, tc := range []struct {
+		accept      string
+		code        int
+		contentType string
+		body        string
+	}{
+		{"", 200, "application/json", `{"a": 1}`},
+		{"application/xml", 200, "application/xml", "<a>1</a>"},
+		{"application/json;q=0.5, application/xml;q=0.8", 200, "application/xml", "<a>1</a>"},
+		{"text/*, application/*;q=0.1", 200, "application/json", `{"a": 1}`},
+		{"application/xml;q=0, */*", 200, "application/json", `{"a": 1}`},
+		{"text/html", 406, "", ""},
+	} {
+		resp, body := doGetWithHeader(testUrl, "Accept", tc.accept, t)
+		if resp.StatusCode != tc.code {
+			t.Errorf("accept %q: status code %d != %d", tc.accept, resp.StatusCode, tc.code)
+			continue
+		}
+		if resp.Header.Get("Vary") != "Accept" {
+			t.Errorf("accept %q: vary header is missing", tc.accept)
+		}
+		if tc.code != 200 {
+			continue
+		}
+		if resp.Header.Get("Content-Type") != tc.contentType || string(body) != tc.body {
+			t.Errorf("accept %q: unexpected response %s: %s", tc.accept, resp.Header.Get("Content-Type"), body)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package server_test
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"mock-server/internal/configs"
+	"mock-server/internal/control"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestStreamRoutesSSE(t *testing.T) {
+	t.Setenv("CONFIG_PATH", "/configs/test_server_config.yaml")
+
+	control.Components.Start()
+	defer control.Components.Stop()
+
+	cfg := configs.GetServerConfig()
+	endpoint := fmt.Sprintf("http://%s", cfg.Addr)
+	streamApiEndpoint := endpoint + "/api/routes/stream"
+	testUrl := endpoint + "/events"
+
+	//////////////////////////////////////////////////////
+
+	// expects []
+	code, body := DoGet(streamApiEndpoint, t)
+	if code != 200 {
+		t.Errorf("expected 200 code response on list all request")
+	}
+
+	if !bytes.Equal(body, []byte(`{"endpoints":[]}`)) {
+		t.Errorf(`list request must be empty at the begining: %s != {"endpoints":[]}`, body)
+	}
+
+	// looped stream without delays is rejected
+	code, _ = DoPost(streamApiEndpoint, []byte(`{
+		"path": "/events",
+		"mode": "sse",
+		"loop": true,
+		"events": [{"data": "tick"}]
+	}`), t)
+	if code != 400 {
+		t.Errorf("expected 400 on looped stream without delays: %d", code)
+	}
+
+	requestBody := []byte(`{
+		"path": "/events",
+		"mode": "sse",
+		"events": [
+			{"event": "greeting", "id": "1", "data": "hello"},
+			{"data": "line1\nline2", "delay_ms": 100}
+		]
+	}`)
+	code, _ = DoPost(streamApiEndpoint, requestBody, t)
+	if code != 200 {
+		t.Errorf("create route failed: %d", code)
+	}
+
+	started := time.Now()
+	code, body = DoGet(testUrl, t)
+	if code != 200 {
+		t.Errorf("expected to be possible make request to new route: %d", code)
+	}
+
+	if time.Since(started) < 100*time.Millisecond {
+		t.Errorf("event delay was not respected")
+	}
+
+	expected := []byte("id: 1\nevent: greeting\ndata: hello\n\ndata: line1\ndata: line2\n\n")
+	if !bytes.Equal(body, expected) {
+		t.Errorf("sse stream mismatch: %q != %q", body, expected)
+	}
+
+	// detele /events
+	code = DoDelete(streamApiEndpoint+"?path=/events", t)
+	if code != 204 {
+		t.Errorf("it must be possible to delete route")
+	}
+}
+
+func TestStreamRoutesChunkedLoop(t *testing.T) {
+	t.Setenv("CONFIG_PATH", "/configs/test_server_config.yaml")
+
+	control.Components.Start()
+	defer control.Components.Stop()
+
+	cfg := configs.GetServerConfig()
+	endpoint := fmt.Sprintf("http://%s", cfg.Addr)
+	streamApiEndpoint := endpoint + "/api/routes/stream"
+	testUrl := endpoint + "/tokens"
+
+	requestBody := []byte(`{
+		"path": "/tokens",
+		"mode": "chunked",
+		"content_type": "text/plain",
+		"loop": true,
+		"events": [{"data": "token\n", "delay_ms": 10}]
+	}`)
+	code, _ := DoPost(streamApiEndpoint, requestBody, t)
+	if code != 200 {
+		t.Errorf("create route failed: %d", code)
+	}
+
+	resp, err := http.Get(testUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if contentType := resp.Header.Get("Content-Type"); contentType != "text/plain" {
+		t.Errorf("content type mismatch: %s != text/plain", contentType)
+	}
+
+	// looped stream keeps sending chunks until client disconnects
+	reader := bufio.NewReader(resp.Body)
+	for i := 0; i < 3; i++ {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if line != "token\n" {
+			t.Errorf("chunk mismatch: %q != %q", line, "token\n")
+		}
+	}
+	resp.Body.Close()
+}
+
+func TestStreamRoutesOutliveResponseTimeout(t *testing.T) {
+	t.Setenv("CONFIG_PATH", "/configs/test_server_config.yaml")
+	configs.SetConfigureForTestingFunc(func(cfg *configs.ServiceConfig) {
+		cfg.Server.ResponseTimeout = time.Second
+	})
+	defer configs.SetConfigureForTestingFunc(nil)
+
+	control.Components.Start()
+	defer control.Components.Stop()
+
+	cfg := configs.GetServerConfig()
+	endpoint := fmt.Sprintf("http://%s", cfg.Addr)
+
+	requestBody := []byte(`{
+		"path": "/slow-ticks",
+		"mode": "chunked",
+		"content_type": "text/plain",
+		"loop": true,
+		"events": [{"data": "tick\n", "delay_ms": 500}]
+	}`)
+	if code, body := DoPost(endpoint+"/api/routes/stream", requestBody, t); code != 200 {
+		t.Fatalf("create route failed: %d %s", code, body)
+	}
+
+	resp, err := http.Get(endpoint + "/slow-ticks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	// 5 ticks take 2.5s, which is past response timeout of 1s
+	started := time.Now()
+	reader := bufio.NewReader(resp.Body)
+	for i := 0; i < 5; i++ {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("stream cut after %s: %s", time.Since(started), err)
+		}
+		if line != "tick\n" {
+			t.Errorf("chunk mismatch: %q != %q", line, "tick\n")
+		}
+	}
+}
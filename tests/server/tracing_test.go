@@ -0,0 +1,50 @@
+package server_test
+
+import (
+	"fmt"
+	"mock-server/internal/configs"
+	"mock-server/internal/control"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTracingProxyPropagation(t *testing.T) {
+	t.Setenv("CONFIG_PATH", "/configs/test_server_config.yaml")
+
+	control.Components.Start()
+	defer control.Components.Stop()
+
+	cfg := configs.GetServerConfig()
+	endpoint := fmt.Sprintf("http://%s", cfg.Addr)
+	proxyApiEndpoint := endpoint + "/api/routes/proxy"
+
+	traceparents := make(chan string, 1)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparents <- r.Header.Get("traceparent")
+	}))
+	defer upstream.Close()
+
+	code, _ := DoPost(proxyApiEndpoint, []byte(fmt.Sprintf(`{
+		"path": "/traced_url",
+		"proxy_url": "%s"
+	}`, upstream.URL)), t)
+	if code != 200 {
+		t.Fatalf("create proxy route status code %d != 200", code)
+	}
+
+	const traceId = "4bf92f3577b34da6a3ce929d0e0e4736"
+	resp := DoGetWithHeader(endpoint+"/traced_url", "traceparent", "00-"+traceId+"-00f067aa0ba902b7-01", t)
+	if resp.StatusCode != 200 {
+		t.Errorf("proxy route status code %d != 200", resp.StatusCode)
+	}
+
+	// upstream is called within the same trace, but from span of mock-server
+	traceparent := <-traceparents
+	if !strings.HasPrefix(traceparent, "00-"+traceId+"-") || strings.Contains(traceparent, "00f067aa0ba902b7") {
+		t.Errorf("trace context is not propagated to upstream: %q", traceparent)
+	}
+
+	DoDelete(proxyApiEndpoint+"?path=/traced_url", t)
+}
@@ -0,0 +1,202 @@
+package server_test
+
+import (
+	"bytes"
+	"fmt"
+	"mock-server/internal/configs"
+	"mock-server/internal/control"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+func readWebSocketMessage(conn *websocket.Conn, t *testing.T) []byte {
+	_, message, err := conn.ReadMessage()
+	if err != nil {
+		t.Error(err)
+		return nil
+	}
+	return message
+}
+
+func TestWebSocketRoutesSimple(t *testing.T) {
+	t.Setenv("CONFIG_PATH", "/configs/test_server_config.yaml")
+
+	control.Components.Start()
+	defer control.Components.Stop()
+
+	cfg := configs.GetServerConfig()
+	endpoint := fmt.Sprintf("http://%s", cfg.Addr)
+	websocketApiEndpoint := endpoint + "/api/routes/websocket"
+	testUrl := fmt.Sprintf("ws://%s/ws", cfg.Addr)
+
+	//////////////////////////////////////////////////////
+
+	// expects []
+	code, body := DoGet(websocketApiEndpoint, t)
+	if code != 200 {
+		t.Errorf("expected 200 code response on list all request")
+	}
+
+	if !bytes.Equal(body, []byte(`{"endpoints":[]}`)) {
+		t.Errorf(`list request must be empty at the begining: %s != {"endpoints":[]}`, body)
+	}
+
+	// invalid regexp is rejected
+	code, _ = DoPost(websocketApiEndpoint, []byte(`{
+		"path": "/ws",
+		"replies": [{"match": "(", "regexp": true, "response": "never"}]
+	}`), t)
+	if code != 400 {
+		t.Errorf("expected 400 on invalid regexp: %d", code)
+	}
+
+	requestBody := []byte(`{
+		"path": "/ws",
+		"on_connect": ["welcome"],
+		"replies": [
+			{"match": "ping", "response": "pong"},
+			{"match": "^sub:.*$", "regexp": true, "response": "subscribed"}
+		]
+	}`)
+	code, _ = DoPost(websocketApiEndpoint, requestBody, t)
+	if code != 200 {
+		t.Errorf("create route failed: %d", code)
+	}
+
+	// plain http request on websocket route -> 426
+	code, _ = DoGet(endpoint+"/ws", t)
+	if code != 426 {
+		t.Errorf("expected 426 on plain http request: %d", code)
+	}
+
+	conn, _, err := websocket.DefaultDialer.Dial(testUrl, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if message := readWebSocketMessage(conn, t); !bytes.Equal(message, []byte("welcome")) {
+		t.Errorf("on connect message mismatch: %s != welcome", message)
+	}
+
+	if err := conn.WriteMessage(websocket.TextMessage, []byte("ping")); err != nil {
+		t.Error(err)
+	}
+	if message := readWebSocketMessage(conn, t); !bytes.Equal(message, []byte("pong")) {
+		t.Errorf("reply mismatch: %s != pong", message)
+	}
+
+	if err := conn.WriteMessage(websocket.TextMessage, []byte("sub:orders")); err != nil {
+		t.Error(err)
+	}
+	if message := readWebSocketMessage(conn, t); !bytes.Equal(message, []byte("subscribed")) {
+		t.Errorf("reply mismatch: %s != subscribed", message)
+	}
+
+	// push message to connected client
+	code, body = DoPost(websocketApiEndpoint+"/broadcast", []byte(`{
+		"path": "/ws",
+		"message": "news"
+	}`), t)
+	if code != 200 {
+		t.Errorf("broadcast failed: %d", code)
+	}
+
+	if !bytes.Equal(body, []byte(`{"clients":1}`)) {
+		t.Errorf(`broadcast clients mismatch: %s != {"clients":1}`, body)
+	}
+
+	if message := readWebSocketMessage(conn, t); !bytes.Equal(message, []byte("news")) {
+		t.Errorf("broadcast message mismatch: %s != news", message)
+	}
+
+	// broadcast on unknown route -> 404
+	code, _ = DoPost(websocketApiEndpoint+"/broadcast", []byte(`{
+		"path": "/unknown",
+		"message": "news"
+	}`), t)
+	if code != 404 {
+		t.Errorf("expected 404 on broadcast to unknown route: %d", code)
+	}
+}
+
+func TestWebSocketRoutesDynamic(t *testing.T) {
+	t.Setenv("CONFIG_PATH", "/configs/test_server_config.yaml")
+
+	control.Components.Start()
+	defer control.Components.Stop()
+
+	cfg := configs.GetServerConfig()
+	endpoint := fmt.Sprintf("http://%s", cfg.Addr)
+	websocketApiEndpoint := endpoint + "/api/routes/websocket"
+	testUrl := fmt.Sprintf("ws://%s/ws", cfg.Addr)
+
+	requestBody := []byte(`{
+		"path": "/ws",
+		"code": "def func(headers, body):\n    return body['A'] + body['B']"
+	}`)
+	code, _ := DoPost(websocketApiEndpoint, requestBody, t)
+	if code != 200 {
+		t.Errorf("create route failed: %d", code)
+	}
+
+	conn, _, err := websocket.DefaultDialer.Dial(testUrl, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(`{"A": 1, "B": 2}`)); err != nil {
+		t.Error(err)
+	}
+	if message := readWebSocketMessage(conn, t); !bytes.Equal(message, []byte("3")) {
+		t.Errorf("dynamic reply mismatch: %s != 3", message)
+	}
+}
+
+func TestWebSocketRoutesScripts(t *testing.T) {
+	t.Setenv("CONFIG_PATH", "/configs/test_server_config.yaml")
+
+	control.Components.Start()
+	defer control.Components.Stop()
+
+	cfg := configs.GetServerConfig()
+	endpoint := fmt.Sprintf("http://%s", cfg.Addr)
+	websocketApiEndpoint := endpoint + "/api/routes/websocket"
+
+	scripts := CountScripts(t)
+	requestBody := []byte(`{
+		"path": "/ws_scripts",
+		"code": "def func(headers, body):\n    return body"
+	}`)
+
+	if code, body := DoPost(websocketApiEndpoint, requestBody, t); code != 200 {
+		t.Fatalf("create route failed: %d %s", code, body)
+	}
+	if code, _ := DoPost(websocketApiEndpoint, requestBody, t); code != 409 {
+		t.Errorf("expected 409 on duplicate route: %d", code)
+	}
+
+	// script of replaced handler is removed
+	if code := DoPut(websocketApiEndpoint, requestBody, t); code != 204 {
+		t.Errorf("update route failed: %d", code)
+	}
+	if cnt := CountScripts(t); cnt != scripts+1 {
+		t.Errorf("expected one script of route: %d != %d", cnt, scripts+1)
+	}
+
+	if code := DoPut(websocketApiEndpoint, []byte(`{"path": "/ws_missing", "code": "def func(headers, body):\n    return body"}`), t); code != 404 {
+		t.Errorf("expected 404 on update of unexisting route: %d", code)
+	}
+
+	if code := DoDelete(websocketApiEndpoint+"?path=/ws_scripts", t); code != 204 {
+		t.Errorf("it must be possible to delete route: %d", code)
+	}
+	if code := DoDelete(websocketApiEndpoint+"?path=/ws_scripts", t); code != 404 {
+		t.Errorf("expected 404 on delete of unexisting route: %d", code)
+	}
+	if cnt := CountScripts(t); cnt != scripts {
+		t.Errorf("script of removed route is kept: %d != %d", cnt, scripts)
+	}
+}
@@ -0,0 +1,35 @@
+package util_tests
+
+import (
+	"mock-server/internal/util"
+	"reflect"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	a := "def func():\n    x = 1\n    return x"
+	b := "def func():\n    y = 2\n    return x\n# done"
+
+	expected := []string{
+		"  def func():",
+		"-     x = 1",
+		"+     y = 2",
+		"      return x",
+		"+ # done",
+	}
+	if diff := util.DiffLines(a, b); !reflect.DeepEqual(diff, expected) {
+		t.Errorf("unexpected diff: %q", diff)
+	}
+}
+
+func TestDiffLinesEmpty(t *testing.T) {
+	if diff := util.DiffLines("", ""); len(diff) != 0 {
+		t.Errorf("unexpected diff of empty code: %q", diff)
+	}
+	if diff := util.DiffLines("", "x"); !reflect.DeepEqual(diff, []string{"+ x"}) {
+		t.Errorf("unexpected diff of added code: %q", diff)
+	}
+	if diff := util.DiffLines("x", ""); !reflect.DeepEqual(diff, []string{"- x"}) {
+		t.Errorf("unexpected diff of removed code: %q", diff)
+	}
+}